@@ -0,0 +1,647 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/KEINOS/go-todotxt/todo"
+	"github.com/pkg/errors"
+)
+
+// appendNoSpace is the leading characters of the text to append without a
+// space in between. Such as "," and ".".
+const appendNoSpace = ",.:;"
+
+// ----------------------------------------------------------------------------
+//  Actions
+// ----------------------------------------------------------------------------
+
+// runAction runs the action with the given arguments. The actions and their
+// messages are the same as todo.sh.
+//
+//nolint:cyclop // complexity is 16 but it is a flat list of the actions
+func (cli *app) runAction(action string, args []string) error {
+	switch action {
+	case "add", "a":
+		return cli.add(args)
+	case "append", "app":
+		return cli.appendText(args)
+	case "archive":
+		return cli.archive()
+	case "del", "rm":
+		return cli.del(args)
+	case "depri", "dp":
+		return cli.depri(args)
+	case "do":
+		return cli.do(args)
+	case "help":
+		cli.print(usage)
+
+		return nil
+	case "list", "ls":
+		return cli.list(args)
+	case "listcon", "lsc":
+		return cli.listTerms(args, func(task *todo.Task) []string { return prefixAll("@", task.Contexts) })
+	case "listproj", "lsprj":
+		return cli.listTerms(args, func(task *todo.Task) []string { return prefixAll("+", task.Projects) })
+	case "prepend", "prep":
+		return cli.prepend(args)
+	case "pri", "p":
+		return cli.pri(args)
+	case "replace":
+		return cli.replace(args)
+	case "report":
+		return cli.report()
+	}
+
+	return newUsageError("TODO: unknown action %q", action)
+}
+
+// add adds a task at the end of the todo file.
+func (cli *app) add(args []string) error {
+	text := strings.Join(args, " ")
+	if strings.TrimSpace(text) == "" {
+		return newUsageError(`usage: todotxt add "TODO ITEM"`)
+	}
+
+	lines, err := cli.load(cli.settings.todoFile)
+	if err != nil {
+		return err
+	}
+
+	task, err := cli.parseLine(text)
+	if err != nil {
+		return err
+	}
+
+	if cli.settings.dateOnAdd && !task.HasCreatedDate() {
+		task.CreatedDate = todo.NewTask(cli.options()...).CreatedDate
+	}
+
+	lines = append(lines, *task)
+
+	if err := cli.save(cli.settings.todoFile, lines); err != nil {
+		return err
+	}
+
+	cli.info("%d %s", len(lines), cli.lineString(task))
+	cli.info("TODO: %d added.", len(lines))
+
+	return nil
+}
+
+// appendText adds the text to the end of the task. A space is put in between
+// unless the text starts with a punctuation. Such as ",".
+func (cli *app) appendText(args []string) error {
+	if len(args) < 2 || strings.Join(args[1:], "") == "" {
+		return newUsageError(`usage: todotxt append ITEM# "TEXT TO APPEND"`)
+	}
+
+	text := strings.Join(args[1:], " ")
+
+	return cli.editLine(args[0], func(line string) string {
+		if strings.ContainsAny(text[:1], appendNoSpace) {
+			return line + text
+		}
+
+		return line + " " + text
+	})
+}
+
+// archive moves the completed tasks from the todo file to the done file, and
+// removes the blank lines from the todo file.
+func (cli *app) archive() error {
+	lines, err := cli.load(cli.settings.todoFile)
+	if err != nil {
+		return err
+	}
+
+	remaining := make(todo.TaskList, 0, len(lines))
+	done := todo.NewTaskList()
+
+	for i := range lines {
+		switch task := &lines[i]; {
+		case isBlank(task):
+			continue
+		case !task.NonTask && task.Completed:
+			done = append(done, *task)
+		default:
+			remaining = append(remaining, *task)
+		}
+	}
+
+	// Append all the lines as todo.sh does, even if the same lines are already
+	// in the done file. Such as a recurring task completed on the same date.
+	if _, err := done.AppendToPath(cli.settings.doneFile, cli.options()...); err != nil {
+		return errors.Wrap(err, "TODO: failed to archive")
+	}
+
+	if err := cli.save(cli.settings.todoFile, remaining); err != nil {
+		return err
+	}
+
+	for i := range done {
+		cli.info("%s", cli.lineString(&done[i]))
+	}
+
+	cli.info("TODO: %s archived.", cli.settings.todoFile)
+
+	return nil
+}
+
+// del deletes the task, or the given term from the task. The line is blanked
+// to keep the line numbers unless preserveLineNumbers is false.
+func (cli *app) del(args []string) error {
+	if len(args) < 1 {
+		return newUsageError("usage: todotxt del ITEM# [TERM]")
+	}
+
+	if len(args) > 1 {
+		return cli.delTerm(args[0], strings.Join(args[1:], " "))
+	}
+
+	lines, err := cli.load(cli.settings.todoFile)
+	if err != nil {
+		return err
+	}
+
+	task, err := cli.taskAt(lines, args[0])
+	if err != nil {
+		return err
+	}
+
+	line := cli.lineString(task)
+
+	if !cli.settings.force && !cli.confirm(fmt.Sprintf("Delete '%s'?  (y/n)", line)) {
+		cli.info("TODO: No tasks were deleted.")
+
+		return nil
+	}
+
+	num, _ := strconv.Atoi(args[0])
+
+	if cli.settings.preserveLineNumbers {
+		//nolint:exhaustruct // blank line
+		lines[num-1] = todo.Task{NonTask: true}
+	} else {
+		lines = append(lines[:num-1], lines[num:]...)
+	}
+
+	if err := cli.save(cli.settings.todoFile, lines); err != nil {
+		return err
+	}
+
+	cli.info("%d %s", num, line)
+	cli.info("TODO: %d deleted.", num)
+
+	return nil
+}
+
+// delTerm removes the term from the task.
+func (cli *app) delTerm(item, term string) error {
+	lines, err := cli.load(cli.settings.todoFile)
+	if err != nil {
+		return err
+	}
+
+	task, err := cli.taskAt(lines, item)
+	if err != nil {
+		return err
+	}
+
+	line := cli.lineString(task)
+	if !strings.Contains(line, term) {
+		return errors.Errorf("TODO: '%s' not found; no removal done.", term)
+	}
+
+	edited, err := cli.parseLine(strings.Join(strings.Fields(strings.ReplaceAll(line, term, "")), " "))
+	if err != nil {
+		return err
+	}
+
+	edited.ID = task.ID
+	*task = *edited
+
+	if err := cli.save(cli.settings.todoFile, lines); err != nil {
+		return err
+	}
+
+	cli.info("%s %s", item, line)
+	cli.info("TODO: Removed '%s' from task.", term)
+	cli.info("%s %s", item, cli.lineString(task))
+
+	return nil
+}
+
+// depri removes the priority from the tasks.
+func (cli *app) depri(args []string) error {
+	return cli.editTasks(args, "usage: todotxt depri ITEM#[, ITEM#, ITEM#, ...]",
+		func(num string, task *todo.Task) {
+			if !task.HasPriority() {
+				cli.print("TODO: %s is not prioritized.", num)
+
+				return
+			}
+
+			task.Priority = ""
+
+			cli.info("%s %s", num, cli.lineString(task))
+			cli.info("TODO: %s deprioritized.", num)
+		})
+}
+
+// do marks the tasks as done and removes their priorities. Then the completed
+// tasks are archived if autoArchive is true.
+func (cli *app) do(args []string) error {
+	err := cli.editTasks(args, "usage: todotxt do ITEM#[, ITEM#, ITEM#, ...]",
+		func(num string, task *todo.Task) {
+			if task.Completed {
+				cli.print("TODO: %s is already marked done.", num)
+
+				return
+			}
+
+			task.Priority = ""
+			task.Complete(cli.options()...)
+
+			cli.info("%s %s", num, cli.lineString(task))
+			cli.info("TODO: %s marked as done.", num)
+		})
+	if err != nil || !cli.settings.autoArchive {
+		return err
+	}
+
+	return cli.archive()
+}
+
+// list prints the lines which contain all the terms, sorted by the text with
+// the line numbers. The terms with a leading "-" exclude the lines instead.
+func (cli *app) list(terms []string) error {
+	lines, err := cli.load(cli.settings.todoFile)
+	if err != nil {
+		return err
+	}
+
+	type listItem struct {
+		text string
+		num  int
+	}
+
+	items := make([]listItem, 0, len(lines))
+
+	for i := range lines {
+		if isBlank(&lines[i]) {
+			continue
+		}
+
+		if text := cli.listString(&lines[i]); matchTerms(text, terms) {
+			items = append(items, listItem{text: text, num: i + 1})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return strings.ToLower(items[i].text) < strings.ToLower(items[j].text)
+	})
+
+	padding := len(strconv.Itoa(len(lines)))
+
+	for _, item := range items {
+		cli.print("%0*d %s", padding, item.num, item.text)
+	}
+
+	cli.info("--")
+	cli.info("TODO: %d of %d tasks shown", len(items), countLines(lines))
+
+	return nil
+}
+
+// listTerms prints the unique terms of the tasks which contain all the given
+// terms, such as the contexts or the projects, in alphabetical order.
+func (cli *app) listTerms(terms []string, getTerms func(task *todo.Task) []string) error {
+	lines, err := cli.load(cli.settings.todoFile)
+	if err != nil {
+		return err
+	}
+
+	found := map[string]bool{}
+
+	for i := range lines {
+		if !lines[i].NonTask && matchTerms(cli.lineString(&lines[i]), terms) {
+			for _, term := range getTerms(&lines[i]) {
+				found[term] = true
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(found))
+	for term := range found {
+		sorted = append(sorted, term)
+	}
+
+	sort.Strings(sorted)
+
+	for _, term := range sorted {
+		cli.print("%s", term)
+	}
+
+	return nil
+}
+
+// prepend adds the text to the beginning of the task, after the completion
+// mark, the priority and the dates.
+func (cli *app) prepend(args []string) error {
+	if len(args) < 2 {
+		return newUsageError(`usage: todotxt prepend ITEM# "TEXT TO PREPEND"`)
+	}
+
+	text := strings.Join(args[1:], " ")
+
+	return cli.editLine(args[0], func(line string) string {
+		task, err := cli.parseLine(line)
+		if err != nil {
+			return text + " " + line
+		}
+
+		for _, seg := range task.InlineSegments(cli.options()...) {
+			switch seg.Type {
+			case todo.SegmentIsCompleted, todo.SegmentCompletedDate, todo.SegmentPriority, todo.SegmentCreatedDate:
+				continue
+			default:
+				return line[:seg.Start] + text + " " + line[seg.Start:]
+			}
+		}
+
+		return line + " " + text
+	})
+}
+
+// pri sets the priority of the task.
+func (cli *app) pri(args []string) error {
+	const errMsg = "usage: todotxt pri ITEM# PRIORITY\nnote: PRIORITY must be anywhere from A to Z."
+
+	if len(args) != 2 {
+		return newUsageError("%s", errMsg)
+	}
+
+	priority := strings.ToUpper(args[1])
+	if len(priority) != 1 || priority[0] < 'A' || priority[0] > 'Z' {
+		return newUsageError("%s", errMsg)
+	}
+
+	return cli.editTasks(args[:1], errMsg, func(num string, task *todo.Task) {
+		oldPriority := task.Priority
+		if oldPriority == priority {
+			cli.print("TODO: %s already prioritized (%s).", num, priority)
+
+			return
+		}
+
+		task.Priority = priority
+
+		cli.info("%s %s", num, cli.lineString(task))
+
+		if oldPriority != "" {
+			cli.info("TODO: %s re-prioritized from (%s) to (%s).", num, oldPriority, priority)
+		} else {
+			cli.info("TODO: %s prioritized (%s).", num, priority)
+		}
+	})
+}
+
+// replace replaces the task with the given text. The priority and the created
+// date of the task are kept if the text does not have them.
+func (cli *app) replace(args []string) error {
+	if len(args) < 2 {
+		return newUsageError(`usage: todotxt replace ITEM# "UPDATED ITEM"`)
+	}
+
+	lines, err := cli.load(cli.settings.todoFile)
+	if err != nil {
+		return err
+	}
+
+	task, err := cli.taskAt(lines, args[0])
+	if err != nil {
+		return err
+	}
+
+	replaced, err := cli.parseLine(strings.Join(args[1:], " "))
+	if err != nil {
+		return err
+	}
+
+	if !replaced.HasPriority() {
+		replaced.Priority = task.Priority
+	}
+
+	if !replaced.HasCreatedDate() {
+		replaced.CreatedDate = task.CreatedDate
+	}
+
+	oldLine := cli.lineString(task)
+	replaced.ID = task.ID
+	*task = *replaced
+
+	if err := cli.save(cli.settings.todoFile, lines); err != nil {
+		return err
+	}
+
+	cli.info("%s %s", args[0], oldLine)
+	cli.info("TODO: Replaced task with:")
+	cli.info("%s %s", args[0], cli.lineString(task))
+
+	return nil
+}
+
+// report archives the completed tasks, then appends the number of the open and
+// done tasks to the report file, and prints the report file.
+func (cli *app) report() error {
+	if err := cli.archive(); err != nil {
+		return err
+	}
+
+	open, err := cli.load(cli.settings.todoFile)
+	if err != nil {
+		return err
+	}
+
+	done, err := cli.load(cli.settings.doneFile)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(cli.settings.reportFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, todo.PermReadWrite)
+	if err != nil {
+		return errors.Wrap(err, "TODO: failed to open report file")
+	}
+
+	_, err = fmt.Fprintf(file, "%s %d %d\n",
+		cli.clock.Now().Format("2006-01-02T15:04:05"), countLines(open), countLines(done))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "TODO: failed to write report file")
+	}
+
+	content, err := os.ReadFile(cli.settings.reportFile)
+	if err != nil {
+		return errors.Wrap(err, "TODO: failed to read report file")
+	}
+
+	fmt.Fprint(cli.stdout, string(content))
+	cli.info("TODO: Report file updated.")
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Helper methods
+// ----------------------------------------------------------------------------
+
+// confirm asks the question and returns true if the answer is yes.
+func (cli *app) confirm(question string) bool {
+	cli.print("%s", question)
+
+	answer, _ := cli.stdin.ReadString('\n')
+
+	return strings.HasPrefix(strings.TrimSpace(answer), "y")
+}
+
+// editLine edits the line of the task with the given function and saves it.
+// The edited line is printed with the line number.
+func (cli *app) editLine(item string, edit func(line string) string) error {
+	lines, err := cli.load(cli.settings.todoFile)
+	if err != nil {
+		return err
+	}
+
+	task, err := cli.taskAt(lines, item)
+	if err != nil {
+		return err
+	}
+
+	edited, err := cli.parseLine(edit(cli.lineString(task)))
+	if err != nil {
+		return err
+	}
+
+	edited.ID = task.ID
+	*task = *edited
+
+	if err := cli.save(cli.settings.todoFile, lines); err != nil {
+		return err
+	}
+
+	cli.info("%s %s", item, cli.lineString(task))
+
+	return nil
+}
+
+// editTasks edits the tasks of the given task numbers with the given function
+// and saves them. All the task numbers are checked before editing.
+func (cli *app) editTasks(args []string, usageMsg string, edit func(num string, task *todo.Task)) error {
+	items := splitItems(args)
+	if len(items) == 0 {
+		return newUsageError("%s", usageMsg)
+	}
+
+	lines, err := cli.load(cli.settings.todoFile)
+	if err != nil {
+		return err
+	}
+
+	tasks := make([]*todo.Task, 0, len(items))
+
+	for _, item := range items {
+		task, err := cli.taskAt(lines, item)
+		if err != nil {
+			return err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	for i, task := range tasks {
+		edit(items[i], task)
+	}
+
+	return cli.save(cli.settings.todoFile, lines)
+}
+
+// listString returns the line of the task to list, without the contexts,
+// projects or priority if they are hidden. See the -@, -+ and -P options.
+func (cli *app) listString(task *todo.Task) string {
+	line := cli.lineString(task)
+
+	hidden := map[todo.TaskSegmentType]bool{
+		todo.SegmentContext:  cli.settings.hideContexts,
+		todo.SegmentProject:  cli.settings.hideProjects,
+		todo.SegmentPriority: cli.settings.hidePriority,
+	}
+
+	if task.NonTask || !(hidden[todo.SegmentContext] || hidden[todo.SegmentProject] || hidden[todo.SegmentPriority]) {
+		return line
+	}
+
+	segs := task.InlineSegments(cli.options()...)
+
+	// Remove from the end not to shift the offsets of the preceding segments
+	for i := len(segs) - 1; i >= 0; i-- {
+		if !hidden[segs[i].Type] {
+			continue
+		}
+
+		prefix, suffix := strings.TrimRight(line[:segs[i].Start], " \t"), line[segs[i].End:]
+		if prefix == "" {
+			suffix = strings.TrimLeft(suffix, " \t")
+		}
+
+		line = prefix + suffix
+	}
+
+	return line
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// matchTerms returns true if the text contains all the terms, and none of the
+// terms with a leading "-". The terms are case-insensitive.
+func matchTerms(text string, terms []string) bool {
+	text = strings.ToLower(text)
+
+	for _, term := range terms {
+		term = strings.ToLower(term)
+
+		if len(term) > 1 && term[0] == '-' {
+			if strings.Contains(text, term[1:]) {
+				return false
+			}
+
+			continue
+		}
+
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// prefixAll returns the strings with the given prefix.
+func prefixAll(prefix string, strs []string) []string {
+	result := make([]string, len(strs))
+
+	for i, s := range strs {
+		result[i] = prefix + s
+	}
+
+	return result
+}
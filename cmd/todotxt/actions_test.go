@@ -0,0 +1,287 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testTodoTxt = "(B) Call Mom @phone +family\n" +
+	"Pick up milk @store\n" +
+	"\n" +
+	"x 2024-01-01 2023-12-31 Pay rent +home\n" +
+	"(A) 2024-01-01 Plan trip +family due:2024-02-01\n"
+
+func TestAction_add(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "todo.txt", testTodoTxt)
+
+	result := runTest(t, dir, "", "add", "(C)", "Water plants @home")
+	require.Equal(t, 0, result.status, result.stderr)
+	require.Equal(t, "6 (C) Water plants @home\nTODO: 6 added.\n", result.stdout)
+
+	result = runTest(t, dir, "", "-t", "a", "(D) Buy bread")
+	require.Equal(t, "7 (D) 2024-01-02 Buy bread\nTODO: 7 added.\n", result.stdout,
+		"created date should be after the priority")
+
+	require.Equal(t, testTodoTxt+"(C) Water plants @home\n(D) 2024-01-02 Buy bread\n", readTestFile(t, dir, "todo.txt"))
+
+	// New file and usage
+	empty := t.TempDir()
+
+	result = runTest(t, empty, "", "add", "First task")
+	require.Equal(t, "1 First task\nTODO: 1 added.\n", result.stdout)
+
+	result = runTest(t, empty, "", "add")
+	require.Equal(t, 1, result.status)
+	require.Contains(t, result.stderr, `usage: todotxt add "TODO ITEM"`)
+
+	result = runTest(t, empty, "", "add", "2024-13-45 Invalid date")
+	require.Equal(t, 1, result.status)
+	require.Contains(t, result.stderr, "invalid CreatedDate")
+}
+
+func TestAction_list(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "todo.txt", testTodoTxt+"2024-13-45 Unparsable line\n")
+
+	result := runTest(t, dir, "", "ls")
+	require.Equal(t, 0, result.status, result.stderr)
+	require.Equal(t, "5 (A) 2024-01-01 Plan trip +family due:2024-02-01\n"+
+		"1 (B) Call Mom @phone +family\n"+
+		"6 2024-13-45 Unparsable line\n"+
+		"2 Pick up milk @store\n"+
+		"4 x 2024-01-01 2023-12-31 Pay rent +home\n"+
+		"--\n"+
+		"TODO: 5 of 5 tasks shown\n", result.stdout)
+
+	result = runTest(t, dir, "", "list", "+FAMILY", "-mom")
+	require.Equal(t, "5 (A) 2024-01-01 Plan trip +family due:2024-02-01\n--\nTODO: 1 of 5 tasks shown\n",
+		result.stdout)
+
+	result = runTest(t, dir, "", "-@+P", "ls", "call")
+	require.Equal(t, "1 Call Mom\n--\nTODO: 1 of 5 tasks shown\n", result.stdout)
+
+	// Padded line numbers
+	writeTestFile(t, dir, "todo.txt", "Task\n\n\n\n\n\n\n\n\nLast task\n")
+
+	result = runTest(t, dir, "", "ls")
+	require.Equal(t, "10 Last task\n01 Task\n--\nTODO: 2 of 2 tasks shown\n", result.stdout)
+}
+
+func TestAction_listcon_listproj(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "todo.txt", testTodoTxt)
+
+	require.Equal(t, "@phone\n@store\n", runTest(t, dir, "", "listcon").stdout)
+	require.Equal(t, "+family\n+home\n", runTest(t, dir, "", "listproj").stdout)
+	require.Equal(t, "+family\n", runTest(t, dir, "", "lsprj", "trip").stdout)
+}
+
+func TestAction_do(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "todo.txt", testTodoTxt)
+
+	result := runTest(t, dir, "", "do", "1,5")
+	require.Equal(t, 0, result.status, result.stderr)
+	require.Equal(t, "1 x 2024-01-02 Call Mom @phone +family\n"+
+		"TODO: 1 marked as done.\n"+
+		"5 x 2024-01-02 2024-01-01 Plan trip +family due:2024-02-01\n"+
+		"TODO: 5 marked as done.\n"+
+		"x 2024-01-02 Call Mom @phone +family\n"+
+		"x 2024-01-01 2023-12-31 Pay rent +home\n"+
+		"x 2024-01-02 2024-01-01 Plan trip +family due:2024-02-01\n"+
+		"TODO: "+filepath.Join(dir, "todo.txt")+" archived.\n", result.stdout)
+
+	require.Equal(t, "Pick up milk @store\n", readTestFile(t, dir, "todo.txt"))
+	require.Equal(t, "x 2024-01-02 Call Mom @phone +family\n"+
+		"x 2024-01-01 2023-12-31 Pay rent +home\n"+
+		"x 2024-01-02 2024-01-01 Plan trip +family due:2024-02-01\n", readTestFile(t, dir, "done.txt"))
+
+	// Without auto-archive
+	writeTestFile(t, dir, "todo.txt", testTodoTxt)
+
+	result = runTest(t, dir, "", "-a", "do", "2", "4")
+	require.Equal(t, "2 x 2024-01-02 Pick up milk @store\n"+
+		"TODO: 2 marked as done.\n"+
+		"TODO: 4 is already marked done.\n", result.stdout)
+	require.Contains(t, readTestFile(t, dir, "todo.txt"), "\nx 2024-01-02 Pick up milk @store\n")
+
+	// Invalid task numbers do not change the file
+	result = runTest(t, dir, "", "do", "1", "3")
+	require.Equal(t, 1, result.status)
+	require.Equal(t, "TODO: No task 3.\n", result.stderr)
+	require.Contains(t, readTestFile(t, dir, "todo.txt"), "(B) Call Mom @phone +family\n")
+
+	result = runTest(t, dir, "", "do", "one")
+	require.Equal(t, 1, result.status)
+	require.Contains(t, result.stderr, `TODO: invalid task number "one"`)
+}
+
+func TestAction_pri_depri(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "todo.txt", testTodoTxt)
+
+	for _, test := range []struct {
+		expect string
+		args   []string
+	}{
+		{args: []string{"pri", "2", "c"}, expect: "2 (C) Pick up milk @store\nTODO: 2 prioritized (C).\n"},
+		{args: []string{"p", "1", "A"}, expect: "1 (A) Call Mom @phone +family\nTODO: 1 re-prioritized from (B) to (A).\n"},
+		{args: []string{"pri", "1", "A"}, expect: "TODO: 1 already prioritized (A).\n"},
+		{args: []string{"depri", "1", "2"}, expect: "1 Call Mom @phone +family\nTODO: 1 deprioritized.\n" +
+			"2 Pick up milk @store\nTODO: 2 deprioritized.\n"},
+		{args: []string{"dp", "2"}, expect: "TODO: 2 is not prioritized.\n"},
+	} {
+		result := runTest(t, dir, "", test.args...)
+		require.Equal(t, 0, result.status, result.stderr)
+		require.Equal(t, test.expect, result.stdout, "args: %v", test.args)
+	}
+
+	require.Equal(t, "Call Mom @phone +family\nPick up milk @store\n", readTestFile(t, dir, "todo.txt")[:44])
+
+	for _, priority := range []string{"AB", "1", ""} {
+		result := runTest(t, dir, "", "pri", "1", priority)
+		require.Equal(t, 1, result.status)
+		require.Contains(t, result.stderr, "usage: todotxt pri ITEM# PRIORITY")
+	}
+}
+
+func TestAction_append_prepend_replace(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "todo.txt", testTodoTxt)
+
+	for _, test := range []struct {
+		expect string
+		args   []string
+	}{
+		{args: []string{"append", "2", "and eggs"}, expect: "2 Pick up milk @store and eggs\n"},
+		{args: []string{"app", "2", ", quickly"}, expect: "2 Pick up milk @store and eggs, quickly\n"},
+		{args: []string{"prepend", "5", "Really"}, expect: "5 (A) 2024-01-01 Really Plan trip +family due:2024-02-01\n"},
+		{args: []string{"prep", "2", "Go"}, expect: "2 Go Pick up milk @store and eggs, quickly\n"},
+		{
+			args: []string{"replace", "5", "Book flights due:2024-01-20"},
+			expect: "5 (A) 2024-01-01 Really Plan trip +family due:2024-02-01\n" +
+				"TODO: Replaced task with:\n" +
+				"5 (A) 2024-01-01 Book flights due:2024-01-20\n",
+		},
+		{
+			args: []string{"replace", "1", "(C) Call Dad"},
+			expect: "1 (B) Call Mom @phone +family\n" +
+				"TODO: Replaced task with:\n" +
+				"1 (C) Call Dad\n",
+		},
+	} {
+		result := runTest(t, dir, "", test.args...)
+		require.Equal(t, 0, result.status, result.stderr)
+		require.Equal(t, test.expect, result.stdout, "args: %v", test.args)
+	}
+
+	require.Equal(t, "(C) Call Dad\n"+
+		"Go Pick up milk @store and eggs, quickly\n"+
+		"\n"+
+		"x 2024-01-01 2023-12-31 Pay rent +home\n"+
+		"(A) 2024-01-01 Book flights due:2024-01-20\n", readTestFile(t, dir, "todo.txt"))
+
+	result := runTest(t, dir, "", "append", "3", "text")
+	require.Equal(t, 1, result.status)
+	require.Equal(t, "TODO: No task 3.\n", result.stderr)
+
+	result = runTest(t, dir, "", "append", "1", "")
+	require.Equal(t, 1, result.status)
+	require.Contains(t, result.stderr, `usage: todotxt append ITEM# "TEXT TO APPEND"`)
+	require.Contains(t, readTestFile(t, dir, "todo.txt"), "(C) Call Dad\n", "empty text should not change the file")
+}
+
+func TestAction_del(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "todo.txt", testTodoTxt)
+
+	result := runTest(t, dir, "n\n", "del", "1")
+	require.Equal(t, "Delete '(B) Call Mom @phone +family'?  (y/n)\nTODO: No tasks were deleted.\n", result.stdout)
+	require.Equal(t, testTodoTxt, readTestFile(t, dir, "todo.txt"))
+
+	result = runTest(t, dir, "y\n", "rm", "1")
+	require.Equal(t, "Delete '(B) Call Mom @phone +family'?  (y/n)\n"+
+		"1 (B) Call Mom @phone +family\n"+
+		"TODO: 1 deleted.\n", result.stdout)
+	require.Equal(t, "\nPick up milk @store\n", readTestFile(t, dir, "todo.txt")[:21], "line numbers should be kept")
+
+	result = runTest(t, dir, "", "-f", "del", "2", "@store")
+	require.Equal(t, "2 Pick up milk @store\n"+
+		"TODO: Removed '@store' from task.\n"+
+		"2 Pick up milk\n", result.stdout)
+
+	result = runTest(t, dir, "", "-f", "del", "2", "@home")
+	require.Equal(t, 1, result.status)
+	require.Equal(t, "TODO: '@home' not found; no removal done.\n", result.stderr)
+
+	result = runTest(t, dir, "", "-fn", "del", "2")
+	require.Equal(t, "2 Pick up milk\nTODO: 2 deleted.\n", result.stdout)
+	require.Equal(t, "\n\nx 2024-01-01 2023-12-31 Pay rent +home\n"+
+		"(A) 2024-01-01 Plan trip +family due:2024-02-01\n", readTestFile(t, dir, "todo.txt"))
+}
+
+func TestAction_archive_report(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "todo.txt", testTodoTxt)
+	writeTestFile(t, dir, "done.txt", "x 2023-12-01 Old task\n")
+	writeTestFile(t, dir, "report.txt", "2023-12-31T10:00:00 4 1\n")
+
+	result := runTest(t, dir, "", "report")
+	require.Equal(t, 0, result.status, result.stderr)
+	require.Equal(t, "x 2024-01-01 2023-12-31 Pay rent +home\n"+
+		"TODO: "+filepath.Join(dir, "todo.txt")+" archived.\n"+
+		"2023-12-31T10:00:00 4 1\n"+
+		"2024-01-02T12:34:56 3 2\n"+
+		"TODO: Report file updated.\n", result.stdout)
+
+	require.Equal(t, "(B) Call Mom @phone +family\n"+
+		"Pick up milk @store\n"+
+		"(A) 2024-01-01 Plan trip +family due:2024-02-01\n", readTestFile(t, dir, "todo.txt"),
+		"blank lines should be removed")
+	require.Equal(t, "x 2023-12-01 Old task\nx 2024-01-01 2023-12-31 Pay rent +home\n", readTestFile(t, dir, "done.txt"))
+
+	// Quiet mode
+	dir = t.TempDir()
+	writeTestFile(t, dir, "todo.txt", testTodoTxt)
+	writeTestFile(t, dir, "todo.cfg", "export TODOTXT_VERBOSE=0\n")
+
+	result = runTest(t, dir, "", "archive")
+	require.Empty(t, result.stdout)
+	require.Equal(t, "x 2024-01-01 2023-12-31 Pay rent +home\n", readTestFile(t, dir, "done.txt"))
+}
+
+func TestAction_archive_duplicate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "todo.txt", "x 2020-01-01 water plants\nCall Mom\n")
+	writeTestFile(t, dir, "done.txt", "x 2020-01-01 water plants\n")
+
+	result := runTest(t, dir, "", "archive")
+	require.Equal(t, 0, result.status, result.stderr)
+	require.Equal(t, "x 2020-01-01 water plants\n"+
+		"TODO: "+filepath.Join(dir, "todo.txt")+" archived.\n", result.stdout)
+
+	require.Equal(t, "Call Mom\n", readTestFile(t, dir, "todo.txt"))
+	require.Equal(t, "x 2020-01-01 water plants\nx 2020-01-01 water plants\n", readTestFile(t, dir, "done.txt"),
+		"duplicate lines should be archived as they are")
+}
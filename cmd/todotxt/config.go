@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: settings
+// ----------------------------------------------------------------------------
+
+// settings holds the configuration of the command. It is read from the todo.cfg
+// file and the environment variables in the same way as todo.sh.
+type settings struct {
+	todoFile            string // todoFile is TODO_FILE. Default is "$TODO_DIR/todo.txt".
+	doneFile            string // doneFile is DONE_FILE. Default is "$TODO_DIR/done.txt".
+	reportFile          string // reportFile is REPORT_FILE. Default is "$TODO_DIR/report.txt".
+	defaultAction       string // defaultAction is TODOTXT_DEFAULT_ACTION, used if no action is given.
+	verbose             int    // verbose is TODOTXT_VERBOSE. Zero to suppress the messages.
+	autoArchive         bool   // autoArchive is TODOTXT_AUTO_ARCHIVE. Archives on "do" if true.
+	dateOnAdd           bool   // dateOnAdd is TODOTXT_DATE_ON_ADD. Prepends the created date on "add" if true.
+	force               bool   // force is TODOTXT_FORCE. Deletes without confirmation if true.
+	hideContexts        bool   // hideContexts hides the contexts in the list output. See the -@ option.
+	hidePriority        bool   // hidePriority hides the priorities in the list output. See the -P option.
+	hideProjects        bool   // hideProjects hides the projects in the list output. See the -+ option.
+	preserveLineNumbers bool   // preserveLineNumbers is TODOTXT_PRESERVE_LINE_NUMBERS. Blanks deleted lines if true.
+}
+
+// configKeyRx matches the "export KEY=" part of the variable assignments.
+var configKeyRx = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)=`)
+
+// ----------------------------------------------------------------------------
+//  Constructor
+// ----------------------------------------------------------------------------
+
+// loadSettings returns the settings from the given config file and the
+// environment variables. The environment variables take precedence over the
+// config file. If configFile is empty, TODOTXT_CFG_FILE or the first existing
+// default config file is used, if any.
+func loadSettings(configFile string, getenv func(string) string) (*settings, error) {
+	vars := map[string]string{}
+
+	if configFile == "" {
+		configFile = getenv("TODOTXT_CFG_FILE")
+	}
+
+	if configFile == "" {
+		configFile = findConfigFile(getenv)
+	}
+
+	if configFile != "" {
+		var err error
+
+		vars, err = readConfigFile(configFile, getenv)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lookup := func(key, defaultValue string) string {
+		if value := getenv(key); value != "" {
+			return value
+		}
+
+		if value, ok := vars[key]; ok && value != "" {
+			return value
+		}
+
+		return defaultValue
+	}
+
+	todoDir := lookup("TODO_DIR", ".")
+
+	verbose, err := strconv.Atoi(lookup("TODOTXT_VERBOSE", "1"))
+	if err != nil {
+		return nil, errors.Wrap(err, "Fatal Error: invalid TODOTXT_VERBOSE")
+	}
+
+	return &settings{
+		todoFile:            lookup("TODO_FILE", filepath.Join(todoDir, "todo.txt")),
+		doneFile:            lookup("DONE_FILE", filepath.Join(todoDir, "done.txt")),
+		reportFile:          lookup("REPORT_FILE", filepath.Join(todoDir, "report.txt")),
+		defaultAction:       lookup("TODOTXT_DEFAULT_ACTION", ""),
+		verbose:             verbose,
+		autoArchive:         lookup("TODOTXT_AUTO_ARCHIVE", "1") == "1",
+		dateOnAdd:           lookup("TODOTXT_DATE_ON_ADD", "0") == "1",
+		force:               lookup("TODOTXT_FORCE", "0") == "1",
+		hideContexts:        false,
+		hidePriority:        false,
+		hideProjects:        false,
+		preserveLineNumbers: lookup("TODOTXT_PRESERVE_LINE_NUMBERS", "1") == "1",
+	}, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// findConfigFile returns the first existing default config file of todo.sh. It
+// returns an empty string if none of them exists.
+func findConfigFile(getenv func(string) string) string {
+	home := getenv("HOME")
+	xdgConfig := getenv("XDG_CONFIG_HOME")
+
+	if xdgConfig == "" && home != "" {
+		xdgConfig = filepath.Join(home, ".config")
+	}
+
+	var candidates []string
+
+	if home != "" {
+		candidates = append(candidates,
+			filepath.Join(home, ".todo", "config"),
+			filepath.Join(home, "todo.cfg"),
+			filepath.Join(home, ".todo.cfg"),
+		)
+	}
+
+	if xdgConfig != "" {
+		candidates = append(candidates, filepath.Join(xdgConfig, "todo", "config"))
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// readConfigFile reads the variable assignments of the todo.cfg file. Such as:
+//
+//	export TODO_DIR="$HOME/todo"
+//	export TODO_FILE="$TODO_DIR/todo.txt"
+//
+// The "$VAR" and "${VAR}" in the values are expanded with the environment
+// variables and the variables assigned before. `$(dirname "$0")` is expanded to
+// the directory of the config file. The other lines, such as comments and shell
+// commands, are ignored.
+func readConfigFile(configFile string, getenv func(string) string) (map[string]string, error) {
+	file, err := os.Open(configFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "Fatal Error: Cannot read configuration file "+configFile)
+	}
+	defer file.Close()
+
+	vars := map[string]string{}
+	dir := filepath.Dir(configFile)
+
+	lookup := func(key string) string {
+		if value := getenv(key); value != "" {
+			return value
+		}
+
+		return vars[key]
+	}
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		loc := configKeyRx.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+
+		value := strings.ReplaceAll(line[loc[1]:], `$(dirname "$0")`, dir)
+
+		if expanded, ok := expandShellValue(value, lookup); ok {
+			vars[line[loc[2]:loc[3]]] = expanded
+		}
+	}
+
+	return vars, errors.Wrap(scanner.Err(), "failed to read configuration file "+configFile)
+}
+
+// expandShellValue returns the value of a shell variable assignment without the
+// quotes, and with the variables expanded except in single quotes. The value
+// ends at the first whitespace outside of the quotes. It returns false if the
+// value contains a command substitution which can not be expanded.
+func expandShellValue(value string, lookup func(string) string) (string, bool) {
+	if strings.Contains(value, "$(") || strings.Contains(value, "`") {
+		return "", false
+	}
+
+	var (
+		result  strings.Builder
+		pending strings.Builder
+	)
+
+	flush := func() {
+		result.WriteString(os.Expand(pending.String(), lookup))
+		pending.Reset()
+	}
+
+	for i := 0; i < len(value); i++ {
+		switch char := value[i]; {
+		case char == '\'':
+			end := strings.IndexByte(value[i+1:], '\'')
+			if end < 0 {
+				return "", false
+			}
+
+			flush()
+			result.WriteString(value[i+1 : i+1+end])
+			i += end + 1
+		case char == '"':
+			end := strings.IndexByte(value[i+1:], '"')
+			if end < 0 {
+				return "", false
+			}
+
+			pending.WriteString(value[i+1 : i+1+end])
+			i += end + 1
+		case char == '\\' && i+1 < len(value):
+			flush()
+			result.WriteByte(value[i+1])
+			i++
+		case char == ' ' || char == '\t' || char == ';':
+			flush()
+
+			return result.String(), true
+		default:
+			pending.WriteByte(char)
+		}
+	}
+
+	flush()
+
+	return result.String(), true
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSettings(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, ".todo/config", `# todo.sh config
+export TODO_DIR=$(dirname "$0")
+export TODO_FILE="$TODO_DIR/my todo.txt"
+export DONE_FILE=${TODO_DIR}/archive/'done.txt' # comment
+export TODOTXT_VERBOSE=0
+export TODOTXT_AUTO_ARCHIVE=0
+TODOTXT_DATE_ON_ADD=1
+export TODOTXT_SORT_COMMAND='env LC_COLLATE=C sort -f -k2'
+export REPORT_FILE=$(pwd)/report.txt
+`)
+
+	env := map[string]string{"HOME": dir}
+	getenv := func(key string) string { return env[key] }
+
+	settings, err := loadSettings("", getenv)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, ".todo", "my todo.txt"), settings.todoFile)
+	require.Equal(t, filepath.Join(dir, ".todo", "archive", "done.txt"), settings.doneFile)
+	require.Equal(t, filepath.Join(dir, ".todo", "report.txt"), settings.reportFile,
+		"command substitutions should be ignored")
+	require.Equal(t, 0, settings.verbose)
+	require.False(t, settings.autoArchive)
+	require.True(t, settings.dateOnAdd)
+	require.True(t, settings.preserveLineNumbers)
+
+	// Environment variables take precedence over the config file
+	env["TODO_DIR"] = filepath.Join(dir, "env")
+	env["DONE_FILE"] = filepath.Join(dir, "done.txt")
+	env["TODOTXT_VERBOSE"] = "2"
+
+	settings, err = loadSettings("", getenv)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "env", "my todo.txt"), settings.todoFile)
+	require.Equal(t, filepath.Join(dir, "done.txt"), settings.doneFile)
+	require.Equal(t, 2, settings.verbose)
+
+	// Explicit config file must exist
+	_, err = loadSettings(filepath.Join(dir, "missing.cfg"), getenv)
+	require.ErrorContains(t, err, "Cannot read configuration file")
+
+	env["TODOTXT_CFG_FILE"] = filepath.Join(dir, "missing.cfg")
+	_, err = loadSettings("", getenv)
+	require.Error(t, err)
+}
+
+func TestLoadSettings_defaults(t *testing.T) {
+	t.Parallel()
+
+	settings, err := loadSettings("", func(string) string { return "" })
+	require.NoError(t, err)
+	require.Equal(t, "todo.txt", settings.todoFile)
+	require.Equal(t, "done.txt", settings.doneFile)
+	require.Equal(t, "report.txt", settings.reportFile)
+	require.Equal(t, 1, settings.verbose)
+	require.True(t, settings.autoArchive)
+	require.False(t, settings.dateOnAdd)
+	require.False(t, settings.force)
+
+	_, err = loadSettings("", func(key string) string {
+		return map[string]string{"TODOTXT_VERBOSE": "yes"}[key]
+	})
+	require.ErrorContains(t, err, "invalid TODOTXT_VERBOSE")
+}
+
+func TestExpandShellValue(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(key string) string { return map[string]string{"DIR": "/tmp/todo"}[key] }
+
+	for _, test := range []struct {
+		input  string
+		expect string
+		ok     bool
+	}{
+		{input: `"$DIR/todo.txt"`, expect: "/tmp/todo/todo.txt", ok: true},
+		{input: `'$DIR'/todo.txt`, expect: "$DIR/todo.txt", ok: true},
+		{input: `$DIR\ files/todo.txt; echo`, expect: "/tmp/todo files/todo.txt", ok: true},
+		{input: `"unterminated`, expect: "", ok: false},
+		{input: "`pwd`/todo.txt", expect: "", ok: false},
+	} {
+		actual, ok := expandShellValue(test.input, lookup)
+		require.Equal(t, test.ok, ok, "input: %s", test.input)
+		require.Equal(t, test.expect, actual, "input: %s", test.input)
+	}
+}
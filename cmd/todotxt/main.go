@@ -0,0 +1,340 @@
+/*
+Command todotxt is a command line tool to manage todo.txt files, built on top of
+the todo package.
+
+It supports the classic actions of todo.sh, such as add, list, do, pri, depri,
+append, prepend, replace, del, archive, listcon, listproj and report. The output
+is compatible with todo.sh, so that the existing scripts keep working.
+
+	todotxt [-fhpantvAINTV@+P] [-d todo_config] action [task_number] [task_description]
+
+The files are taken from the TODO_FILE, DONE_FILE and REPORT_FILE variables of
+the environment or of the todo.cfg file. See "todotxt help" for details.
+*/
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/KEINOS/go-todotxt/todo"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: app
+// ----------------------------------------------------------------------------
+
+// app holds the environment and the settings of the command. The environment
+// is replaced in the tests.
+type app struct {
+	clock    todo.Clock
+	stdin    *bufio.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+	getenv   func(key string) string
+	settings *settings
+}
+
+// ----------------------------------------------------------------------------
+//  Type: usageError
+// ----------------------------------------------------------------------------
+
+// usageError is returned if the arguments are invalid. The usage is printed
+// along with the error.
+type usageError string
+
+// newUsageError returns a usageError with the formatted message.
+func newUsageError(format string, args ...any) error {
+	return usageError(fmt.Sprintf(format, args...))
+}
+
+// Error returns the message of the error.
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// ----------------------------------------------------------------------------
+//  Main
+// ----------------------------------------------------------------------------
+
+func main() {
+	cli := &app{
+		clock:    todo.SystemClock,
+		stdin:    bufio.NewReader(os.Stdin),
+		stdout:   os.Stdout,
+		stderr:   os.Stderr,
+		getenv:   os.Getenv,
+		settings: nil,
+	}
+
+	os.Exit(cli.run(os.Args[1:]))
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// info prints the message of the action only in the verbose mode. Such as
+// "TODO: 3 added.".
+func (cli *app) info(format string, args ...any) {
+	if cli.settings.verbose > 0 {
+		fmt.Fprintf(cli.stdout, format+"\n", args...)
+	}
+}
+
+// print prints the output of the action regardless of the verbose mode.
+func (cli *app) print(format string, args ...any) {
+	fmt.Fprintf(cli.stdout, format+"\n", args...)
+}
+
+// run runs the command with the given arguments and returns the exit status.
+func (cli *app) run(args []string) int {
+	overrides, args, err := parseFlags(args)
+	if err == nil {
+		cli.settings, err = loadSettings(overrides.configFile, cli.getenv)
+	}
+
+	if err != nil {
+		fmt.Fprintln(cli.stderr, err)
+		fmt.Fprintln(cli.stderr, shortUsage)
+
+		return 1
+	}
+
+	for _, override := range overrides.apply {
+		override(cli.settings)
+	}
+
+	if overrides.help {
+		cli.print(usage)
+
+		return 0
+	}
+
+	if overrides.version {
+		cli.print("todotxt version %s", version())
+
+		return 0
+	}
+
+	if len(args) == 0 && cli.settings.defaultAction != "" {
+		args = strings.Fields(cli.settings.defaultAction)
+	}
+
+	if len(args) == 0 {
+		fmt.Fprintln(cli.stderr, shortUsage)
+
+		return 1
+	}
+
+	if err := cli.runAction(args[0], args[1:]); err != nil {
+		fmt.Fprintln(cli.stderr, err)
+
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintln(cli.stderr, shortUsage)
+		}
+
+		return 1
+	}
+
+	return 0
+}
+
+// ----------------------------------------------------------------------------
+//  Type: flags
+// ----------------------------------------------------------------------------
+
+// flags holds the options of the command line. The options which change the
+// settings are applied after loading the config file, in order of appearance.
+type flags struct {
+	apply      []func(*settings)
+	configFile string
+	help       bool
+	version    bool
+}
+
+// parseFlags parses the leading options of the command line in the same way as
+// getopts of todo.sh. Such as "-fa" or "-d todo.cfg". It returns the remaining
+// arguments, which are the action and its arguments.
+//
+//nolint:cyclop,funlen // complexity is 18 but it is a flat list of the options
+func parseFlags(args []string) (*flags, []string, error) {
+	result := new(flags)
+
+	for len(args) > 0 && len(args[0]) > 1 && args[0][0] == '-' {
+		arg := args[0]
+		args = args[1:]
+
+		if arg == "--" {
+			break
+		}
+
+		for i := 1; i < len(arg); i++ {
+			var apply func(*settings)
+
+			switch arg[i] {
+			case 'a':
+				apply = func(s *settings) { s.autoArchive = false }
+			case 'A':
+				apply = func(s *settings) { s.autoArchive = true }
+			case 'c', 'p', 'x':
+				// colors and final filters are not supported, the output is always plain
+			case 'd':
+				if i+1 < len(arg) {
+					result.configFile = arg[i+1:]
+				} else if len(args) > 0 {
+					result.configFile, args = args[0], args[1:]
+				} else {
+					return nil, nil, newUsageError("Error: option -d requires a config file")
+				}
+
+				i = len(arg)
+			case 'f':
+				apply = func(s *settings) { s.force = true }
+			case 'h':
+				result.help = true
+			case 'n':
+				apply = func(s *settings) { s.preserveLineNumbers = false }
+			case 'N':
+				apply = func(s *settings) { s.preserveLineNumbers = true }
+			case 't':
+				apply = func(s *settings) { s.dateOnAdd = true }
+			case 'T':
+				apply = func(s *settings) { s.dateOnAdd = false }
+			case 'v':
+				apply = func(s *settings) { s.verbose++ }
+			case 'V':
+				result.version = true
+			case '@':
+				apply = func(s *settings) { s.hideContexts = !s.hideContexts }
+			case '+':
+				apply = func(s *settings) { s.hideProjects = !s.hideProjects }
+			case 'P':
+				apply = func(s *settings) { s.hidePriority = !s.hidePriority }
+			default:
+				return nil, nil, newUsageError("Error: unknown option -%c", arg[i])
+			}
+
+			if apply != nil {
+				result.apply = append(result.apply, apply)
+			}
+		}
+	}
+
+	return result, args, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// version returns the module version of the binary.
+func version() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "(devel)"
+}
+
+// ----------------------------------------------------------------------------
+//  Usage
+// ----------------------------------------------------------------------------
+
+const shortUsage = `Usage: todotxt [-fhpantvAINTV@+P] [-d todo_config] action [task_number] [task_description]
+Try 'todotxt -h' for more information.`
+
+const usage = `Usage: todotxt [-fhpantvAINTV@+P] [-d todo_config] action [task_number] [task_description]
+
+Actions:
+  add "THING I NEED TO DO +project @context"
+  a "THING I NEED TO DO +project @context"
+    Adds THING I NEED TO DO to your todo.txt file on its own line.
+
+  append ITEM# "TEXT TO APPEND"
+  app ITEM# "TEXT TO APPEND"
+    Adds TEXT TO APPEND to the end of the task on line ITEM#.
+
+  archive
+    Moves all done tasks from todo.txt to done.txt and removes blank lines.
+
+  del ITEM# [TERM]
+  rm ITEM# [TERM]
+    Deletes the task on line ITEM# in todo.txt. If TERM specified, deletes
+    only TERM from the task.
+
+  depri ITEM#[, ITEM#, ITEM#, ...]
+  dp ITEM#[, ITEM#, ITEM#, ...]
+    Deprioritizes (removes the priority) from the task(s) on line ITEM#.
+
+  do ITEM#[, ITEM#, ITEM#, ...]
+    Marks task(s) on line ITEM# as done in todo.txt.
+
+  help
+    Display this help message.
+
+  list [TERM...]
+  ls [TERM...]
+    Displays all tasks that contain TERM(s) sorted by priority with line
+    numbers. Each task must match all TERM(s). Hides all tasks that contain
+    TERM(s) preceded by a minus sign (i.e. -TERM).
+
+  listcon [TERM...]
+  lsc [TERM...]
+    Lists all the task contexts that start with the @ sign in todo.txt.
+
+  listproj [TERM...]
+  lsprj [TERM...]
+    Lists all the projects (terms that start with a + sign) in todo.txt.
+
+  prepend ITEM# "TEXT TO PREPEND"
+  prep ITEM# "TEXT TO PREPEND"
+    Adds TEXT TO PREPEND to the beginning of the task on line ITEM#.
+
+  pri ITEM# PRIORITY
+  p ITEM# PRIORITY
+    Adds PRIORITY to task on line ITEM#. PRIORITY must be a letter A to Z.
+
+  replace ITEM# "UPDATED TODO"
+    Replaces task on line ITEM# with UPDATED TODO.
+
+  report
+    Adds the number of open tasks and done tasks to report.txt.
+
+Options:
+  -@      Hide context names in list output. Use twice to show them (default).
+  -+      Hide project names in list output. Use twice to show them (default).
+  -P      Hide priority labels in list output. Use twice to show them (default).
+  -a      Don't auto-archive tasks automatically on completion.
+  -A      Auto-archive tasks automatically on completion (default).
+  -d CONFIG_FILE
+          Use a configuration file other than the default.
+  -f      Forces actions without confirmation.
+  -h      Display this help message.
+  -n      Don't preserve line numbers; automatically remove blank lines on
+          task deletion.
+  -N      Preserve line numbers (default).
+  -p      Plain mode, accepted for compatibility. The output is always plain.
+  -t      Prepend the current date to a task automatically when it's added.
+  -T      Do not prepend the current date to a task (default).
+  -v      Verbose mode. The actions print confirmation messages (default).
+  -V      Displays version.
+
+Environment variables:
+  TODO_DIR, TODO_FILE, DONE_FILE, REPORT_FILE
+          The location of the files. They take precedence over the config file.
+  TODOTXT_CFG_FILE
+          The config file to use instead of the default ones.
+  TODOTXT_AUTO_ARCHIVE, TODOTXT_DATE_ON_ADD, TODOTXT_FORCE,
+  TODOTXT_PRESERVE_LINE_NUMBERS, TODOTXT_VERBOSE, TODOTXT_DEFAULT_ACTION
+          The same as the options above. Such as TODOTXT_VERBOSE=0.
+
+The config file is the first one found of: $HOME/.todo/config, $HOME/todo.cfg,
+$HOME/.todo.cfg and $XDG_CONFIG_HOME/todo/config. Its "export KEY=VALUE" lines
+are read in the same way as todo.sh.`
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KEINOS/go-todotxt/todo"
+	"github.com/stretchr/testify/require"
+)
+
+// testResult is the result of runTest.
+type testResult struct {
+	stdout string
+	stderr string
+	status int
+}
+
+// runTest runs the command in the given directory, which is TODO_DIR and HOME,
+// on 2024-01-02 12:34:56 local time.
+func runTest(t *testing.T, dir, stdin string, args ...string) testResult {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	env := map[string]string{"HOME": dir, "TODO_DIR": dir}
+
+	cli := &app{
+		clock:    todo.FixedClock(time.Date(2024, 1, 2, 12, 34, 56, 0, time.Local)),
+		stdin:    bufio.NewReader(strings.NewReader(stdin)),
+		stdout:   &stdout,
+		stderr:   &stderr,
+		getenv:   func(key string) string { return env[key] },
+		settings: nil,
+	}
+
+	status := cli.run(args)
+
+	return testResult{stdout: stdout.String(), stderr: stderr.String(), status: status}
+}
+
+// writeTestFile writes the content to the file in the directory and returns
+// the path.
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+// readTestFile returns the content of the file in the directory.
+func readTestFile(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+func TestRun_usage(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	result := runTest(t, dir, "")
+	require.Equal(t, 1, result.status, "no action should be an error")
+	require.Contains(t, result.stderr, "Usage: todotxt ")
+
+	result = runTest(t, dir, "", "-h")
+	require.Equal(t, 0, result.status)
+	require.Contains(t, result.stdout, "Actions:\n")
+
+	result = runTest(t, dir, "", "-z", "ls")
+	require.Equal(t, 1, result.status)
+	require.Equal(t, "Error: unknown option -z\n", strings.SplitAfter(result.stderr, "\n")[0])
+
+	result = runTest(t, dir, "", "unknown")
+	require.Equal(t, 1, result.status)
+	require.True(t, strings.HasPrefix(result.stderr, "TODO: unknown action \"unknown\"\nUsage: "))
+
+	result = runTest(t, dir, "", "-d")
+	require.Equal(t, 1, result.status)
+	require.Contains(t, result.stderr, "option -d requires a config file")
+}
+
+func TestParseFlags(t *testing.T) {
+	t.Parallel()
+
+	result, args, err := parseFlags([]string{"-fan", "-d", "todo.cfg", "-vv", "-@", "-@+", "--", "-ls"})
+	require.NoError(t, err)
+	require.Equal(t, "todo.cfg", result.configFile)
+	require.Equal(t, []string{"-ls"}, args, "arguments after -- should not be options")
+
+	settings := new(settings)
+	settings.autoArchive = true
+	settings.preserveLineNumbers = true
+
+	for _, apply := range result.apply {
+		apply(settings)
+	}
+
+	require.True(t, settings.force)
+	require.False(t, settings.autoArchive)
+	require.False(t, settings.preserveLineNumbers)
+	require.Equal(t, 2, settings.verbose)
+	require.False(t, settings.hideContexts, "twice should show the contexts")
+	require.True(t, settings.hideProjects)
+
+	result, args, err = parseFlags([]string{"-dtodo.cfg", "ls", "-f"})
+	require.NoError(t, err)
+	require.Equal(t, "todo.cfg", result.configFile)
+	require.Equal(t, []string{"ls", "-f"}, args, "options after the action should be arguments")
+}
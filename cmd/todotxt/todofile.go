@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/KEINOS/go-todotxt/todo"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Loading and saving
+// ----------------------------------------------------------------------------
+//  The files are loaded as one entry per line, so that the index of an entry
+//  is its line number minus 1 as in todo.sh. Blank lines are non-task entries
+//  and the lines which failed to parse are kept as is. The tasks are written
+//  in the order of the original line. See todo.WithPreserveOriginal.
+
+// options returns the todo options to load and write the files losslessly.
+func (cli *app) options() []todo.Option {
+	return []todo.Option{
+		todo.WithClock(cli.clock),
+		todo.WithIgnoreComments(false),
+		todo.WithKeepNonTaskLines(true),
+		todo.WithNewLine("\n"),
+		todo.WithPreserveOriginal(true),
+		todo.WithRemoveCompletedPriority(false),
+	}
+}
+
+// load returns the lines of the given file. A missing file is an empty list.
+func (cli *app) load(filename string) (todo.TaskList, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return todo.NewTaskList(), nil
+		}
+
+		return nil, errors.Wrap(err, "Fatal Error: Cannot read file "+filename)
+	}
+	defer file.Close()
+
+	lines := todo.NewTaskList()
+	reader := todo.NewReader(file, cli.options()...)
+
+	for {
+		task, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			var parseErr *todo.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, errors.Wrap(err, "Fatal Error: Cannot read file "+filename)
+			}
+
+			// Keep the line as is to not lose it on writing
+			//nolint:exhaustruct // other fields are missing intentionally
+			lines = append(lines, todo.Task{Original: parseErr.Text, NonTask: true})
+
+			continue
+		}
+
+		lines = append(lines, *task)
+	}
+
+	return lines, nil
+}
+
+// save writes the lines to the given file.
+func (cli *app) save(filename string, lines todo.TaskList) error {
+	return lines.WriteToPath(filename, cli.options()...)
+}
+
+// lineString returns the line of the task as written in the file.
+func (cli *app) lineString(task *todo.Task) string {
+	return task.StringWith(cli.options()...)
+}
+
+// parseLine parses the given text as a task line.
+func (cli *app) parseLine(text string) (*todo.Task, error) {
+	task, err := todo.ParseTask(text, cli.options()...)
+	if err != nil {
+		return nil, errors.Wrap(err, "TODO: invalid task")
+	}
+
+	return task, nil
+}
+
+// taskAt returns the task at the given line number, which is an argument of
+// the action.
+func (cli *app) taskAt(lines todo.TaskList, item string) (*todo.Task, error) {
+	num, err := strconv.Atoi(item)
+	if err != nil {
+		return nil, newUsageError("TODO: invalid task number %q", item)
+	}
+
+	if num < 1 || num > len(lines) || isBlank(&lines[num-1]) {
+		return nil, errors.Errorf("TODO: No task %d.", num)
+	}
+
+	task := &lines[num-1]
+	if task.NonTask {
+		// Report why the line failed to parse
+		if _, err := cli.parseLine(task.Original); err != nil {
+			return nil, errors.Wrapf(err, "TODO: line %d", num)
+		}
+	}
+
+	return task, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// countLines returns the number of lines which are not blank.
+func countLines(lines todo.TaskList) int {
+	count := 0
+
+	for i := range lines {
+		if !isBlank(&lines[i]) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// isBlank returns true if the entry is a blank line.
+func isBlank(task *todo.Task) bool {
+	return task.NonTask && strings.TrimSpace(task.Original) == ""
+}
+
+// splitItems returns the task numbers of the arguments. Such as "1 2 3" or
+// "1,2,3" or "1, 2, 3".
+func splitItems(args []string) []string {
+	return strings.FieldsFunc(strings.Join(args, " "), func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
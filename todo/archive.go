@@ -0,0 +1,264 @@
+package todo
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: ArchiveOptions
+// ----------------------------------------------------------------------------
+
+// ArchiveOptions represents the options of archiving. The zero value is the
+// default.
+type ArchiveOptions struct {
+	// KeepIDs keeps the IDs of the remaining tasks. They are the IDs given on
+	// loading, which number the tasks in order without the blank and comment
+	// lines. If 'false', the remaining tasks are renumbered from 1.
+	KeepIDs bool
+}
+
+// ----------------------------------------------------------------------------
+//  TaskList.Archive()
+// ----------------------------------------------------------------------------
+
+// Archive removes the completed tasks from the TaskList and returns them as a
+// new TaskList, which is the "done" list. Non-task entries stay in the TaskList.
+//
+// The remaining tasks are renumbered from 1 in order, unless the KeepIDs option
+// is set. Such as:
+//
+//	done := tasklist.Archive(todo.ArchiveOptions{KeepIDs: true})
+func (tasklist *TaskList) Archive(archiveOpts ArchiveOptions) TaskList {
+	done := NewTaskList()
+	remaining := make(TaskList, 0, len(*tasklist))
+
+	for _, task := range *tasklist {
+		if !task.NonTask && task.Completed {
+			done = append(done, task)
+
+			continue
+		}
+
+		remaining = append(remaining, task)
+	}
+
+	if !archiveOpts.KeepIDs {
+		taskID := 0
+
+		for i := range remaining {
+			if !remaining[i].NonTask {
+				taskID++
+				remaining[i].ID = taskID
+			}
+		}
+	}
+
+	*tasklist = remaining
+
+	return done
+}
+
+// AppendToPath appends the tasks to the specified file (most likely called
+// "done.txt") without rewriting it. The file is created if it does not exist.
+//
+// All the tasks are appended, even if the same lines are already in the file.
+// Such as a recurring task completed on the same date. It returns the number of
+// appended tasks. Non-task entries are not appended.
+func (tasklist *TaskList) AppendToPath(filename string, opts ...Option) (int, error) {
+	cfg := NewConfig(opts...)
+
+	lines := make([]string, 0, len(*tasklist))
+
+	for _, task := range *tasklist {
+		if !task.NonTask {
+			lines = append(lines, task.stringWith(cfg))
+		}
+	}
+
+	if len(lines) == 0 {
+		return 0, nil
+	}
+
+	needNewLine, err := lacksTrailingNewLine(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, PermReadWrite)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to open file to append: "+filename)
+	}
+	defer file.Close()
+
+	var strBld strings.Builder
+
+	if needNewLine {
+		strBld.WriteString(cfg.NewLine)
+	}
+
+	for _, line := range lines {
+		strBld.WriteString(line + cfg.NewLine)
+	}
+
+	if _, err := file.WriteString(strBld.String()); err != nil {
+		return 0, errors.Wrap(err, "failed to append tasks to the path: "+filename)
+	}
+
+	return len(lines), errors.Wrap(file.Close(), "failed to close file: "+filename)
+}
+
+// ----------------------------------------------------------------------------
+//  Public functions
+// ----------------------------------------------------------------------------
+
+// ArchiveToPath moves the completed tasks from the todo file to the done file.
+// It is the standard archive workflow of todo.txt.
+//
+// The remaining tasks are written to a temporary file next to the todo file,
+// which replaces the todo file after the completed tasks are appended to the
+// done file. If it fails in the middle, the appended tasks are removed from the
+// done file. So both files are left as they were, and it is safe to run again.
+// It returns the archived tasks.
+//
+// The options are used on both loading and writing. Use WithKeepNonTaskLines(true)
+// to keep the comment and blank lines of the todo file.
+func ArchiveToPath(todoPath, donePath string, archiveOpts ArchiveOptions, opts ...Option) (TaskList, error) {
+	return archiveToPath(todoPath, donePath, archiveOpts, os.Rename, opts...)
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// archiveToPath is the same as ArchiveToPath but with the given function to
+// replace the todo file with the temporary file.
+func archiveToPath(
+	todoPath, donePath string, archiveOpts ArchiveOptions, rename func(oldPath, newPath string) error, opts ...Option,
+) (TaskList, error) {
+	tasklist, err := LoadFromPath(todoPath, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	done := tasklist.Archive(archiveOpts)
+	if len(done) == 0 {
+		return done, nil
+	}
+
+	doneInfo, err := os.Stat(donePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Wrap(err, "failed to stat file: "+donePath)
+	}
+
+	if doneInfo != nil && doneInfo.IsDir() {
+		return nil, errors.New("failed to open file: " + donePath + " is a directory")
+	}
+
+	tempPath, err := writeTempFile(todoPath, tasklist.StringWith(opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = os.Remove(tempPath) }() // no-op once renamed
+
+	if _, err := done.AppendToPath(donePath, opts...); err != nil {
+		return nil, undoAppend(donePath, doneInfo, err)
+	}
+
+	if err := rename(tempPath, todoPath); err != nil {
+		return nil, undoAppend(donePath, doneInfo, errors.Wrap(err, "failed to replace the todo file: "+todoPath))
+	}
+
+	return done, nil
+}
+
+// lacksTrailingNewLine returns true if the file is not empty and does not end
+// with a new line. It is 'false' if the file does not exist.
+func lacksTrailingNewLine(filename string) (bool, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, errors.Wrap(err, "failed to open file: "+filename)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to stat file: "+filename)
+	}
+
+	if info.IsDir() {
+		return false, errors.New("failed to open file: " + filename + " is a directory")
+	}
+
+	if info.Size() == 0 {
+		return false, nil
+	}
+
+	lastByte := make([]byte, 1)
+
+	if _, err := file.ReadAt(lastByte, info.Size()-1); err != nil && !errors.Is(err, io.EOF) {
+		return false, errors.Wrap(err, "failed to read file: "+filename)
+	}
+
+	return lastByte[0] != '\n', nil
+}
+
+// undoAppend restores the done file to the given state before appending, and
+// returns the given error of archiving. If the file did not exist, it is
+// removed.
+func undoAppend(donePath string, before os.FileInfo, archiveErr error) error {
+	var err error
+
+	if before == nil {
+		err = os.Remove(donePath)
+	} else {
+		err = os.Truncate(donePath, before.Size())
+	}
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrapf(archiveErr, "failed to remove the archived tasks from %s (%v)", donePath, err)
+	}
+
+	return archiveErr
+}
+
+// writeTempFile writes the text to a new temporary file in the directory of the
+// given file, with the same permission. It returns the path of the temporary
+// file.
+func writeTempFile(filename, text string) (string, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return emptyStr, errors.Wrap(err, "failed to stat file: "+filename)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return emptyStr, errors.Wrap(err, "failed to create temporary file for: "+filename)
+	}
+
+	_, err = file.WriteString(text)
+	if err == nil {
+		err = file.Chmod(info.Mode().Perm())
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(file.Name())
+
+		return emptyStr, errors.Wrap(err, "failed to write temporary file for: "+filename)
+	}
+
+	return file.Name(), nil
+}
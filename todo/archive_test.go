@@ -0,0 +1,177 @@
+package todo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskList_Archive(t *testing.T) {
+	t.Parallel()
+
+	text := "(A) Call Mom\nx 2020-05-10 Pay rent\n# comment\nWrite report\nx Water plants\n"
+
+	// Renumber the remaining tasks
+	{
+		tasklist, err := LoadFromString(text, WithKeepNonTaskLines(true))
+		require.NoError(t, err)
+
+		done := tasklist.Archive(ArchiveOptions{})
+
+		require.Equal(t, "x 2020-05-10 Pay rent\nx Water plants\n", done.String())
+		require.Equal(t, "(A) Call Mom\n# comment\nWrite report\n", tasklist.String(),
+			"non-task entries should stay")
+
+		task, err := tasklist.GetTask(2)
+		require.NoError(t, err)
+		require.Equal(t, "Write report", task.Todo, "remaining tasks should be renumbered")
+	}
+
+	// Keep the IDs
+	{
+		tasklist, err := LoadFromString(text)
+		require.NoError(t, err)
+
+		tasklist.Archive(ArchiveOptions{KeepIDs: true})
+
+		task, err := tasklist.GetTask(3)
+		require.NoError(t, err)
+		require.Equal(t, "Write report", task.Todo, "IDs should be kept")
+	}
+}
+
+func TestArchiveToPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	todoPath := filepath.Join(dir, "todo.txt")
+	donePath := filepath.Join(dir, "done.txt")
+
+	require.NoError(t, os.WriteFile(todoPath,
+		[]byte("(A) Call Mom\nx 2020-05-10 Pay rent\nx Water plants\n"), PermReadWrite))
+	// done.txt without the new line at the end
+	require.NoError(t, os.WriteFile(donePath, []byte("x 2020-05-01 Old task"), PermReadWrite))
+
+	done, err := ArchiveToPath(todoPath, donePath, ArchiveOptions{})
+	require.NoError(t, err)
+	require.Len(t, done, 2)
+
+	todoText, err := os.ReadFile(todoPath)
+	require.NoError(t, err)
+	require.Equal(t, "(A) Call Mom\n", string(todoText))
+
+	expectDone := "x 2020-05-01 Old task\nx 2020-05-10 Pay rent\nx Water plants\n"
+
+	doneText, err := os.ReadFile(donePath)
+	require.NoError(t, err)
+	require.Equal(t, expectDone, string(doneText))
+
+	// Duplicate lines, such as a recurring task completed on the same date,
+	// are archived as they are
+	require.NoError(t, os.WriteFile(todoPath, []byte("(A) Call Mom\nx Water plants\n"), PermReadWrite))
+
+	done, err = ArchiveToPath(todoPath, donePath, ArchiveOptions{})
+	require.NoError(t, err)
+	require.Len(t, done, 1)
+
+	doneText, err = os.ReadFile(donePath)
+	require.NoError(t, err)
+	require.Equal(t, expectDone+"x Water plants\n", string(doneText), "duplicate tasks should not be lost")
+
+	// Nothing to archive
+	done, err = ArchiveToPath(todoPath, donePath, ArchiveOptions{})
+	require.NoError(t, err)
+	require.Empty(t, done)
+}
+
+func TestArchiveToPath_errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	_, err := ArchiveToPath(filepath.Join(dir, "missing.txt"), filepath.Join(dir, "done.txt"), ArchiveOptions{})
+	require.Error(t, err, "missing todo file should be an error")
+
+	todoPath := filepath.Join(dir, "todo.txt")
+	require.NoError(t, os.WriteFile(todoPath, []byte("x Pay rent\n"), PermReadWrite))
+
+	_, err = ArchiveToPath(todoPath, dir, ArchiveOptions{})
+	require.Error(t, err, "directory as done file should be an error")
+
+	todoText, err := os.ReadFile(todoPath)
+	require.NoError(t, err)
+	require.Equal(t, "x Pay rent\n", string(todoText), "todo file should not be changed on error")
+}
+
+func TestArchiveToPath_undo(t *testing.T) {
+	t.Parallel()
+
+	const todoText = "(A) Call Mom\nx 2020-05-10 Pay rent\n"
+
+	failRename := func(_, _ string) error { return errors.New("forced error") }
+
+	for _, doneText := range []string{"x 2020-05-01 Old task", ""} {
+		dir := t.TempDir()
+		todoPath := filepath.Join(dir, "todo.txt")
+		donePath := filepath.Join(dir, "done.txt")
+
+		require.NoError(t, os.WriteFile(todoPath, []byte(todoText), PermReadWrite))
+
+		if doneText != "" {
+			require.NoError(t, os.WriteFile(donePath, []byte(doneText), PermReadWrite))
+		}
+
+		_, err := archiveToPath(todoPath, donePath, ArchiveOptions{}, failRename)
+		require.ErrorContains(t, err, "forced error")
+
+		// Both files should be left as they were
+		actual, err := os.ReadFile(todoPath)
+		require.NoError(t, err)
+		require.Equal(t, todoText, string(actual))
+
+		if doneText == "" {
+			require.NoFileExists(t, donePath, "new done file should be removed")
+		} else {
+			actual, err = os.ReadFile(donePath)
+			require.NoError(t, err)
+			require.Equal(t, doneText, string(actual), "appended tasks should be removed")
+		}
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+
+		for _, entry := range entries {
+			require.Contains(t, []string{"todo.txt", "done.txt"}, entry.Name(), "temporary file should be removed")
+		}
+
+		// Safe to run again
+		done, err := ArchiveToPath(todoPath, donePath, ArchiveOptions{})
+		require.NoError(t, err)
+		require.Len(t, done, 1)
+	}
+}
+
+func TestTaskList_AppendToPath(t *testing.T) {
+	t.Parallel()
+
+	donePath := filepath.Join(t.TempDir(), "done.txt")
+
+	tasklist, err := LoadFromString("x 2020-05-10 Water plants rec:1d\nx 2020-05-10 Water plants rec:1d\n")
+	require.NoError(t, err)
+
+	count, err := tasklist.AppendToPath(donePath)
+	require.NoError(t, err)
+	require.Equal(t, 2, count, "duplicate tasks should be counted")
+
+	count, err = tasklist.AppendToPath(donePath)
+	require.NoError(t, err)
+	require.Equal(t, 2, count, "tasks already in the file should be appended")
+
+	doneText, err := os.ReadFile(donePath)
+	require.NoError(t, err)
+	require.Equal(t, 4, strings.Count(string(doneText), "x 2020-05-10 Water plants rec:1d\n"))
+}
@@ -0,0 +1,34 @@
+package todo
+
+import "time"
+
+// ----------------------------------------------------------------------------
+//  Type: Clock
+// ----------------------------------------------------------------------------
+
+// Clock provides the current time. It is used to get "now" and "today" in the
+// time-dependent functions, so that they can be tested deterministically. See
+// WithClock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to use an ordinary function as a Clock.
+type ClockFunc func() time.Time
+
+// Now returns the current time by calling the function itself.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock which returns time.Now. It is the default Clock of
+// Config.
+//
+//nolint:gochecknoglobals // global variable is intentional as it is stateless
+var SystemClock Clock = ClockFunc(time.Now)
+
+// FixedClock returns a Clock which always returns the given time.
+func FixedClock(now time.Time) Clock {
+	return ClockFunc(func() time.Time { return now })
+}
@@ -0,0 +1,581 @@
+package todo
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: ColorMode
+// ----------------------------------------------------------------------------
+
+// ColorMode represents the color capability of the terminal to colorize the
+// tasks. See Task.Colorize.
+type ColorMode uint8
+
+// Color modes. The colors of the styles are converted to the nearest ones that
+// the mode supports.
+const (
+	ColorAuto      ColorMode = iota + 1 // ColorAuto detects the mode from the environment. See DetectColorMode.
+	ColorNone                           // ColorNone writes no escape sequences.
+	Color16                             // Color16 uses the 16 basic colors.
+	Color256                            // Color256 uses the 256 indexed colors.
+	ColorTrueColor                      // ColorTrueColor uses the 24-bit RGB colors.
+)
+
+// DetectColorMode returns the color mode of the terminal from the environment
+// variables. It follows the NO_COLOR convention (https://no-color.org/), then
+// COLORTERM and TERM:
+//
+//   - NO_COLOR is set and not empty, or TERM is "dumb" or empty: ColorNone.
+//   - COLORTERM is "truecolor" or "24bit": ColorTrueColor.
+//   - TERM contains "256color": Color256.
+//   - Otherwise: Color16.
+//
+// Note that it does not check whether the output is a terminal.
+func DetectColorMode() ColorMode {
+	return detectColorMode(os.Getenv)
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Color
+// ----------------------------------------------------------------------------
+
+// Color represents a color of a Style. The zero value is the default color of
+// the terminal.
+type Color struct {
+	kind  colorKind
+	index uint8
+	red   uint8
+	green uint8
+	blue  uint8
+}
+
+// colorKind represents how the Color is specified.
+type colorKind uint8
+
+const (
+	colorDefault colorKind = iota
+	colorBasic
+	colorIndexed
+	colorRGB
+)
+
+// BasicColor returns one of the 16 basic colors. 0 to 7 are black, red, green,
+// yellow, blue, magenta, cyan and white, and 8 to 15 are the bright ones. The
+// values over 15 are taken modulo 16.
+func BasicColor(index uint8) Color {
+	return Color{kind: colorBasic, index: index % basicColorCount, red: 0, green: 0, blue: 0}
+}
+
+// IndexedColor returns one of the 256 indexed colors of the terminal.
+func IndexedColor(index uint8) Color {
+	return Color{kind: colorIndexed, index: index, red: 0, green: 0, blue: 0}
+}
+
+// RGBColor returns the 24-bit color.
+func RGBColor(red, green, blue uint8) Color {
+	return Color{kind: colorRGB, index: 0, red: red, green: green, blue: blue}
+}
+
+// IsZero returns true if the color is the default color of the terminal.
+func (color Color) IsZero() bool {
+	return color.kind == colorDefault
+}
+
+// sgr returns the SGR parameters of the color in the given mode. The base is 30
+// for the foreground and 40 for the background.
+func (color Color) sgr(mode ColorMode, base int) string {
+	switch {
+	case color.kind == colorDefault:
+		return emptyStr
+	case color.kind == colorBasic:
+		return basicColorSGR(color.index, base)
+	case mode == Color16:
+		if color.kind == colorIndexed && color.index < basicColorCount {
+			return basicColorSGR(color.index, base)
+		}
+
+		return basicColorSGR(nearestBasicColor(color.rgb()), base)
+	case color.kind == colorIndexed:
+		return strconv.Itoa(base+8) + ";5;" + strconv.Itoa(int(color.index)) //nolint:mnd // 38 or 48
+	case mode == Color256:
+		return strconv.Itoa(base+8) + ";5;" + strconv.Itoa(int(nearestIndexedColor(color.rgb()))) //nolint:mnd // 38 or 48
+	}
+
+	return strconv.Itoa(base+8) + ";2;" + //nolint:mnd // 38 or 48
+		strconv.Itoa(int(color.red)) + ";" + strconv.Itoa(int(color.green)) + ";" + strconv.Itoa(int(color.blue))
+}
+
+// rgb returns the RGB values of the color. The indexed colors are the ones of
+// the xterm palette.
+func (color Color) rgb() [3]uint8 {
+	switch color.kind {
+	case colorRGB:
+		return [3]uint8{color.red, color.green, color.blue}
+	case colorBasic, colorIndexed:
+		return indexedColorRGB(color.index)
+	case colorDefault:
+	}
+
+	return [3]uint8{}
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Style
+// ----------------------------------------------------------------------------
+
+// Style represents the appearance of a segment in the terminal.
+type Style struct {
+	Foreground    Color
+	Background    Color
+	Bold          bool
+	Faint         bool
+	Italic        bool
+	Underline     bool
+	Strikethrough bool
+}
+
+// Render returns the text with the escape sequences of the style in the given
+// mode. It returns the text as is for ColorNone or the zero Style.
+func (style Style) Render(text string, mode ColorMode) string {
+	if mode == ColorAuto {
+		mode = DetectColorMode()
+	}
+
+	if mode == ColorNone {
+		return text
+	}
+
+	params := make([]string, 0, 7) //nolint:mnd // max number of the parameters
+
+	for _, attr := range []struct {
+		code string
+		on   bool
+	}{
+		{code: "1", on: style.Bold},
+		{code: "2", on: style.Faint},
+		{code: "3", on: style.Italic},
+		{code: "4", on: style.Underline},
+		{code: "9", on: style.Strikethrough},
+	} {
+		if attr.on {
+			params = append(params, attr.code)
+		}
+	}
+
+	if fg := style.Foreground.sgr(mode, 30); isNotEmpty(fg) { //nolint:mnd // foreground colors
+		params = append(params, fg)
+	}
+
+	if bg := style.Background.sgr(mode, 40); isNotEmpty(bg) { //nolint:mnd // background colors
+		params = append(params, bg)
+	}
+
+	if len(params) == 0 {
+		return text
+	}
+
+	return "\x1b[" + strings.Join(params, ";") + "m" + text + "\x1b[0m"
+}
+
+// merge returns the style with the given style over it. The colors of the given
+// style take precedence if set, and the attributes are combined.
+func (style Style) merge(over Style) Style {
+	if !over.Foreground.IsZero() {
+		style.Foreground = over.Foreground
+	}
+
+	if !over.Background.IsZero() {
+		style.Background = over.Background
+	}
+
+	style.Bold = style.Bold || over.Bold
+	style.Faint = style.Faint || over.Faint
+	style.Italic = style.Italic || over.Italic
+	style.Underline = style.Underline || over.Underline
+	style.Strikethrough = style.Strikethrough || over.Strikethrough
+
+	return style
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Theme
+// ----------------------------------------------------------------------------
+
+// Theme is the set of the styles to colorize the tasks. See DefaultTheme.
+type Theme struct {
+	// Segments are the styles of the segment types. The segment types not in
+	// the map are not styled.
+	Segments map[TaskSegmentType]Style
+	// Overdue is the style of the due date of the overdue tasks, instead of the
+	// one of SegmentDueDate.
+	Overdue Style
+	// DueToday is the style of the due date of the tasks due today, instead of
+	// the one of SegmentDueDate.
+	DueToday Style
+	// Completed is applied over the styles of all the segments of the completed
+	// tasks. Such as Faint to dim them.
+	Completed Style
+}
+
+// DefaultTheme returns the default theme, which uses the 16 basic colors only.
+// Modify the returned one to customize it. Such as:
+//
+//	theme := todo.DefaultTheme()
+//	theme.Segments[todo.SegmentProject] = todo.Style{Foreground: todo.RGBColor(255, 136, 0)}
+//
+//	fmt.Println(task.Colorize(todo.ColorOptions{Theme: theme}))
+func DefaultTheme() *Theme {
+	return &Theme{
+		Segments: map[TaskSegmentType]Style{
+			SegmentIsCompleted:   {Faint: true},
+			SegmentCompletedDate: {Faint: true},
+			SegmentPriority:      {Bold: true, Foreground: BasicColor(3)},
+			SegmentCreatedDate:   {Faint: true},
+			SegmentContext:       {Foreground: BasicColor(2)},
+			SegmentProject:       {Foreground: BasicColor(4)},
+			SegmentTag:           {Foreground: BasicColor(6)},
+			SegmentDueDate:       {Foreground: BasicColor(5)},
+			SegmentThresholdDate: {Faint: true},
+		},
+		Overdue:   Style{Bold: true, Foreground: BasicColor(9)},
+		DueToday:  Style{Bold: true, Foreground: BasicColor(11)},
+		Completed: Style{Faint: true},
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Type: ColorOptions
+// ----------------------------------------------------------------------------
+
+// ColorOptions represents the options to colorize the tasks. The zero value is
+// the default, which is the DefaultTheme in the detected ColorMode without the
+// width limit.
+type ColorOptions struct {
+	// Theme is the set of the styles. The default is nil, which is DefaultTheme.
+	Theme *Theme
+	// Width is the max number of the columns of a colorized task. The default
+	// is 0, which is unlimited.
+	Width int
+	// Mode is the color capability of the terminal. The default is ColorAuto,
+	// which detects it from the environment, such as NO_COLOR. See
+	// DetectColorMode.
+	Mode ColorMode
+	// Inline colorizes the InlineSegments of the task, in order of the task
+	// line. The default is 'false', which uses Task.Segments.
+	Inline bool
+}
+
+// normalized returns the options with the theme and the detected mode.
+func (o ColorOptions) normalized() ColorOptions {
+	if o.Theme == nil {
+		o.Theme = DefaultTheme()
+	}
+
+	if o.Mode == 0 || o.Mode == ColorAuto {
+		o.Mode = DetectColorMode()
+	}
+
+	return o
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of Task
+// ----------------------------------------------------------------------------
+
+// Colorize returns the task string with the ANSI escape sequences to colorize
+// it in the terminal. Each segment of Task.Segments is styled by the Theme of
+// the ColorOptions, and the due date is styled by the state of the task, such
+// as overdue and due today. The contexts and projects in the todo text are not
+// repeated.
+//
+// The colors are converted for the Mode of the ColorOptions, and no escape
+// sequences are written for ColorNone. If the Width is set, the trailing
+// segments which do not fit the width are replaced with "…", so that a segment
+// is never broken. Such as:
+//
+//	fmt.Println(task.Colorize(todo.ColorOptions{Mode: todo.Color256, Width: 80}))
+//
+// The options are used to get "today" and to write the segments. See Config.
+func (task *Task) Colorize(colorOpts ColorOptions, opts ...Option) string {
+	return task.colorize(colorOpts.normalized(), NewConfig(opts...))
+}
+
+// colorize is the same as Colorize but with the normalized options and the
+// given configuration.
+func (task *Task) colorize(colorOpts ColorOptions, cfg *Config) string {
+	allSegs := task.Segments(WithConfig(*cfg))
+	if colorOpts.Inline {
+		allSegs = task.inlineSegments(cfg)
+	}
+
+	var segs []*TaskSegment
+
+	for _, seg := range allSegs {
+		// The contexts and projects in the todo text are already displayed
+		if !colorOpts.Inline && (seg.Type == SegmentContext || seg.Type == SegmentProject) &&
+			strings.Contains(task.Todo, seg.Display) {
+			continue
+		}
+
+		segs = append(segs, seg)
+	}
+
+	const ellipsisWidth = 2 // width of " …"
+
+	// Drop the trailing segments which do not fit the width with the ellipsis
+	truncated := colorOpts.Width > 0 && segmentsWidth(segs) > colorOpts.Width
+	if truncated {
+		for len(segs) > 0 && segmentsWidth(segs)+ellipsisWidth > colorOpts.Width {
+			segs = segs[:len(segs)-1]
+		}
+	}
+
+	parts := make([]string, 0, len(segs)+1)
+
+	for _, seg := range segs {
+		parts = append(parts, task.segmentStyle(seg, colorOpts.Theme, cfg).Render(seg.Display, colorOpts.Mode))
+	}
+
+	if truncated && (len(segs) > 0 || colorOpts.Width >= 1) {
+		parts = append(parts, "…")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// segmentStyle returns the style of the segment by the theme and the state of
+// the task.
+func (task *Task) segmentStyle(seg *TaskSegment, theme *Theme, cfg *Config) Style {
+	style := theme.Segments[seg.Type]
+
+	if seg.Type == SegmentDueDate && !task.Completed {
+		switch {
+		case task.isOverdue(cfg):
+			style = theme.Overdue
+		case task.isDueToday(cfg):
+			style = theme.DueToday
+		}
+	}
+
+	if task.Completed {
+		style = style.merge(theme.Completed)
+	}
+
+	return style
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of TaskList
+// ----------------------------------------------------------------------------
+
+// WriteColorized writes the colorized tasks, one per line. See Task.Colorize.
+// Non-task entries are written as is.
+func (tasklist *TaskList) WriteColorized(writer io.Writer, colorOpts ColorOptions, opts ...Option) error {
+	cfg := NewConfig(opts...)
+	colorOpts = colorOpts.normalized()
+
+	bufWriter := bufio.NewWriter(writer)
+
+	for i := range *tasklist {
+		task := &(*tasklist)[i]
+
+		line := task.Original
+		if !task.NonTask {
+			line = task.colorize(colorOpts, cfg)
+		}
+
+		if _, err := bufWriter.WriteString(line + cfg.NewLine); err != nil {
+			return errors.Wrap(err, "failed to write colorized task")
+		}
+	}
+
+	return errors.Wrap(bufWriter.Flush(), "failed to write colorized tasks")
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// basicColorCount is the number of the basic colors.
+const basicColorCount = 16
+
+// basicColorSGR returns the SGR parameter of the basic color.
+func basicColorSGR(index uint8, base int) string {
+	if index < 8 { //nolint:mnd // normal colors
+		return strconv.Itoa(base + int(index))
+	}
+
+	return strconv.Itoa(base + 60 + int(index) - 8) //nolint:mnd // bright colors are 90-97 and 100-107
+}
+
+// detectColorMode is the same as DetectColorMode but with the given function to
+// get the environment variables.
+func detectColorMode(getenv func(string) string) ColorMode {
+	term := getenv("TERM")
+
+	switch colorTerm := strings.ToLower(getenv("COLORTERM")); {
+	case isNotEmpty(getenv("NO_COLOR")), term == "dumb":
+		return ColorNone
+	case colorTerm == "truecolor", colorTerm == "24bit":
+		return ColorTrueColor
+	case isEmpty(term):
+		return ColorNone
+	case strings.Contains(term, "256color"):
+		return Color256
+	}
+
+	return Color16
+}
+
+// displayWidth returns the number of the columns of the text in the terminal.
+// The East Asian wide characters and the emojis take two columns.
+func displayWidth(text string) int {
+	width := 0
+
+	for len(text) > 0 {
+		char, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+
+		if isWideRune(char) {
+			width += 2
+		} else {
+			width++
+		}
+	}
+
+	return width
+}
+
+// indexedColorRGB returns the RGB values of the indexed color of the xterm
+// palette.
+func indexedColorRGB(index uint8) [3]uint8 {
+	switch {
+	case index < basicColorCount:
+		return xtermBasicColors[index]
+	case index >= 232: //nolint:mnd // grayscale ramp
+		gray := 8 + 10*(index-232) //nolint:mnd // 8, 18, ..., 238
+
+		return [3]uint8{gray, gray, gray}
+	}
+
+	cube := index - basicColorCount
+
+	return [3]uint8{
+		xtermCubeLevels[cube/36], //nolint:mnd // 6x6x6 cube
+		xtermCubeLevels[cube/6%6],
+		xtermCubeLevels[cube%6],
+	}
+}
+
+// isWideRune returns true if the rune takes two columns in the terminal.
+func isWideRune(char rune) bool {
+	for _, wide := range [][2]rune{
+		{0x1100, 0x115F},   // Hangul Jamo
+		{0x2E80, 0x303E},   // CJK Radicals to CJK Symbols and Punctuation
+		{0x3041, 0x33FF},   // Hiragana to CJK Compatibility
+		{0x3400, 0x4DBF},   // CJK Unified Ideographs Extension A
+		{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+		{0xA000, 0xA4CF},   // Yi
+		{0xAC00, 0xD7A3},   // Hangul Syllables
+		{0xF900, 0xFAFF},   // CJK Compatibility Ideographs
+		{0xFE30, 0xFE4F},   // CJK Compatibility Forms
+		{0xFF00, 0xFF60},   // Fullwidth Forms
+		{0xFFE0, 0xFFE6},   // Fullwidth Signs
+		{0x1F300, 0x1F64F}, // Miscellaneous Symbols and Pictographs, Emoticons
+		{0x1F900, 0x1F9FF}, // Supplemental Symbols and Pictographs
+		{0x20000, 0x3FFFD}, // CJK Unified Ideographs Extension B and later
+	} {
+		if char >= wide[0] && char <= wide[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nearestBasicColor returns the index of the basic color nearest to the RGB.
+func nearestBasicColor(rgb [3]uint8) uint8 {
+	nearest, minDist := uint8(0), -1
+
+	for index, basic := range xtermBasicColors {
+		if dist := rgbDistance(rgb, basic); minDist < 0 || dist < minDist {
+			nearest, minDist = uint8(index), dist //nolint:gosec // index is less than 16
+		}
+	}
+
+	return nearest
+}
+
+// nearestIndexedColor returns the index of the color of the 6x6x6 cube or the
+// grayscale ramp nearest to the RGB.
+func nearestIndexedColor(rgb [3]uint8) uint8 {
+	cubeIndex := func(value uint8) uint8 {
+		nearest := uint8(0)
+
+		for i, level := range xtermCubeLevels {
+			if rgbDistance([3]uint8{value}, [3]uint8{level}) < rgbDistance([3]uint8{value}, [3]uint8{xtermCubeLevels[nearest]}) {
+				nearest = uint8(i) //nolint:gosec // i is less than 6
+			}
+		}
+
+		return nearest
+	}
+
+	cube := basicColorCount + 36*cubeIndex(rgb[0]) + 6*cubeIndex(rgb[1]) + cubeIndex(rgb[2]) //nolint:mnd // 6x6x6 cube
+
+	average := (int(rgb[0]) + int(rgb[1]) + int(rgb[2])) / 3 //nolint:mnd // average of RGB
+	gray := uint8(232 + min(max((average-8+5)/10, 0), 23))   //nolint:mnd,gosec // nearest of 8, 18, ..., 238
+
+	if rgbDistance(rgb, indexedColorRGB(gray)) < rgbDistance(rgb, indexedColorRGB(cube)) {
+		return gray
+	}
+
+	return cube
+}
+
+// rgbDistance returns the squared distance of the colors.
+func rgbDistance(a, b [3]uint8) int {
+	dist := 0
+
+	for i := range a {
+		diff := int(a[i]) - int(b[i])
+		dist += diff * diff
+	}
+
+	return dist
+}
+
+// segmentsWidth returns the number of the columns of the segments separated by
+// spaces.
+func segmentsWidth(segs []*TaskSegment) int {
+	width := max(len(segs)-1, 0)
+
+	for _, seg := range segs {
+		width += displayWidth(seg.Display)
+	}
+
+	return width
+}
+
+// xtermBasicColors are the RGB values of the basic colors of xterm.
+//
+//nolint:gochecknoglobals // constant table
+var xtermBasicColors = [basicColorCount][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// xtermCubeLevels are the levels of each RGB component of the 6x6x6 cube.
+//
+//nolint:gochecknoglobals // constant table
+var xtermCubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
@@ -0,0 +1,168 @@
+package todo
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testColorizeOptions(opts ...Option) []Option {
+	now := time.Date(2020, 3, 8, 12, 0, 0, 0, time.UTC)
+
+	return append([]Option{WithClock(FixedClock(now)), WithLocation(time.UTC)}, opts...)
+}
+
+func TestTask_Colorize(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("(A) 2020-03-01 Call Mom @phone +family est:30 due:2020-03-09")
+	require.NoError(t, err)
+
+	require.Equal(t,
+		"\x1b[1;33m(A)\x1b[0m \x1b[2m2020-03-01\x1b[0m Call Mom @phone +family "+
+			"\x1b[36mest:30\x1b[0m \x1b[35mdue:2020-03-09\x1b[0m",
+		task.Colorize(ColorOptions{Mode: Color16}, testColorizeOptions()...))
+
+	// No escape sequences
+	require.Equal(t, "(A) 2020-03-01 Call Mom @phone +family est:30 due:2020-03-09",
+		task.Colorize(ColorOptions{Mode: ColorNone}, testColorizeOptions()...))
+
+	// Contexts not in the todo text are styled
+	task.Contexts = append(task.Contexts, "home")
+	require.Contains(t, task.Colorize(ColorOptions{Mode: Color16}, testColorizeOptions()...),
+		"+family \x1b[32m@home\x1b[0m ")
+}
+
+func TestTask_Colorize_states(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString(
+		"Overdue task due:2020-03-07\nToday task due:2020-03-08\nx 2020-03-07 Done task due:2020-03-01 @phone",
+	)
+	require.NoError(t, err)
+
+	colorOpts := ColorOptions{Mode: Color16}
+
+	require.Contains(t, tasklist[0].Colorize(colorOpts, testColorizeOptions()...), "\x1b[1;91mdue:2020-03-07\x1b[0m")
+	require.Contains(t, tasklist[1].Colorize(colorOpts, testColorizeOptions()...), "\x1b[1;93mdue:2020-03-08\x1b[0m")
+
+	// Completed tasks are dimmed and never overdue
+	require.Equal(t,
+		"\x1b[2mx\x1b[0m \x1b[2m2020-03-07\x1b[0m \x1b[2mDone task @phone\x1b[0m \x1b[2;35mdue:2020-03-01\x1b[0m",
+		tasklist[2].Colorize(colorOpts, testColorizeOptions()...))
+}
+
+func TestTask_Colorize_theme(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("Call Mom +family")
+	require.NoError(t, err)
+
+	task.Projects = append(task.Projects, "home")
+
+	theme := DefaultTheme()
+	theme.Segments[SegmentTodoText] = Style{Italic: true, Underline: true}
+	theme.Segments[SegmentProject] = Style{Foreground: RGBColor(255, 136, 0), Background: IndexedColor(236)}
+
+	for mode, expect := range map[ColorMode]string{
+		ColorTrueColor: "\x1b[3;4mCall Mom +family\x1b[0m \x1b[38;2;255;136;0;48;5;236m+home\x1b[0m",
+		Color256:       "\x1b[3;4mCall Mom +family\x1b[0m \x1b[38;5;208;48;5;236m+home\x1b[0m",
+		Color16:        "\x1b[3;4mCall Mom +family\x1b[0m \x1b[33;40m+home\x1b[0m",
+	} {
+		require.Equal(t, expect, task.Colorize(ColorOptions{Theme: theme, Mode: mode}, testColorizeOptions()...),
+			"mode: %d", mode)
+	}
+}
+
+func TestTask_Colorize_width(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("(A) 2020-03-01 Call Mom est:30 due:2020-03-09")
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		expect string
+		width  int
+	}{
+		{width: 0, expect: "(A) 2020-03-01 Call Mom est:30 due:2020-03-09"},
+		{width: 45, expect: "(A) 2020-03-01 Call Mom est:30 due:2020-03-09"},
+		{width: 44, expect: "(A) 2020-03-01 Call Mom est:30 …"},
+		{width: 32, expect: "(A) 2020-03-01 Call Mom est:30 …"},
+		{width: 31, expect: "(A) 2020-03-01 Call Mom …"},
+		{width: 5, expect: "(A) …"},
+		{width: 4, expect: "…"},
+		{width: 1, expect: "…"},
+	} {
+		actual := task.Colorize(ColorOptions{Mode: ColorNone, Width: test.width}, testColorizeOptions()...)
+		require.Equal(t, test.expect, actual, "width: %d", test.width)
+	}
+
+	// Wide characters take two columns
+	wide, err := ParseTask("日本語 due:2020-03-09")
+	require.NoError(t, err)
+	require.Equal(t, "日本語 …", wide.Colorize(ColorOptions{Mode: ColorNone, Width: 20}, testColorizeOptions()...))
+
+	// Escape sequences do not count
+	require.Equal(t, "\x1b[1;33m(A)\x1b[0m …",
+		task.Colorize(ColorOptions{Mode: Color16, Width: 5}, testColorizeOptions()...))
+}
+
+func TestTaskList_WriteColorized(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString("# comment\n(A) Call Mom", WithKeepNonTaskLines(true))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, tasklist.WriteColorized(&buf,
+		ColorOptions{Mode: Color16}, testColorizeOptions(WithNewLine("\n"))...))
+	require.Equal(t, "# comment\n\x1b[1;33m(A)\x1b[0m Call Mom\n", buf.String())
+}
+
+func TestDetectColorMode(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		env    map[string]string
+		expect ColorMode
+	}{
+		{env: map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1"}, expect: ColorNone},
+		{env: map[string]string{"TERM": "dumb", "COLORTERM": "truecolor"}, expect: ColorNone},
+		{env: map[string]string{}, expect: ColorNone},
+		{env: map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, expect: ColorTrueColor},
+		{env: map[string]string{"COLORTERM": "24bit"}, expect: ColorTrueColor},
+		{env: map[string]string{"TERM": "xterm-256color", "NO_COLOR": ""}, expect: Color256},
+		{env: map[string]string{"TERM": "xterm"}, expect: Color16},
+	} {
+		actual := detectColorMode(func(key string) string { return test.env[key] })
+		require.Equal(t, test.expect, actual, "env: %v", test.env)
+	}
+}
+
+func TestColor_conversion(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, Color{}.IsZero())
+	require.Equal(t, "31", BasicColor(1).sgr(ColorTrueColor, 30))
+	require.Equal(t, "97", BasicColor(15).sgr(Color16, 30))
+	require.Equal(t, "41", IndexedColor(1).sgr(Color16, 40))
+	require.Equal(t, "38;5;196", IndexedColor(196).sgr(ColorTrueColor, 30))
+	require.Equal(t, "91", IndexedColor(196).sgr(Color16, 30))
+	require.Equal(t, "38;5;232", RGBColor(10, 10, 10).sgr(Color256, 30), "should be the nearest gray")
+	require.Equal(t, "38;5;231", RGBColor(255, 255, 255).sgr(Color256, 30))
+	require.Equal(t, "Plain", Style{}.Render("Plain", Color16))
+}
+
+func TestTask_Colorize_inline(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("Call @Mom about +Trip est:30 today")
+	require.NoError(t, err)
+
+	require.Equal(t,
+		"Call \x1b[32m@Mom\x1b[0m about \x1b[34m+Trip\x1b[0m \x1b[36mest:30\x1b[0m today",
+		task.Colorize(ColorOptions{Mode: Color16, Inline: true}, testColorizeOptions()...))
+}
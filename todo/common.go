@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -27,8 +28,11 @@ const (
 	PermReadWrite = 0o640
 	// PermReadWriteExec represents the permission bits for "rwx r-x r-x".
 	PermReadWriteExec = 0o755
-	// DateLayout is used for formatting time.Time into todo.txt date format and vice-versa.
+	// DateLayout is used for formatting Date into todo.txt date format and vice-versa.
 	DateLayout = "2006-01-02"
+
+	// timeOfDayPattern matches a time of day following a date.
+	timeOfDayPattern = `T\d{2}:\d{2}(?::\d{2})?`
 )
 
 // ----------------------------------------------------------------------------
@@ -39,6 +43,10 @@ const (
 // behaviour of todo.txt.
 // The todo.txt format does not define comments.
 //
+// Note that these variables are only used as the default values of Config. To
+// parse or write tasks with a different configuration per call, use the With*
+// options instead. See NewConfig.
+//
 //nolint:gochecknoglobals // global variable is intentional
 var (
 	// IgnoreComments is used to switch ignoring of comments (lines starting
@@ -53,27 +61,79 @@ var (
 )
 
 var (
-	// Match priority: '(A) ...' or 'x (A) ...' or 'x 2012-12-12 (A) ...'.
-	priorityRx = regexp.MustCompile(`^(x|x \d{4}-\d{2}-\d{2}|)\s*\(([A-Z])\)\s+`)
-	// Match created date:
-	//   '(A) 2012-12-12 ...' or 'x 2012-12-12 (A) 2012-12-12 ...'
-	// or
-	//   'x (A) 2012-12-12 ...' or 'x 2012-12-12 2012-12-12 ...' or '2012-12-12 ...'.
-	createdDateRx = regexp.MustCompile(
-		`^(\([A-Z]\)|x \d{4}-\d{2}-\d{2} \([A-Z]\)|x \([A-Z]\)|x \d{4}-\d{2}-\d{2}|)\s*(\d{4}-\d{2}-\d{2})\s+`,
-	)
 	// Match completed: 'x ...'.
 	completedRx = regexp.MustCompile(`^x\s+`)
-	// Match completed date: 'x 2012-12-12 ...'.
-	completedDateRx = regexp.MustCompile(`^x\s*(\d{4}-\d{2}-\d{2})\s+`)
-	// Match additional tags date: '... due:2012-12-12 ...'.
+	// Match additional tags: '... due:2012-12-12 ...'.
 	addonTagRx = regexp.MustCompile(`(^|\s+)([^:\s]+):([^:\s]+)`)
+	// dateRxCache caches the *dateRx per date layout and Config.DateTime. See
+	// dateRxOf.
+	dateRxCache sync.Map
 	// Match contexts: '@Context ...' or '... @Context ...'.
 	contextRx = regexp.MustCompile(`(^|\s+)@(\S+)`)
 	// Match projects: '+Project...' or '... +Project ...'.
 	projectRx = regexp.MustCompile(`(^|\s+)\+(\S+)`)
 )
 
+// ----------------------------------------------------------------------------
+//  Type: dateRx
+// ----------------------------------------------------------------------------
+
+// dateRx is the set of the regular expressions which depend on the date layout.
+type dateRx struct {
+	// Match priority: '(A) ...' or 'x (A) ...' or 'x 2012-12-12 (A) ...'.
+	priority *regexp.Regexp
+	// Match created date:
+	//   '(A) 2012-12-12 ...' or 'x 2012-12-12 (A) 2012-12-12 ...'
+	// or
+	//   'x (A) 2012-12-12 ...' or 'x 2012-12-12 2012-12-12 ...' or '2012-12-12 ...'.
+	createdDate *regexp.Regexp
+	// Match completed date: 'x 2012-12-12 ...'.
+	completedDate *regexp.Regexp
+	// Match additional tags: '... due:2012-12-12 ...'. With time of day, the
+	// dates such as 'due:2012-12-12T10:00' are matched as a whole.
+	addonTag *regexp.Regexp
+}
+
+// dateRxKey is the key of dateRxCache.
+type dateRxKey struct {
+	layout   string
+	dateTime bool
+}
+
+// dateRxOf returns the regular expressions for the given date layout. If
+// dateTime is 'true', the dates may have the time of day, such as
+// '2012-12-12T10:00' or '2012-12-12T10:00:30'.
+func dateRxOf(layout string, dateTime bool) *dateRx {
+	key := dateRxKey{layout: layout, dateTime: dateTime}
+
+	if cached, ok := dateRxCache.Load(key); ok {
+		return cached.(*dateRx) //nolint:forcetypeassert // only *dateRx is stored
+	}
+
+	datePattern := layoutPattern(layout)
+	dateTimePattern := datePattern
+	addonTag := addonTagRx
+
+	if dateTime {
+		dateTimePattern = datePattern + `(?:` + timeOfDayPattern + `)?`
+		addonTag = regexp.MustCompile(`(^|\s+)([^:\s]+):(` + datePattern + timeOfDayPattern + `|[^:\s]+)`)
+	}
+
+	rx := &dateRx{
+		priority: regexp.MustCompile(`^(x|x ` + dateTimePattern + `|)\s*\(([A-Z])\)\s+`),
+		createdDate: regexp.MustCompile(
+			`^(\([A-Z]\)|x ` + dateTimePattern + ` \([A-Z]\)|x \([A-Z]\)|x ` + dateTimePattern + `|)\s*(` +
+				dateTimePattern + `)\s+`,
+		),
+		completedDate: regexp.MustCompile(`^x\s*(` + dateTimePattern + `)\s+`),
+		addonTag:      addonTag,
+	}
+
+	cached, _ := dateRxCache.LoadOrStore(key, rx)
+
+	return cached.(*dateRx) //nolint:forcetypeassert // only *dateRx is stored
+}
+
 // ----------------------------------------------------------------------------
 //  Public functions
 // ----------------------------------------------------------------------------
@@ -83,14 +143,15 @@ var (
 // Using *os.File instead of a filename allows to also use os.Stdout.
 //
 // Note: Comments from original file will be omitted and not written to target *os.File,
-// if IgnoreComments is set to 'true'.
-func WriteToFile(tasklist *TaskList, file *os.File) error {
-	return tasklist.WriteToFile(file)
+// if IgnoreComments is set to 'true' and they were not loaded with the
+// WithKeepNonTaskLines(true) option.
+func WriteToFile(tasklist *TaskList, file *os.File, opts ...Option) error {
+	return tasklist.WriteToFile(file, opts...)
 }
 
 // WriteToPath writes a TaskList to the specified file (most likely called "todo.txt").
-func WriteToPath(tasklist *TaskList, filename string) error {
-	return tasklist.WriteToPath(filename)
+func WriteToPath(tasklist *TaskList, filename string, opts ...Option) error {
+	return tasklist.WriteToPath(filename, opts...)
 }
 
 // ----------------------------------------------------------------------------
@@ -161,41 +222,92 @@ func lessStrings(a, b []string) bool {
 	return lenA < lenB
 }
 
-func parseAdditionalTags(txtOrig string, task *Task) error {
-	matches := addonTagRx.FindAllStringSubmatch(txtOrig, -1)
-	tags := make(map[string]string, len(matches))
+// mergeValues returns the sorted values of both slices without duplicates.
+// It returns nil if both are empty.
+func mergeValues(values, more []string) []string {
+	merged := make([]string, 0, len(values)+len(more))
+	seen := make(map[string]bool, len(values)+len(more))
+
+	for _, value := range append(values, more...) {
+		if !seen[value] {
+			merged = append(merged, value)
+			seen[value] = true
+		}
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+
+	sort.Strings(merged)
+
+	return merged
+}
+
+func parseAdditionalTags(txtOrig string, task *Task, cfg *Config) error {
+	// The leading part, such as the dates, is already removed from the Todo
+	// text. So that a time of day in it is not taken as a tag.
+	body := task.Todo
+	bodyStart := len(txtOrig) - len(body)
+	matches := cfg.addonTagRx().FindAllStringSubmatchIndex(body, -1)
+	tags := make(Tags, 0, len(matches))
 
 	for _, match := range matches {
-		key, value := match[2], match[3]
+		for i := range match {
+			if match[i] >= 0 {
+				match[i] += bodyStart
+			}
+		}
+
+		key, value := txtOrig[match[4]:match[5]], txtOrig[match[6]:match[7]]
+
+		// threshold date is also a known addon tag. Other values, such as
+		// "t:tbd", are kept as additional tags.
+		if key == "t" {
+			if date, err := cfg.parseDate(value); err == nil {
+				task.ThresholdDate = date
+
+				continue
+			}
+		}
 
 		// due date is a known addon tag, it has its own struct field
 		if key == "due" {
-			date, err := parseTime(value)
+			date, err := cfg.parseDate(value)
 			if err != nil {
-				return errors.Wrap(err, "failed to parse time of due date")
+				return newParseError(errors.Wrap(err, "failed to parse time of due date"),
+					txtOrig, match[4], SegmentDueDate, txtOrig[match[4]:match[7]])
 			}
 
 			task.DueDate = date
 		} else if isNotEmpty(key) && isNotEmpty(value) {
-			// add other tags rather than due date to the map
-			tags[key] = value
+			// validate the value if a TagCodec is registered for the key
+			if err := validateTag(key, value); err != nil {
+				return newParseError(err, txtOrig, match[4], SegmentTag, txtOrig[match[4]:match[7]])
+			}
+
+			// add other tags rather than due date to the list
+			tags.Add(key, value)
 		}
 	}
 
 	task.AdditionalTags = tags
-	task.Todo = addonTagRx.ReplaceAllString(task.Todo, emptyStr) // Remove from Todo text
+	task.Todo = cfg.addonTagRx().ReplaceAllString(task.Todo, emptyStr) // Remove from Todo text
 
 	return nil
 }
 
-func parseCompleted(txtOrig string, task *Task) error {
+func parseCompleted(txtOrig string, task *Task, cfg *Config) error {
 	task.Completed = true
 
 	// Check for completed date
-	if completedDateRx.MatchString(txtOrig) {
-		date, err := parseTime(completedDateRx.FindStringSubmatch(txtOrig)[1])
+	if match := cfg.completedDateRx().FindStringSubmatchIndex(txtOrig); match != nil {
+		value := txtOrig[match[2]:match[3]]
+
+		date, err := cfg.parseDate(value)
 		if err != nil {
-			return errors.Wrap(err, "failed to parse completed date")
+			return newParseError(errors.Wrap(err, "failed to parse completed date"),
+				txtOrig, match[2], SegmentCompletedDate, value)
 		}
 
 		task.CompletedDate = date
@@ -203,42 +315,35 @@ func parseCompleted(txtOrig string, task *Task) error {
 
 	/* Remove from Todo text */
 	// Strip CompletedDate first, otherwise it wouldn't match anymore (^x date...)
-	task.Todo = completedDateRx.ReplaceAllString(task.Todo, emptyStr)
+	task.Todo = cfg.completedDateRx().ReplaceAllString(task.Todo, emptyStr)
 	// Strip 'x '
 	task.Todo = completedRx.ReplaceAllString(task.Todo, emptyStr)
 
 	return nil
 }
 
-func parseCreatedDate(txtOrig string, task *Task) error {
-	date, err := parseTime(createdDateRx.FindStringSubmatch(txtOrig)[2])
+func parseCreatedDate(txtOrig string, task *Task, cfg *Config) error {
+	match := cfg.createdDateRx().FindStringSubmatchIndex(txtOrig)
+	value := txtOrig[match[4]:match[5]]
+
+	date, err := cfg.parseDate(value)
 	if err != nil {
-		return errors.Wrap(err, "failed to parse time of created date")
+		return newParseError(errors.Wrap(err, "failed to parse time of created date"),
+			txtOrig, match[4], SegmentCreatedDate, value)
 	}
 
 	task.CreatedDate = date
-	task.Todo = createdDateRx.ReplaceAllString(task.Todo, emptyStr) // Remove from Todo text
+	task.Todo = cfg.createdDateRx().ReplaceAllString(task.Todo, emptyStr) // Remove from Todo text
 
 	return nil
 }
 
-func parsePriority(txtOrig string, task *Task) {
-	task.Priority = priorityRx.FindStringSubmatch(txtOrig)[2]
-	task.Todo = priorityRx.ReplaceAllString(task.Todo, emptyStr) // Remove from Todo text
-}
-
-// parseTime parses a string as a local time into a time.Time struct.
-func parseTime(s string) (time.Time, error) {
-	//nolint:gosmopolitan //
-	parsed, err := time.ParseInLocation(DateLayout, s, time.Local)
-	if err != nil {
-		return time.Time{}, errors.Wrap(err, "failed to parse time")
-	}
-
-	return parsed, nil
+func parsePriority(txtOrig string, task *Task, cfg *Config) {
+	task.Priority = cfg.priorityRx().FindStringSubmatch(txtOrig)[2]
+	task.Todo = cfg.priorityRx().ReplaceAllString(task.Todo, emptyStr) // Remove from Todo text
 }
 
-func sortByDate(asc bool, hasDate1, hasDate2 bool, date1, date2 time.Time) bool {
+func sortByDate(asc bool, hasDate1, hasDate2 bool, date1, date2 Date) bool {
 	// ASC
 	if asc {
 		if hasDate1 && hasDate2 {
@@ -3,7 +3,6 @@ package todo
 import (
 	"os"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -36,7 +35,7 @@ func TestRemoveCompletedPriority(t *testing.T) {
 	}
 
 	// Sets Task.Completed to 'true' if the task was not already completed.
-	// Also sets Task.CompletedDate to time.Now().
+	// Also sets Task.CompletedDate to today.
 	task.Complete()
 
 	// RemoveCompletedPriority as false
@@ -44,7 +43,7 @@ func TestRemoveCompletedPriority(t *testing.T) {
 		RemoveCompletedPriority = false
 
 		// Set completed date to an old date
-		task.CompletedDate = time.Date(2020, 11, 30, 0, 0, 0, 0, time.Local)
+		task.CompletedDate = NewDate(2020, 11, 30)
 
 		expectStr := "x 2020-11-30 (A) Hello World @Work"
 		actualStr := task.String()
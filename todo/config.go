@@ -0,0 +1,280 @@
+package todo
+
+import (
+	"regexp"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Config
+// ----------------------------------------------------------------------------
+
+// Config represents the configuration used to parse and write tasks.
+//
+// The package-level variables, such as IgnoreComments and RemoveCompletedPriority,
+// are only used as the default values. To parse or write tasks with a different
+// configuration, pass the With* options to the functions which accept them,
+// such as ParseTask, LoadFromFile, Task.StringWith and TaskList.WriteToFile.
+// It is safe to use different configurations concurrently.
+type Config struct {
+	// Clock provides the current time to get "today" in the time-dependent
+	// functions. The default is SystemClock.
+	Clock Clock
+	// Location is the time zone of "today" and of the time.Time values given
+	// to the filters. The dates of tasks are calendar dates without location,
+	// see Date. The default is time.Local.
+	Location *time.Location
+	// DateLayout is the layout used to find, parse and format the dates, such
+	// as "02.01.2006". It must not contain whitespaces nor ":" to be written in
+	// the task line. The default is DateLayout.
+	DateLayout string
+	// DateTime parses and writes the dates with time of day, such as
+	// "due:2020-03-08T14:00", in the due and threshold tags and in the completed
+	// and created dates. If 'false', only the dates without time of day are
+	// supported as before. See DateTimeLayout. The default is 'false'.
+	DateTime bool
+	// NewLine is the end of line characters used to write a TaskList. The
+	// default is NewLine.
+	NewLine string
+	// IgnoreComments skips the lines starting with "#" on loading. See the
+	// package-level IgnoreComments.
+	IgnoreComments bool
+	// KeepNonTaskLines keeps the comment and blank lines on loading as non-task
+	// entries (see Task.NonTask) at their positions, so that they are written
+	// back as they were. Note that comment lines are only treated as such if
+	// IgnoreComments is 'true'. The default is 'false'.
+	KeepNonTaskLines bool
+	// ParseTags parses the "key:value" words as additional tags. If 'false',
+	// such words are kept in the Todo text as is. The default is 'true'.
+	ParseTags bool
+	// PreserveOriginal writes the parsed tasks losslessly. An unmodified task
+	// is written as its Original text as is, and an edited task only changes
+	// the edited parts, keeping the rest of the line in its original order.
+	// The default is 'false'.
+	PreserveOriginal bool
+	// RemoveCompletedPriority discards the priority of the completed tasks on
+	// writing. See the package-level RemoveCompletedPriority.
+	RemoveCompletedPriority bool
+}
+
+// Option is a function type to set a Config field. It is used as a "Functional
+// Options Pattern" style, such as:
+//
+//	task, err := todo.ParseTask(text, todo.WithParseTags(false))
+type Option func(*Config)
+
+// ----------------------------------------------------------------------------
+//  Constructor
+// ----------------------------------------------------------------------------
+
+// NewConfig returns a new Config with the default values, then applies the
+// given options in order.
+//
+// The default values are taken from the package-level variables at the time of
+// the call.
+func NewConfig(opts ...Option) *Config {
+	cfg := &Config{
+		Clock:                   SystemClock,
+		Location:                time.Local,
+		DateLayout:              DateLayout,
+		DateTime:                false,
+		NewLine:                 NewLine,
+		IgnoreComments:          IgnoreComments,
+		KeepNonTaskLines:        false,
+		ParseTags:               true,
+		PreserveOriginal:        false,
+		RemoveCompletedPriority: RemoveCompletedPriority,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// ----------------------------------------------------------------------------
+//  Options
+// ----------------------------------------------------------------------------
+
+// WithClock returns an Option to set the Clock which provides the current time.
+// Such as:
+//
+//	filter := todo.FilterDueWithin(3, todo.WithClock(todo.FixedClock(now)))
+func WithClock(clock Clock) Option {
+	return func(cfg *Config) {
+		cfg.Clock = clock
+	}
+}
+
+// WithConfig returns an Option to replace the whole configuration with the
+// given one. The zero values of Clock, Location, DateLayout and NewLine are
+// replaced with the defaults, so that a partial Config such as
+// Config{ParseTags: true} is still usable.
+func WithConfig(config Config) Option {
+	return func(cfg *Config) {
+		*cfg = config
+
+		if cfg.Clock == nil {
+			cfg.Clock = SystemClock
+		}
+
+		if cfg.Location == nil {
+			cfg.Location = time.Local
+		}
+
+		if isEmpty(cfg.DateLayout) {
+			cfg.DateLayout = DateLayout
+		}
+
+		if isEmpty(cfg.NewLine) {
+			cfg.NewLine = NewLine
+		}
+	}
+}
+
+// WithDateLayout returns an Option to set the layout of the dates. The dates
+// in the task line are found, parsed and written in this layout. Such as:
+//
+//	task, err := todo.ParseTask("08.03.2020 Call Mom due:09.03.2020", todo.WithDateLayout("02.01.2006"))
+func WithDateLayout(layout string) Option {
+	return func(cfg *Config) {
+		cfg.DateLayout = layout
+	}
+}
+
+// WithDateTime returns an Option to set whether to parse and write the dates
+// with time of day. Such as:
+//
+//	task, err := todo.ParseTask("Call Bob due:2020-03-08T14:00", todo.WithDateTime(true))
+func WithDateTime(enable bool) Option {
+	return func(cfg *Config) {
+		cfg.DateTime = enable
+	}
+}
+
+// WithIgnoreComments returns an Option to set whether to skip the comment lines.
+func WithIgnoreComments(ignore bool) Option {
+	return func(cfg *Config) {
+		cfg.IgnoreComments = ignore
+	}
+}
+
+// WithKeepNonTaskLines returns an Option to set whether to keep the comment and
+// blank lines as non-task entries.
+func WithKeepNonTaskLines(keep bool) Option {
+	return func(cfg *Config) {
+		cfg.KeepNonTaskLines = keep
+	}
+}
+
+// WithLocation returns an Option to set the time zone of "today". Nil is the
+// same as time.Local.
+//
+//	// Load the todo.txt of a user living in Tokyo on a UTC server
+//	tasklist, err := todo.LoadFromPath("todo.txt", todo.WithLocation(tokyo))
+func WithLocation(loc *time.Location) Option {
+	return func(cfg *Config) {
+		if loc == nil {
+			loc = time.Local
+		}
+
+		cfg.Location = loc
+	}
+}
+
+// WithNewLine returns an Option to set the end of line characters.
+func WithNewLine(newLine string) Option {
+	return func(cfg *Config) {
+		cfg.NewLine = newLine
+	}
+}
+
+// WithParseTags returns an Option to set whether to parse the "key:value" words
+// as additional tags.
+func WithParseTags(parse bool) Option {
+	return func(cfg *Config) {
+		cfg.ParseTags = parse
+	}
+}
+
+// WithPreserveOriginal returns an Option to set whether to write the parsed
+// tasks in the order of the original line.
+func WithPreserveOriginal(preserve bool) Option {
+	return func(cfg *Config) {
+		cfg.PreserveOriginal = preserve
+	}
+}
+
+// WithRemoveCompletedPriority returns an Option to set whether to discard the
+// priority of the completed tasks on writing.
+func WithRemoveCompletedPriority(remove bool) Option {
+	return func(cfg *Config) {
+		cfg.RemoveCompletedPriority = remove
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// addonTagRx returns the regular expression of the additional tags.
+func (cfg *Config) addonTagRx() *regexp.Regexp {
+	return cfg.dateRx().addonTag
+}
+
+// completedDateRx returns the regular expression of the completed date.
+func (cfg *Config) completedDateRx() *regexp.Regexp {
+	return cfg.dateRx().completedDate
+}
+
+// createdDateRx returns the regular expression of the created date.
+func (cfg *Config) createdDateRx() *regexp.Regexp {
+	return cfg.dateRx().createdDate
+}
+
+// dateRx returns the regular expressions for the configured date layout.
+func (cfg *Config) dateRx() *dateRx {
+	return dateRxOf(cfg.DateLayout, cfg.DateTime)
+}
+
+// formatDate returns the date in the configured layout. The time of day is
+// only written if DateTime is enabled.
+func (cfg *Config) formatDate(date Date) string {
+	return date.formatLayout(cfg.DateLayout, cfg.DateTime)
+}
+
+// isComment returns true if the given trimmed line is a comment line to skip.
+func (cfg *Config) isComment(text string) bool {
+	return cfg.IgnoreComments && len(text) > 0 && text[0] == '#'
+}
+
+// now returns the current date in the configured location. It has the time of
+// day if DateTime is enabled.
+func (cfg *Config) now() Date {
+	if cfg.DateTime {
+		return DateTimeOf(cfg.Clock.Now().In(cfg.Location))
+	}
+
+	return cfg.today()
+}
+
+// parseDate parses the date in the configured layout. The time of day is only
+// accepted if DateTime is enabled.
+func (cfg *Config) parseDate(value string) (Date, error) {
+	if cfg.DateTime {
+		return parseDateTimeLayout(cfg.DateLayout, value)
+	}
+
+	return parseDateLayout(cfg.DateLayout, value)
+}
+
+// priorityRx returns the regular expression of the priority.
+func (cfg *Config) priorityRx() *regexp.Regexp {
+	return cfg.dateRx().priority
+}
+
+// today returns the date of today in the configured location.
+func (cfg *Config) today() Date {
+	return DateOf(cfg.Clock.Now().In(cfg.Location))
+}
@@ -0,0 +1,332 @@
+package todo
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest // do not parallel to avoid race conditions
+func TestNewConfig_default_values(t *testing.T) {
+	cfg := NewConfig()
+
+	require.Equal(t, DateLayout, cfg.DateLayout)
+	require.Equal(t, NewLine, cfg.NewLine)
+	require.Equal(t, IgnoreComments, cfg.IgnoreComments)
+	require.False(t, cfg.KeepNonTaskLines, "non-task lines should be skipped by default")
+	require.False(t, cfg.PreserveOriginal, "lossless mode should be opt-in")
+	require.Equal(t, RemoveCompletedPriority, cfg.RemoveCompletedPriority)
+	require.True(t, cfg.ParseTags, "tags should be parsed by default")
+
+	// Options are applied in order
+	cfg = NewConfig(WithIgnoreComments(false), WithConfig(*cfg), WithNewLine("\r\n"))
+
+	require.Equal(t, IgnoreComments, cfg.IgnoreComments, "WithConfig should replace the previous options")
+	require.Equal(t, "\r\n", cfg.NewLine, "options after WithConfig should be applied")
+}
+
+func TestWithParseTags(t *testing.T) {
+	t.Parallel()
+
+	const text = "(A) Meet Bob at 10:30 @Office see:https://example.com due:2020-11-15"
+
+	task, err := ParseTask(text, WithParseTags(false))
+	require.NoError(t, err, "failed to parse task")
+
+	require.False(t, task.HasAdditionalTags(), "tags should not be parsed")
+	require.False(t, task.HasDueDate(), "due date should not be parsed")
+	require.Equal(t, "Meet Bob at 10:30 @Office see:https://example.com due:2020-11-15", task.Todo)
+	require.Equal(t, text, task.StringWith(WithPreserveOriginal(true)))
+	require.Equal(t, text, task.String())
+}
+
+func TestWithDateLayout(t *testing.T) {
+	t.Parallel()
+
+	// Day and month are swapped
+	const layout = "2006-02-01"
+
+	task, err := ParseTask("x 2020-30-11 (A) 2020-01-11 Call Mom due:2020-31-12", WithDateLayout(layout))
+	require.NoError(t, err, "failed to parse task")
+
+	require.Equal(t, "2020-12-31", task.DueDate.Format(DateLayout))
+	require.Equal(t, "2020-11-30", task.CompletedDate.Format(DateLayout))
+	require.Equal(t, "2020-11-01", task.CreatedDate.Format(DateLayout))
+
+	require.Equal(t, "x 2020-30-11 (A) 2020-01-11 Call Mom due:2020-31-12",
+		task.StringWith(WithDateLayout(layout), WithRemoveCompletedPriority(false)))
+	require.Equal(t, "x 2020-11-30 2020-11-01 Call Mom due:2020-12-31",
+		task.StringWith(WithRemoveCompletedPriority(true)))
+
+	segs := task.Segments(WithDateLayout(layout))
+	require.Equal(t, "2020-30-11", segs[1].Display)
+
+	// Default layout fails to parse
+	_, err = ParseTask("Call Mom due:2020-31-12")
+	require.Error(t, err, "invalid date in the default layout should be an error")
+}
+
+func TestWithDateLayout_find_dates(t *testing.T) {
+	t.Parallel()
+
+	const (
+		layout = "02.01.2006"
+		text   = "x 09.03.2020 (A) 08.03.2020 Call Mom due:10.03.2020"
+	)
+
+	task, err := ParseTask(text, WithDateLayout(layout), WithRemoveCompletedPriority(false))
+	require.NoError(t, err)
+
+	require.True(t, task.Completed)
+	require.Equal(t, "A", task.Priority)
+	require.Equal(t, "2020-03-09", task.CompletedDate.Format(DateLayout))
+	require.Equal(t, "2020-03-08", task.CreatedDate.Format(DateLayout))
+	require.Equal(t, "2020-03-10", task.DueDate.Format(DateLayout))
+	require.Equal(t, "Call Mom", task.Todo, "dates in the layout should not be left in the todo text")
+	require.Equal(t, text, task.StringWith(WithDateLayout(layout), WithRemoveCompletedPriority(false)))
+
+	// With time of day
+	task, err = ParseTask("08.03.2020T09:30 Call Mom due:10.03.2020T14:00",
+		WithDateLayout(layout), WithDateTime(true))
+	require.NoError(t, err)
+
+	require.Equal(t, NewDate(2020, 3, 8).WithTime(9, 30, 0), task.CreatedDate)
+	require.Equal(t, NewDate(2020, 3, 10).WithTime(14, 0, 0), task.DueDate)
+	require.Equal(t, "Call Mom", task.Todo)
+
+	// The default layout is not a date in the given layout
+	task, err = ParseTask("2020-03-08 Call Mom", WithDateLayout(layout))
+	require.NoError(t, err)
+	require.False(t, task.HasCreatedDate())
+	require.Equal(t, "2020-03-08 Call Mom", task.Todo)
+}
+
+func TestLayoutPattern(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		layout string
+		expect string
+	}{
+		{layout: DateLayout, expect: `\d{4}-\d{2}-\d{2}`},
+		{layout: "02.01.2006", expect: `\d{2}\.\d{2}\.\d{4}`},
+		{layout: "2/1/06", expect: `\d{1,2}/\d{1,2}/\d{2}`},
+		{layout: "2006-Jan-02", expect: `\d{4}-[A-Za-z]{3}-\d{2}`},
+		{layout: "2006.002", expect: `\d{4}\.\d{3}`},
+	} {
+		require.Equal(t, test.expect, layoutPattern(test.layout), "layout: %s", test.layout)
+	}
+}
+
+func TestWithConfig_zero_values(t *testing.T) {
+	t.Parallel()
+
+	cfg := NewConfig(WithConfig(Config{ParseTags: true}))
+	require.NotNil(t, cfg.Clock)
+	require.Equal(t, time.Local, cfg.Location)
+	require.Equal(t, DateLayout, cfg.DateLayout)
+	require.Equal(t, NewLine, cfg.NewLine)
+
+	task, err := ParseTask("(A) 2020-03-01 Call Mom due:2020-03-09", WithConfig(Config{ParseTags: true}))
+	require.NoError(t, err)
+	require.Equal(t, NewDate(2020, 3, 9), task.DueDate)
+	require.Equal(t, "(A) 2020-03-01 Call Mom due:2020-03-09", task.StringWith(WithConfig(Config{})))
+
+	tasklist := TaskList{*task}
+	require.Equal(t, "(A) 2020-03-01 Call Mom due:2020-03-09"+NewLine, tasklist.StringWith(WithConfig(Config{})))
+}
+
+func TestLoadFromString_with_options(t *testing.T) {
+	t.Parallel()
+
+	const text = "# Tenant A\n(A) Call Mom @Phone\n\nx (B) Pick up milk @GroceryStore\n"
+
+	// Comments are parsed as tasks
+	tasklist, err := LoadFromString(text, WithIgnoreComments(false))
+	require.NoError(t, err, "failed to load tasks")
+	require.Len(t, tasklist, 3)
+	require.Equal(t, "# Tenant A", tasklist[0].Todo)
+
+	// Comments and blank lines are kept as is
+	tasklist, err = LoadFromString(text, WithKeepNonTaskLines(true))
+	require.NoError(t, err, "failed to load tasks")
+	require.Len(t, tasklist, 4)
+	require.Equal(t, 2, tasklist.Count())
+	require.Equal(t, text, tasklist.StringWith(WithNewLine("\n"), WithRemoveCompletedPriority(false)))
+	require.Equal(t, "# Tenant A\r\n(A) Call Mom @Phone\r\n\r\nx Pick up milk @GroceryStore\r\n",
+		tasklist.StringWith(WithNewLine("\r\n"), WithRemoveCompletedPriority(true)))
+}
+
+func TestTaskList_WriteToPath_with_options(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString("(A) Call Mom @Phone due:2020-11-15\n")
+	require.NoError(t, err, "failed to load tasks")
+
+	pathFileOutput := testGetPathFileTemp(t, testOutput)
+	require.NoError(t, WriteToPath(&tasklist, pathFileOutput, WithNewLine("\r\n"), WithDateLayout("2006-02-01")))
+
+	rawOutput, err := os.ReadFile(pathFileOutput)
+	require.NoError(t, err, "failed to read saved tasklist")
+	require.Equal(t, "(A) Call Mom @Phone due:2020-15-11\r\n", string(rawOutput))
+}
+
+func TestConfig_concurrent_use(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("x 2020-11-30 (A) Call Mom @Phone")
+	require.NoError(t, err, "failed to parse task")
+
+	var waitGroup sync.WaitGroup
+
+	for range 10 {
+		waitGroup.Add(2)
+
+		go func() {
+			defer waitGroup.Done()
+
+			require.Equal(t, "x 2020-11-30 Call Mom @Phone", task.StringWith(WithRemoveCompletedPriority(true)))
+		}()
+
+		go func() {
+			defer waitGroup.Done()
+
+			require.Equal(t, "x 2020-11-30 (A) Call Mom @Phone", task.StringWith(WithRemoveCompletedPriority(false)))
+		}()
+	}
+
+	waitGroup.Wait()
+}
+
+func TestWithClock_time_dependent_functions(t *testing.T) {
+	t.Parallel()
+
+	clock := WithClock(FixedClock(time.Date(2020, 3, 8, 12, 0, 0, 0, time.Local)))
+
+	task, err := ParseTask("Write report due:2020-03-08")
+	require.NoError(t, err)
+
+	require.True(t, task.IsDueToday(clock))
+	require.False(t, task.IsOverdue(clock))
+	require.True(t, FilterDueWithin(0, clock)(*task))
+
+	task.Complete(clock)
+	require.Equal(t, "2020-03-08", task.CompletedDate.Format(DateLayout))
+	require.Equal(t, "2020-03-08", NewTask(clock).CreatedDate.Format(DateLayout))
+}
+
+func TestWithLocation(t *testing.T) {
+	t.Parallel()
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	newYork := time.FixedZone("EST", -5*60*60)
+
+	tasklist, err := LoadFromString(
+		"x 2020-03-09 2020-03-01 Send report due:2020-03-08\n"+
+			"2020-03-02 Write report t:2020-03-07 due:2020-03-09",
+		WithLocation(tokyo),
+	)
+	require.NoError(t, err)
+
+	task := tasklist[0]
+	require.Equal(t, NewDate(2020, 3, 8), task.DueDate, "dates should not depend on the location")
+
+	// The dates of times in other locations are the dates in their locations
+	task.DueDate = DateOf(time.Date(2020, 3, 8, 23, 0, 0, 0, newYork))
+	task.CreatedDate = DateOf(time.Date(2020, 3, 1, 0, 30, 0, 0, tokyo))
+	require.Equal(t, "x 2020-03-09 2020-03-01 Send report due:2020-03-08", task.String())
+
+	require.NoError(t, tasklist.Sort(SortDueDateDesc))
+	require.Equal(t, "Write report", tasklist[0].Todo)
+
+	// "Today" in Tokyo while it is still the day before in UTC
+	now := time.Date(2020, 3, 8, 20, 0, 0, 0, time.UTC)
+	require.True(t, task.IsOverdue(WithClock(FixedClock(now)), WithLocation(tokyo)))
+	require.True(t, task.IsDueToday(WithClock(FixedClock(now)), WithLocation(time.UTC)))
+
+	require.Equal(t, time.Local, NewConfig(WithLocation(nil)).Location)
+}
+
+func TestWithDateTime(t *testing.T) {
+	t.Parallel()
+
+	const text = "x 2020-03-08T15:30 (A) 2020-03-01T09:00 Call Bob t:2020-03-07T12:00 due:2020-03-08T14:00"
+
+	// Date-only by default, as before
+	_, err := ParseTask(text)
+	require.Error(t, err)
+
+	task, err := ParseTask(text, WithDateTime(true))
+	require.NoError(t, err)
+	require.Equal(t, NewDate(2020, 3, 8).WithTime(15, 30, 0), task.CompletedDate)
+	require.Equal(t, NewDate(2020, 3, 1).WithTime(9, 0, 0), task.CreatedDate)
+	require.Equal(t, NewDate(2020, 3, 7).WithTime(12, 0, 0), task.ThresholdDate)
+	require.Equal(t, NewDate(2020, 3, 8).WithTime(14, 0, 0), task.DueDate)
+	require.Equal(t, "A", task.Priority)
+	require.Equal(t, "Call Bob", task.Todo)
+
+	require.Equal(t,
+		"x 2020-03-08T15:30 (A) 2020-03-01T09:00 Call Bob t:2020-03-07T12:00 due:2020-03-08T14:00",
+		task.StringWith(WithDateTime(true)))
+	require.Equal(t,
+		"x 2020-03-08 (A) 2020-03-01 Call Bob t:2020-03-07 due:2020-03-08",
+		task.String(), "time of day should only be written if enabled")
+
+	// Lossless serialization keeps the order and updates the edited value
+	preserved, err := ParseTask(text, WithDateTime(true), WithPreserveOriginal(true))
+	require.NoError(t, err)
+
+	preserved.DueDate = preserved.DueDate.WithTime(16, 0, 0)
+	require.Equal(t,
+		"x 2020-03-08T15:30 (A) 2020-03-01T09:00 Call Bob t:2020-03-07T12:00 due:2020-03-08T16:00",
+		preserved.StringWith(WithDateTime(true), WithPreserveOriginal(true)))
+
+	// Date-only values behave exactly as before
+	dateOnly, err := ParseTask("2020-03-01 Call Bob due:2020-03-08", WithDateTime(true))
+	require.NoError(t, err)
+	require.False(t, dateOnly.DueDate.HasTime())
+	require.Equal(t, "2020-03-01 Call Bob due:2020-03-08", dateOnly.StringWith(WithDateTime(true)))
+
+	// Overdue once the time of day has passed
+	before := WithClock(FixedClock(time.Date(2020, 3, 8, 13, 59, 0, 0, time.UTC)))
+	after := WithClock(FixedClock(time.Date(2020, 3, 8, 14, 1, 0, 0, time.UTC)))
+	utc := WithLocation(time.UTC)
+
+	require.False(t, task.IsOverdue(before, utc))
+	require.True(t, task.IsOverdue(after, utc))
+	require.True(t, task.IsDueToday(after, utc))
+	require.False(t, dateOnly.IsOverdue(after, utc))
+	require.Equal(t, time.Minute, task.Due(before, utc))
+
+	// Completing with time of day
+	open, err := ParseTask("Call Bob", WithDateTime(true))
+	require.NoError(t, err)
+
+	open.Complete(after, utc, WithDateTime(true))
+	require.Equal(t, NewDate(2020, 3, 8).WithTime(14, 1, 0), open.CompletedDate)
+
+	// Sorting respects the time of day
+	tasklist, err := LoadFromString(
+		"Call Bob due:2020-03-08T14:00\nCall Alice due:2020-03-08T09:00\nCall Carol due:2020-03-08",
+		WithDateTime(true),
+	)
+	require.NoError(t, err)
+	require.NoError(t, tasklist.Sort(SortDueDateAsc))
+	require.Equal(t, "Call Carol", tasklist[0].Todo)
+	require.Equal(t, "Call Alice", tasklist[1].Todo)
+	require.Equal(t, "Call Bob", tasklist[2].Todo)
+}
+
+func TestWithClock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 3, 8, 23, 30, 0, 0, time.UTC)
+	cfg := NewConfig(WithClock(FixedClock(now)), WithLocation(time.FixedZone("JST", 9*60*60)))
+
+	require.Equal(t, now, cfg.Clock.Now())
+	require.Equal(t, "2020-03-09", cfg.today().Format(DateLayout), "today should be in the location")
+	require.Equal(t, time.Local, NewConfig().Location, "the default location should be time.Local")
+}
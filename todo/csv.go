@@ -0,0 +1,503 @@
+package todo
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: CSVColumn
+// ----------------------------------------------------------------------------
+
+// CSVColumn represents a column of CSV to import and export tasks. The value is
+// the name of the column in the header row.
+type CSVColumn string
+
+// Columns of CSV. Use CSVColumnTag for the columns of the additional tags.
+const (
+	CSVColumnID            CSVColumn = "id"
+	CSVColumnCompleted     CSVColumn = "completed"
+	CSVColumnPriority      CSVColumn = "priority"
+	CSVColumnCompletedDate CSVColumn = "completed_date"
+	CSVColumnCreatedDate   CSVColumn = "created_date"
+	CSVColumnDueDate       CSVColumn = "due_date"
+	CSVColumnThresholdDate CSVColumn = "threshold_date"
+	CSVColumnTodo          CSVColumn = "todo"
+	CSVColumnContexts      CSVColumn = "contexts"
+	CSVColumnProjects      CSVColumn = "projects"
+
+	// csvTagPrefix is the prefix of the columns of the additional tags.
+	csvTagPrefix = "tag:"
+)
+
+// CSVColumnTag returns the column of the additional tag of the given key. The
+// name of the column is the key prefixed with "tag:". Such as "tag:est".
+func CSVColumnTag(key string) CSVColumn {
+	return CSVColumn(csvTagPrefix + key)
+}
+
+// DefaultCSVColumns returns the default columns to export to CSV, which are all
+// the columns but the additional tags.
+func DefaultCSVColumns() []CSVColumn {
+	return []CSVColumn{
+		CSVColumnID,
+		CSVColumnCompleted,
+		CSVColumnPriority,
+		CSVColumnCompletedDate,
+		CSVColumnCreatedDate,
+		CSVColumnDueDate,
+		CSVColumnThresholdDate,
+		CSVColumnTodo,
+		CSVColumnContexts,
+		CSVColumnProjects,
+	}
+}
+
+// TagKey returns the key of the additional tag if the column is of a tag.
+func (column CSVColumn) TagKey() (string, bool) {
+	return strings.CutPrefix(string(column), csvTagPrefix)
+}
+
+// isValid returns true if the column is known or of a tag. The "due" and "t"
+// tags are not valid since they have their own columns.
+func (column CSVColumn) isValid() bool {
+	if key, ok := column.TagKey(); ok {
+		return isNotEmpty(key) && key != "due" && key != "t" && !strings.ContainsAny(key, ":"+whitespaces)
+	}
+
+	for _, known := range DefaultCSVColumns() {
+		if column == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ----------------------------------------------------------------------------
+//  Type: CSVRowError
+// ----------------------------------------------------------------------------
+
+// CSVRowError is the error of a row on importing CSV.
+type CSVRowError struct {
+	Err    error     // Err is the underlying error.
+	Column CSVColumn // Column of the error. Empty if the error is of the whole row.
+	Row    int       // Row number of the error, where the header row is 1.
+}
+
+// Error returns the error message with the row and column of the error.
+func (e *CSVRowError) Error() string {
+	if isEmpty(string(e.Column)) {
+		return "row " + strconv.Itoa(e.Row) + ": " + e.Err.Error()
+	}
+
+	return "row " + strconv.Itoa(e.Row) + ", column " + strconv.Quote(string(e.Column)) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *CSVRowError) Unwrap() error {
+	return e.Err
+}
+
+// ----------------------------------------------------------------------------
+//  Type: CSVErrors
+// ----------------------------------------------------------------------------
+
+// CSVErrors is the list of the row errors on importing CSV. See LoadFromCSV.
+type CSVErrors []*CSVRowError
+
+// Error returns the error messages of the rows, one per line.
+func (e CSVErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+
+	for _, rowErr := range e {
+		msgs = append(msgs, rowErr.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// ----------------------------------------------------------------------------
+//  Type: CSVOptions
+// ----------------------------------------------------------------------------
+
+// CSVOptions represents the options of importing and exporting CSV. The zero
+// value is the default, which is the comma separated DefaultCSVColumns.
+//
+// Note that the cells are written as they are by default. A spreadsheet
+// application may run a cell starting with "=", "+", "-" or "@" as a formula,
+// such as the todo text "=HYPERLINK(...)". Set EscapeFormulas to export the
+// CSV of untrusted tasks to such applications.
+type CSVOptions struct {
+	// Columns are the columns to write on exporting. The default is
+	// DefaultCSVColumns. On importing, the columns are taken from the header
+	// row instead.
+	Columns []CSVColumn
+	// Comma is the field delimiter. Such as '\t' for TSV. The default is ','.
+	Comma rune
+	// EscapeFormulas prefixes the cells starting with "=", "+", "-", "@", a tab
+	// or a carriage return with a single quote on exporting, so that they are
+	// not run as formulas. The quote is removed again on importing.
+	EscapeFormulas bool
+}
+
+// columns returns the columns to write.
+func (o CSVOptions) columns() []CSVColumn {
+	if len(o.Columns) == 0 {
+		return DefaultCSVColumns()
+	}
+
+	return o.Columns
+}
+
+// comma returns the field delimiter.
+func (o CSVOptions) comma() rune {
+	if o.Comma == 0 {
+		return ','
+	}
+
+	return o.Comma
+}
+
+// escapeCell returns the cell to write, which is escaped if it may be run as a
+// formula and EscapeFormulas is set.
+func (o CSVOptions) escapeCell(cell string) string {
+	if o.EscapeFormulas && isCSVFormula(cell) {
+		return "'" + cell
+	}
+
+	return cell
+}
+
+// unescapeCell returns the cell read, removing the quote of escapeCell.
+func (o CSVOptions) unescapeCell(cell string) string {
+	if unquoted, found := strings.CutPrefix(cell, "'"); o.EscapeFormulas && found && isCSVFormula(unquoted) {
+		return unquoted
+	}
+
+	return cell
+}
+
+// ----------------------------------------------------------------------------
+//  Public functions
+// ----------------------------------------------------------------------------
+
+// LoadFromCSV reads the tasks from CSV, such as the one written by
+// TaskList.WriteToCSV. Use the CSVOptions{Comma: '\t'} to read TSV.
+//
+// The first row must be the header row of the column names, which decides the
+// columns of the rows. See CSVColumn. The multiple values in a cell, such as
+// the contexts, are separated by whitespaces. The contexts and projects in the
+// todo text are kept as well.
+//
+// The invalid rows are skipped, and the valid ones are returned along with the
+// error of CSVErrors, which tells the row and column of each error. The IDs of
+// the tasks are taken from the "id" column. The tasks without ID are numbered in
+// order after the largest ID of the others, or from 1 if none.
+//
+// The cells are taken as they are. Such as "due:2020-01-05" in the "todo" column
+// is a text, not a due date. Use the "due_date" column instead.
+//
+// The options are used to parse the dates. See Config.
+func LoadFromCSV(reader io.Reader, csvOpts CSVOptions, opts ...Option) (TaskList, error) {
+	cfg := NewConfig(opts...)
+
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = csvOpts.comma()
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the header row of CSV")
+	}
+
+	columns := make([]CSVColumn, 0, len(header))
+
+	for _, name := range header {
+		column := CSVColumn(strings.TrimSpace(name))
+		if !column.isValid() {
+			return nil, &CSVRowError{Row: 1, Column: column, Err: errors.New("unknown column")}
+		}
+
+		columns = append(columns, column)
+	}
+
+	tasklist := NewTaskList()
+
+	var rowErrs CSVErrors
+
+	for row := 2; ; row++ {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, errors.Wrap(err, "failed to read CSV")
+			}
+
+			rowErrs = append(rowErrs, &CSVRowError{Row: row, Column: emptyStr, Err: parseErr.Err})
+
+			continue
+		}
+
+		for i := range record {
+			record[i] = csvOpts.unescapeCell(record[i])
+		}
+
+		task, rowErr := csvRecordToTask(columns, record, cfg)
+		if rowErr != nil {
+			rowErr.Row = row
+			rowErrs = append(rowErrs, rowErr)
+
+			continue
+		}
+
+		tasklist = append(tasklist, *task)
+	}
+
+	// Number the tasks without ID after the largest one, to avoid duplicates
+	maxID := 0
+
+	for i := range tasklist {
+		maxID = max(maxID, tasklist[i].ID)
+	}
+
+	for i := range tasklist {
+		if tasklist[i].ID == 0 {
+			maxID++
+			tasklist[i].ID = maxID
+		}
+	}
+
+	if len(rowErrs) > 0 {
+		return tasklist, rowErrs
+	}
+
+	return tasklist, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of TaskList
+// ----------------------------------------------------------------------------
+
+// WriteToCSV writes the tasks to CSV with the header row. Use the CSVOptions to
+// choose the columns and the delimiter, such as:
+//
+//	err := tasklist.WriteToCSV(os.Stdout, todo.CSVOptions{
+//	    Columns: []todo.CSVColumn{todo.CSVColumnTodo, todo.CSVColumnDueDate, todo.CSVColumnTag("est")},
+//	    Comma:   '\t', // TSV
+//	})
+//
+// The multiple values in a cell, such as the contexts, are separated by a
+// space. Non-task entries are not written. The options are used to format the
+// dates. See Config.
+func (tasklist *TaskList) WriteToCSV(writer io.Writer, csvOpts CSVOptions, opts ...Option) error {
+	cfg := NewConfig(opts...)
+	columns := csvOpts.columns()
+
+	for _, column := range columns {
+		if !column.isValid() {
+			return errors.Errorf("unknown CSV column %q", column)
+		}
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Comma = csvOpts.comma()
+
+	header := make([]string, 0, len(columns))
+
+	for _, column := range columns {
+		header = append(header, string(column))
+	}
+
+	if err := csvWriter.Write(header); err != nil {
+		return errors.Wrap(err, "failed to write the header row of CSV")
+	}
+
+	for i := range *tasklist {
+		task := &(*tasklist)[i]
+		if task.NonTask {
+			continue
+		}
+
+		record := make([]string, 0, len(columns))
+
+		for _, column := range columns {
+			record = append(record, csvOpts.escapeCell(task.csvValue(column, cfg)))
+		}
+
+		if err := csvWriter.Write(record); err != nil {
+			return errors.Wrap(err, "failed to write a row of CSV")
+		}
+	}
+
+	csvWriter.Flush()
+
+	return errors.Wrap(csvWriter.Error(), "failed to write CSV")
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// addCSVTags adds the additional tags of the given key from the values of a
+// cell, which are separated by whitespaces.
+func addCSVTags(task *Task, key, value string) error {
+	for _, tagValue := range strings.Fields(value) {
+		if strings.Contains(tagValue, ":") {
+			return errors.Errorf("invalid tag value %q, it must not contain \":\"", tagValue)
+		}
+
+		if err := validateTag(key, tagValue); err != nil {
+			return err
+		}
+
+		task.AdditionalTags.Add(key, tagValue)
+	}
+
+	return nil
+}
+
+// csvRecordToTask returns the task of the CSV record. The task is built from
+// the cells as they are, so that a cell is never taken as the other fields.
+// Such as "due:2020-01-05" in the todo text, which is kept as a text.
+//
+//nolint:cyclop,funlen // complexity is high but it is a simple switch of columns
+func csvRecordToTask(columns []CSVColumn, record []string, cfg *Config) (*Task, *CSVRowError) {
+	task := new(Task)
+
+	for i, column := range columns {
+		value := strings.TrimSpace(record[i])
+
+		var err error
+
+		switch column {
+		case CSVColumnID:
+			if isNotEmpty(value) {
+				task.ID, err = strconv.Atoi(value)
+			}
+		case CSVColumnCompleted:
+			task.Completed, err = parseCSVBool(value)
+		case CSVColumnPriority:
+			task.Priority = strings.ToUpper(strings.Trim(value, "()"))
+			if isNotEmpty(task.Priority) && (len(task.Priority) != 1 || task.Priority[0] < 'A' || task.Priority[0] > 'Z') {
+				err = errors.Errorf("invalid priority %q, expected A to Z", value)
+			}
+		case CSVColumnCompletedDate:
+			task.CompletedDate, err = parseCSVDate(value, cfg)
+		case CSVColumnCreatedDate:
+			task.CreatedDate, err = parseCSVDate(value, cfg)
+		case CSVColumnDueDate:
+			task.DueDate, err = parseCSVDate(value, cfg)
+		case CSVColumnThresholdDate:
+			task.ThresholdDate, err = parseCSVDate(value, cfg)
+		case CSVColumnTodo:
+			task.Todo = value
+		case CSVColumnContexts:
+			task.Contexts = append(task.Contexts, splitCSVValues(value, "@")...)
+		case CSVColumnProjects:
+			task.Projects = append(task.Projects, splitCSVValues(value, "+")...)
+		default:
+			key, _ := column.TagKey()
+			err = addCSVTags(task, key, value)
+		}
+
+		if err != nil {
+			return nil, &CSVRowError{Row: 0, Column: column, Err: err}
+		}
+	}
+
+	if task.HasCompletedDate() {
+		task.Completed = true
+	}
+
+	// The contexts and projects in the todo text are kept as well
+	task.Contexts = mergeValues(task.Contexts, getSlice(task.Todo, contextRx))
+	task.Projects = mergeValues(task.Projects, getSlice(task.Todo, projectRx))
+	task.Original = task.stringWith(cfg)
+
+	return task, nil
+}
+
+// csvValue returns the value of the cell of the given column.
+func (task *Task) csvValue(column CSVColumn, cfg *Config) string {
+	switch column {
+	case CSVColumnID:
+		return strconv.Itoa(task.ID)
+	case CSVColumnCompleted:
+		if task.Completed {
+			return "x"
+		}
+
+		return emptyStr
+	case CSVColumnPriority:
+		return task.Priority
+	case CSVColumnCompletedDate:
+		return cfg.formatDate(task.CompletedDate)
+	case CSVColumnCreatedDate:
+		return cfg.formatDate(task.CreatedDate)
+	case CSVColumnDueDate:
+		return cfg.formatDate(task.DueDate)
+	case CSVColumnThresholdDate:
+		return cfg.formatDate(task.ThresholdDate)
+	case CSVColumnTodo:
+		return task.Todo
+	case CSVColumnContexts:
+		return strings.Join(task.Contexts, " ")
+	case CSVColumnProjects:
+		return strings.Join(task.Projects, " ")
+	}
+
+	key, _ := column.TagKey()
+
+	return strings.Join(task.AdditionalTags.Values(key), " ")
+}
+
+// isCSVFormula returns true if a spreadsheet application may run the cell as a
+// formula.
+func isCSVFormula(cell string) bool {
+	return isNotEmpty(cell) && strings.ContainsRune("=+-@\t\r", rune(cell[0]))
+}
+
+// parseCSVBool parses the value of the "completed" column. Such as "x", "true",
+// "yes" or "1". An empty value is false.
+func parseCSVBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case emptyStr:
+		return false, nil
+	case "x":
+		return true, nil
+	}
+
+	return parseTagBool(value)
+}
+
+// parseCSVDate parses the date of a cell. An empty value is the unset date.
+func parseCSVDate(value string, cfg *Config) (Date, error) {
+	if isEmpty(value) {
+		return Date{}, nil
+	}
+
+	return cfg.parseDate(value)
+}
+
+// splitCSVValues splits the multiple values of a cell by whitespaces, trimming
+// the given prefix such as "@" of the contexts.
+func splitCSVValues(value, prefix string) []string {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	for i, field := range fields {
+		fields[i] = strings.TrimPrefix(field, prefix)
+	}
+
+	return fields
+}
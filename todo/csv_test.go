@@ -0,0 +1,223 @@
+package todo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskList_WriteToCSV(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString(
+		"(A) 2020-01-01 Call Mom @phone @home +family due:2020-01-05 dep:3 est:30 dep:7\n" +
+			"x 2020-01-03 Pick up milk, eggs\n",
+	)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, tasklist.WriteToCSV(&buf, CSVOptions{}))
+	require.Equal(t,
+		"id,completed,priority,completed_date,created_date,due_date,threshold_date,todo,contexts,projects\n"+
+			"1,,A,,2020-01-01,2020-01-05,,Call Mom @phone @home +family,home phone,family\n"+
+			"2,x,,2020-01-03,,,,\"Pick up milk, eggs\",,\n",
+		buf.String())
+
+	// Chosen columns as TSV
+	buf.Reset()
+
+	require.NoError(t, tasklist.WriteToCSV(&buf, CSVOptions{
+		Columns: []CSVColumn{CSVColumnPriority, CSVColumnTodo, CSVColumnTag("dep"), CSVColumnTag("est")},
+		Comma:   '\t',
+	}))
+	require.Equal(t,
+		"priority\ttodo\ttag:dep\ttag:est\n"+
+			"A\tCall Mom @phone @home +family\t3 7\t30\n"+
+			"\tPick up milk, eggs\t\t\n",
+		buf.String())
+
+	require.Error(t, tasklist.WriteToCSV(&buf, CSVOptions{Columns: []CSVColumn{"unknown"}}))
+}
+
+func TestTaskList_WriteToCSV_escape_formulas(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString("=1+2 est:-5\n@home Call Mom\n")
+	require.NoError(t, err)
+
+	csvOpts := CSVOptions{Columns: []CSVColumn{CSVColumnTodo, CSVColumnTag("est")}, EscapeFormulas: false}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, tasklist.WriteToCSV(&buf, csvOpts))
+	require.Equal(t, "todo,tag:est\n=1+2,-5\n@home Call Mom,\n", buf.String(),
+		"cells should be written as they are by default")
+
+	buf.Reset()
+
+	csvOpts.EscapeFormulas = true
+
+	require.NoError(t, tasklist.WriteToCSV(&buf, csvOpts))
+	require.Equal(t, "todo,tag:est\n'=1+2,'-5\n'@home Call Mom,\n", buf.String())
+
+	decoded, err := LoadFromCSV(&buf, csvOpts)
+	require.NoError(t, err)
+	require.Equal(t, tasklist[0].Todo, decoded[0].Todo, "escaped cells should be unescaped on importing")
+	require.Equal(t, []string{"-5"}, decoded[0].AdditionalTags.Values("est"))
+	require.Equal(t, "@home Call Mom", decoded[1].Todo)
+}
+
+func TestTaskList_WriteToCSV_non_task(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString("# comment\nCall Mom", WithKeepNonTaskLines(true))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, tasklist.WriteToCSV(&buf, CSVOptions{Columns: []CSVColumn{CSVColumnTodo}}))
+	require.Equal(t, "todo\nCall Mom\n", buf.String(), "non-task entries should not be written")
+}
+
+func TestLoadFromCSV(t *testing.T) {
+	t.Parallel()
+
+	const input = "priority\ttodo\tcontexts\tprojects\tdue_date\tcompleted\ttag:est\n" +
+		"a\tCall Mom @phone\t@home phone\t+family work\t2020-01-05\t\t30\n" +
+		"\tPick up milk\t\t\t\tyes\t\n"
+
+	tasklist, err := LoadFromCSV(strings.NewReader(input), CSVOptions{Comma: '\t'})
+	require.NoError(t, err)
+	require.Len(t, tasklist, 2)
+
+	task := tasklist[0]
+	require.Equal(t, 1, task.ID, "IDs should be numbered if missing")
+	require.Equal(t, "A", task.Priority)
+	require.Equal(t, "Call Mom @phone", task.Todo, "todo text should be kept as it is")
+	require.Equal(t, []string{"home", "phone"}, task.Contexts)
+	require.Equal(t, []string{"family", "work"}, task.Projects)
+	require.Equal(t, NewDate(2020, 1, 5), task.DueDate)
+	require.Equal(t, "30", task.AdditionalTags.Values("est")[0])
+	require.Equal(t, "(A) Call Mom @phone @home +family +work est:30 due:2020-01-05", task.Original)
+
+	require.Equal(t, 2, tasklist[1].ID)
+	require.True(t, tasklist[1].Completed)
+}
+
+func TestLoadFromCSV_cells_as_they_are(t *testing.T) {
+	t.Parallel()
+
+	const input = "id,todo,due_date,tag:est\n" +
+		"3,\"x 2020-01-01 (B) Pay rent due:2020-01-09 est:10\",2020-01-05,30 45\n" +
+		",Call Mom,,\n" +
+		"1,Write report,,\n" +
+		",Buy milk,,\n"
+
+	tasklist, err := LoadFromCSV(strings.NewReader(input), CSVOptions{})
+	require.NoError(t, err)
+	require.Len(t, tasklist, 4)
+
+	task := tasklist[0]
+	require.False(t, task.Completed, "todo text should not be parsed")
+	require.Empty(t, task.Priority)
+	require.Equal(t, NewDate(2020, 1, 5), task.DueDate)
+	require.Equal(t, []string{"30", "45"}, task.AdditionalTags.Values("est"))
+	require.Equal(t, "x 2020-01-01 (B) Pay rent due:2020-01-09 est:10", task.Todo)
+
+	// Missing IDs are numbered after the largest one
+	require.Equal(t, []int{3, 4, 1, 5}, []int{tasklist[0].ID, tasklist[1].ID, tasklist[2].ID, tasklist[3].ID})
+
+	// Invalid tag values and columns
+	_, err = LoadFromCSV(strings.NewReader("todo,tag:see\nCall Mom,http://example.com\n"), CSVOptions{})
+	require.ErrorContains(t, err, `row 2, column "tag:see": invalid tag value`)
+
+	_, err = LoadFromCSV(strings.NewReader("todo,tag:due\nCall Mom,2020-01-05\n"), CSVOptions{})
+	require.ErrorContains(t, err, `row 1, column "tag:due": unknown column`)
+}
+
+func TestLoadFromCSV_round_trip(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromPath(testInputTask)
+	require.NoError(t, err)
+
+	columns := append(DefaultCSVColumns(), CSVColumnTag("rec"))
+
+	var buf bytes.Buffer
+
+	require.NoError(t, tasklist.WriteToCSV(&buf, CSVOptions{Columns: columns}))
+
+	decoded, err := LoadFromCSV(&buf, CSVOptions{})
+	require.NoError(t, err)
+	require.Len(t, decoded, len(tasklist))
+
+	for i := range tasklist {
+		require.Equal(t, tasklist[i].ID, decoded[i].ID)
+		require.Equal(t, tasklist[i].Todo, decoded[i].Todo)
+		require.Equal(t, tasklist[i].Priority, decoded[i].Priority)
+		require.Equal(t, tasklist[i].Completed, decoded[i].Completed)
+		require.Equal(t, tasklist[i].CompletedDate, decoded[i].CompletedDate)
+		require.Equal(t, tasklist[i].CreatedDate, decoded[i].CreatedDate)
+		require.Equal(t, tasklist[i].DueDate, decoded[i].DueDate)
+		require.Equal(t, tasklist[i].ThresholdDate, decoded[i].ThresholdDate)
+		require.ElementsMatch(t, tasklist[i].Contexts, decoded[i].Contexts)
+		require.ElementsMatch(t, tasklist[i].Projects, decoded[i].Projects)
+		require.Equal(t, tasklist[i].AdditionalTags.Values("rec"), decoded[i].AdditionalTags.Values("rec"))
+	}
+}
+
+func TestLoadFromCSV_row_errors(t *testing.T) {
+	t.Parallel()
+
+	const input = "id,todo,due_date,priority,completed\n" +
+		"1,Call Mom,2020-01-05,A,\n" +
+		"2,Pick up milk,tomorrow,,\n" +
+		"3,Write report,,AB,\n" +
+		"4,Send report,,,maybe\n" +
+		"5,\"Broken\"quote,,,\n" +
+		"6,Too few\n" +
+		"seven,Buy bread,,,\n" +
+		"8,Buy eggs,,,x\n"
+
+	tasklist, err := LoadFromCSV(strings.NewReader(input), CSVOptions{})
+	require.Error(t, err)
+	require.Len(t, tasklist, 2, "valid rows should be returned")
+	require.Equal(t, 1, tasklist[0].ID)
+	require.Equal(t, 8, tasklist[1].ID)
+	require.True(t, tasklist[1].Completed)
+
+	var rowErrs CSVErrors
+
+	require.ErrorAs(t, err, &rowErrs)
+	require.Len(t, rowErrs, 6)
+
+	for i, expect := range []struct {
+		column CSVColumn
+		row    int
+	}{
+		{row: 3, column: CSVColumnDueDate},
+		{row: 4, column: CSVColumnPriority},
+		{row: 5, column: CSVColumnCompleted},
+		{row: 6, column: ""},
+		{row: 7, column: ""},
+		{row: 8, column: CSVColumnID},
+	} {
+		require.Equal(t, expect.row, rowErrs[i].Row, "error #%d: %v", i, rowErrs[i])
+		require.Equal(t, expect.column, rowErrs[i].Column, "error #%d: %v", i, rowErrs[i])
+	}
+
+	require.Contains(t, err.Error(), `row 3, column "due_date": `)
+}
+
+func TestLoadFromCSV_invalid_header(t *testing.T) {
+	t.Parallel()
+
+	_, err := LoadFromCSV(strings.NewReader("todo,unknown\nCall Mom,1\n"), CSVOptions{})
+	require.ErrorContains(t, err, `row 1, column "unknown": unknown column`)
+
+	_, err = LoadFromCSV(strings.NewReader(""), CSVOptions{})
+	require.Error(t, err, "missing header row should be an error")
+}
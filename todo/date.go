@@ -0,0 +1,417 @@
+package todo
+
+import (
+	"cmp"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Date
+// ----------------------------------------------------------------------------
+
+// Date represents a calendar date of todo.txt, such as "2020-03-08", without
+// location. So that it never shifts a day when compared or written in another
+// time zone.
+//
+// A Date may also have a time of day, such as "2020-03-08T14:00", which is
+// only parsed and written with the WithDateTime(true) option. See HasTime.
+//
+// The zero value is the "unset" date. Use IsZero to check it. Use the Time
+// method and DateOf function to convert from/to time.Time.
+type Date struct {
+	year    int
+	month   time.Month
+	day     int
+	sec     int  // sec is the time of day in seconds from midnight.
+	hasTime bool // hasTime is true if the date has a time of day.
+}
+
+// DateTimeLayout is the layout of a date with time of day, such as
+// "2020-03-08T14:00". The seconds are added if not zero, such as
+// "2020-03-08T14:00:30".
+const DateTimeLayout = "2006-01-02T15:04"
+
+// timeLayouts are the layouts of the time of day after the "T" separator.
+//
+//nolint:gochecknoglobals // global variable is intentional as a lookup table
+var timeLayouts = []string{"15:04", "15:04:05"}
+
+// ----------------------------------------------------------------------------
+//  Constructors
+// ----------------------------------------------------------------------------
+
+// DateOf returns the date of the given time in its location, without the time
+// of day. The zero time returns the zero Date. See DateTimeOf to keep the time.
+func DateOf(t time.Time) Date {
+	if t.IsZero() {
+		return Date{}
+	}
+
+	year, month, day := t.Date()
+
+	return Date{year: year, month: month, day: day}
+}
+
+// DateTimeOf returns the date with the time of day of the given time in its
+// location. The zero time returns the zero Date.
+func DateTimeOf(t time.Time) Date {
+	if t.IsZero() {
+		return Date{}
+	}
+
+	hour, minute, sec := t.Clock()
+
+	return DateOf(t).WithTime(hour, minute, sec)
+}
+
+// NewDate returns the Date of the given year, month and day. The values outside
+// of their usual ranges are normalized as time.Date does. Such as October 32 is
+// November 1.
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// ParseDate parses the date in DateLayout, such as "2020-03-08", into a Date.
+func ParseDate(value string) (Date, error) {
+	return parseDateLayout(DateLayout, value)
+}
+
+// ParseDateTime parses the date with or without the time of day, such as
+// "2020-03-08", "2020-03-08T14:00" or "2020-03-08T14:00:30", into a Date.
+func ParseDateTime(value string) (Date, error) {
+	return parseDateTimeLayout(DateLayout, value)
+}
+
+// Today returns the date of today. Today is taken from the Clock and the
+// location of the options. See WithClock and WithLocation.
+func Today(opts ...Option) Date {
+	return NewConfig(opts...).today()
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// AddDate returns the date of the given number of years, months and days added
+// to the date. It normalizes the result as time.Time.AddDate does. Such as
+// 2020-01-31 plus 1 month is 2020-03-02. The time of day is kept.
+func (d Date) AddDate(years, months, days int) Date {
+	return DateOf(d.Time(time.UTC).AddDate(years, months, days)).withTimeOf(d)
+}
+
+// AddDays returns the date of the given number of days added to the date. The
+// days can be negative to go back.
+func (d Date) AddDays(days int) Date {
+	return d.AddDate(0, 0, days)
+}
+
+// After returns true if the date is after the other.
+func (d Date) After(other Date) bool {
+	return d.Compare(other) > 0
+}
+
+// Before returns true if the date is before the other.
+func (d Date) Before(other Date) bool {
+	return d.Compare(other) < 0
+}
+
+// Clock returns the time of day. It is midnight if the date has no time of day.
+func (d Date) Clock() (int, int, int) {
+	return d.sec / 3600, d.sec / 60 % 60, d.sec % 60
+}
+
+// Compare compares the date with the other. It returns -1 if the date is before
+// the other, +1 if after, and 0 if they are the same. The zero Date is before
+// any other date.
+//
+// On the same day, a date without time of day is before the ones with time.
+func (d Date) Compare(other Date) int {
+	if result := cmp.Compare(d.year, other.year); result != 0 {
+		return result
+	}
+
+	if result := cmp.Compare(d.month, other.month); result != 0 {
+		return result
+	}
+
+	if result := cmp.Compare(d.day, other.day); result != 0 {
+		return result
+	}
+
+	if d.hasTime != other.hasTime {
+		if d.hasTime {
+			return 1
+		}
+
+		return -1
+	}
+
+	return cmp.Compare(d.sec, other.sec)
+}
+
+// Date returns the year, month and day of the date.
+func (d Date) Date() (int, time.Month, int) {
+	return d.year, d.month, d.day
+}
+
+// Day returns the day of the month.
+func (d Date) Day() int {
+	return d.day
+}
+
+// DateOnly returns the date without the time of day.
+func (d Date) DateOnly() Date {
+	return Date{year: d.year, month: d.month, day: d.day, sec: 0, hasTime: false}
+}
+
+// Format returns the date formatted in the given layout of time.Time.Format.
+// The zero Date returns an empty string.
+func (d Date) Format(layout string) string {
+	if d.IsZero() {
+		return emptyStr
+	}
+
+	return d.Time(time.UTC).Format(layout)
+}
+
+// HasTime returns true if the date has a time of day.
+func (d Date) HasTime() bool {
+	return d.hasTime
+}
+
+// ISOWeek returns the ISO 8601 year and week number of the date.
+func (d Date) ISOWeek() (int, int) {
+	return d.Time(time.UTC).ISOWeek()
+}
+
+// IsZero returns true if the date is the zero Date, which means "unset".
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// MarshalJSON implements the json.Marshaler interface. The date is a string as
+// String returns, and the zero Date is null.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The date is a
+// text as String returns, and the zero Date is an empty text.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Month returns the month of the year.
+func (d Date) Month() time.Month {
+	return d.month
+}
+
+// String returns the date in DateLayout, such as "2020-03-08", or in
+// DateTimeLayout if the date has a time of day, such as "2020-03-08T14:00". The
+// zero Date returns an empty string.
+func (d Date) String() string {
+	return d.formatLayout(DateLayout, true)
+}
+
+// Sub returns the number of days from the other date to the date, regardless
+// of the time of day. It is negative if the other date is after the date.
+func (d Date) Sub(other Date) int {
+	return int(d.DateOnly().Time(time.UTC).Sub(other.DateOnly().Time(time.UTC)) / oneDay)
+}
+
+// Time returns the time of the date in the given location. It is at midnight if
+// the date has no time of day. The zero Date returns the zero time.
+func (d Date) Time(loc *time.Location) time.Time {
+	if d.IsZero() {
+		return time.Time{}
+	}
+
+	return time.Date(d.year, d.month, d.day, 0, 0, d.sec, 0, loc)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts a string
+// as ParseDateTime does. Null and an empty string are the zero Date.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Date{}
+
+		return nil
+	}
+
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return errors.Errorf("invalid date %s, expected a string such as \"2020-03-08\"", data)
+	}
+
+	return d.UnmarshalText(data[1 : len(data)-1])
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts
+// the text as ParseDateTime does. An empty text is the zero Date.
+func (d *Date) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*d = Date{}
+
+		return nil
+	}
+
+	date, err := ParseDateTime(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = date
+
+	return nil
+}
+
+// Weekday returns the day of the week of the date.
+func (d Date) Weekday() time.Weekday {
+	return d.Time(time.UTC).Weekday()
+}
+
+// WithTime returns the date with the given time of day. The values outside of
+// their usual ranges are normalized as time.Date does.
+func (d Date) WithTime(hour, minute, sec int) Date {
+	if d.IsZero() {
+		return d
+	}
+
+	moment := time.Date(d.year, d.month, d.day, hour, minute, sec, 0, time.UTC)
+	date := DateOf(moment)
+	date.sec = moment.Hour()*3600 + moment.Minute()*60 + moment.Second()
+	date.hasTime = true
+
+	return date
+}
+
+// Year returns the year of the date.
+func (d Date) Year() int {
+	return d.year
+}
+
+// formatLayout returns the date in the given layout of the date part. The time
+// of day is appended in the form of DateTimeLayout if withTime is true and the
+// date has the time.
+func (d Date) formatLayout(layout string, withTime bool) string {
+	if !withTime || !d.hasTime {
+		return d.Format(layout)
+	}
+
+	timeLayout := timeLayouts[0]
+	if d.sec%60 != 0 {
+		timeLayout = timeLayouts[1]
+	}
+
+	return d.Format(layout + "T" + timeLayout)
+}
+
+// withTimeOf returns the date with the time of day of the other date, if any.
+func (d Date) withTimeOf(other Date) Date {
+	if d.IsZero() {
+		return d
+	}
+
+	d.sec, d.hasTime = other.sec, other.hasTime
+
+	return d
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// layoutElements are the elements of the date layouts and their regular
+// expressions. The longer elements come first to match them before the shorter
+// ones, such as "2006" before "2".
+//
+//nolint:gochecknoglobals // global variable is intentional as a lookup table
+var layoutElements = []struct {
+	element string
+	pattern string
+}{
+	{element: "January", pattern: `[A-Za-z]+`},
+	{element: "Monday", pattern: `[A-Za-z]+`},
+	{element: "2006", pattern: `\d{4}`},
+	{element: "Jan", pattern: `[A-Za-z]{3}`},
+	{element: "Mon", pattern: `[A-Za-z]{3}`},
+	{element: "002", pattern: `\d{3}`},
+	{element: "01", pattern: `\d{2}`},
+	{element: "02", pattern: `\d{2}`},
+	{element: "06", pattern: `\d{2}`},
+	{element: "1", pattern: `\d{1,2}`},
+	{element: "2", pattern: `\d{1,2}`},
+}
+
+// layoutPattern returns the regular expression which matches the dates in the
+// given layout. Such as `\d{4}-\d{2}-\d{2}` for "2006-01-02". The characters
+// other than the date elements are matched as they are.
+func layoutPattern(layout string) string {
+	var pattern strings.Builder
+
+	literalStart := 0
+
+	for pos := 0; pos < len(layout); {
+		matched := false
+
+		for _, elem := range layoutElements {
+			if strings.HasPrefix(layout[pos:], elem.element) {
+				pattern.WriteString(regexp.QuoteMeta(layout[literalStart:pos]))
+				pattern.WriteString(elem.pattern)
+
+				pos += len(elem.element)
+				literalStart = pos
+				matched = true
+
+				break
+			}
+		}
+
+		if !matched {
+			pos++
+		}
+	}
+
+	pattern.WriteString(regexp.QuoteMeta(layout[literalStart:]))
+
+	return pattern.String()
+}
+
+// parseDateLayout parses the date in the given layout into a Date.
+func parseDateLayout(layout, value string) (Date, error) {
+	parsed, err := time.Parse(layout, value)
+	if err != nil {
+		return Date{}, errors.Wrap(err, "failed to parse time")
+	}
+
+	return DateOf(parsed), nil
+}
+
+// parseDateTimeLayout parses the date in the given layout, optionally followed
+// by "T" and the time of day, into a Date.
+func parseDateTimeLayout(layout, value string) (Date, error) {
+	datePart, timePart, found := strings.Cut(value, "T")
+	if !found {
+		return parseDateLayout(layout, value)
+	}
+
+	date, err := parseDateLayout(layout, datePart)
+	if err != nil {
+		return Date{}, err
+	}
+
+	for _, timeLayout := range timeLayouts {
+		if clock, err := time.Parse(timeLayout, timePart); err == nil {
+			return date.WithTime(clock.Clock()), nil
+		}
+	}
+
+	return Date{}, errors.Errorf("failed to parse time of day %q, expected such as \"14:00\"", timePart)
+}
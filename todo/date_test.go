@@ -0,0 +1,175 @@
+package todo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDate(t *testing.T) {
+	t.Parallel()
+
+	date := NewDate(2020, 2, 29)
+
+	require.False(t, date.IsZero())
+	require.Equal(t, "2020-02-29", date.String())
+	require.Equal(t, 2020, date.Year())
+	require.Equal(t, time.February, date.Month())
+	require.Equal(t, 29, date.Day())
+	require.Equal(t, time.Saturday, date.Weekday())
+	require.Equal(t, "Feb 29", date.Format("Jan 2"))
+
+	year, week := NewDate(2021, 1, 1).ISOWeek()
+	require.Equal(t, 2020, year)
+	require.Equal(t, 53, week)
+
+	// Normalization
+	require.Equal(t, NewDate(2020, 3, 1), NewDate(2020, 2, 30))
+	require.Equal(t, NewDate(2020, 3, 1), date.AddDays(1))
+	require.Equal(t, NewDate(2019, 12, 31), NewDate(2020, 1, 1).AddDays(-1))
+	require.Equal(t, NewDate(2021, 3, 1), date.AddDate(1, 0, 0))
+
+	// Comparison
+	require.True(t, date.Before(date.AddDays(1)))
+	require.True(t, date.After(date.AddDays(-1)))
+	require.Equal(t, 0, date.Compare(NewDate(2020, 2, 29)))
+	require.Equal(t, -1, Date{}.Compare(date), "zero date should be before any date")
+	require.Equal(t, 366, NewDate(2021, 1, 1).Sub(NewDate(2020, 1, 1)))
+	require.Equal(t, -1, date.Sub(date.AddDays(1)))
+}
+
+func TestDate_zero(t *testing.T) {
+	t.Parallel()
+
+	var date Date
+
+	require.True(t, date.IsZero())
+	require.Empty(t, date.String())
+	require.True(t, date.Time(time.UTC).IsZero())
+	require.Equal(t, Date{}, DateOf(time.Time{}))
+}
+
+func TestDate_time_conversion(t *testing.T) {
+	t.Parallel()
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	instant := time.Date(2020, 3, 8, 20, 0, 0, 0, time.UTC)
+
+	require.Equal(t, NewDate(2020, 3, 8), DateOf(instant))
+	require.Equal(t, NewDate(2020, 3, 9), DateOf(instant.In(tokyo)))
+	require.Equal(t, time.Date(2020, 3, 8, 0, 0, 0, 0, tokyo), NewDate(2020, 3, 8).Time(tokyo))
+}
+
+func TestDate_time_of_day(t *testing.T) {
+	t.Parallel()
+
+	date := NewDate(2020, 3, 8)
+	timed := date.WithTime(14, 0, 0)
+
+	require.True(t, timed.HasTime())
+	require.False(t, date.HasTime())
+	require.Equal(t, "2020-03-08T14:00", timed.String())
+	require.Equal(t, "2020-03-08T14:00:30", date.WithTime(14, 0, 30).String())
+	require.Equal(t, NewDate(2020, 3, 9).WithTime(1, 0, 0), date.WithTime(25, 0, 0), "should be normalized")
+	require.Equal(t, date, timed.DateOnly())
+	require.Equal(t, NewDate(2020, 3, 9).WithTime(14, 0, 0), timed.AddDays(1), "time of day should be kept")
+
+	hour, minute, sec := timed.Clock()
+	require.Equal(t, []int{14, 0, 0}, []int{hour, minute, sec})
+
+	// Date only is before the times of the same day
+	require.True(t, date.Before(date.WithTime(0, 0, 0)))
+	require.True(t, timed.Before(date.WithTime(15, 0, 0)))
+	require.True(t, timed.Before(NewDate(2020, 3, 9)))
+	require.Equal(t, 1, NewDate(2020, 3, 9).Sub(timed))
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	instant := time.Date(2020, 3, 8, 14, 0, 0, 0, tokyo)
+
+	require.Equal(t, timed, DateTimeOf(instant))
+	require.Equal(t, instant, timed.Time(tokyo))
+}
+
+func TestParseDateTime(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		expect Date
+	}{
+		{input: "2020-03-08", expect: NewDate(2020, 3, 8)},
+		{input: "2020-03-08T14:00", expect: NewDate(2020, 3, 8).WithTime(14, 0, 0)},
+		{input: "2020-03-08T14:00:30", expect: NewDate(2020, 3, 8).WithTime(14, 0, 30)},
+	} {
+		actual, err := ParseDateTime(test.input)
+		require.NoError(t, err, "input: %s", test.input)
+		require.Equal(t, test.expect, actual, "input: %s", test.input)
+	}
+
+	for _, input := range []string{"2020-03-08T", "2020-03-08T25:00", "2020-03-08T2pm"} {
+		_, err := ParseDateTime(input)
+		require.Error(t, err, "input: %s", input)
+	}
+
+	_, err := ParseDate("2020-03-08T14:00")
+	require.Error(t, err, "ParseDate should not accept time of day")
+}
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	date, err := ParseDate("2020-03-08")
+	require.NoError(t, err)
+	require.Equal(t, NewDate(2020, 3, 8), date)
+
+	_, err = ParseDate("2020-13-45")
+	require.Error(t, err)
+}
+
+func TestDate_marshal(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		Due     Date `json:"due"`
+		Created Date `json:"created"`
+	}
+
+	data, err := json.Marshal(record{Due: NewDate(2020, 3, 8), Created: Date{}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"due":"2020-03-08","created":null}`, string(data))
+
+	var decoded record
+
+	require.NoError(t, json.Unmarshal([]byte(`{"due":"2020-03-08","created":""}`), &decoded))
+	require.Equal(t, NewDate(2020, 3, 8), decoded.Due)
+	require.True(t, decoded.Created.IsZero())
+
+	require.Error(t, json.Unmarshal([]byte(`{"due":20200308}`), &decoded))
+	require.Error(t, json.Unmarshal([]byte(`{"due":"tomorrow"}`), &decoded))
+
+	text, err := NewDate(2020, 3, 8).MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "2020-03-08", string(text))
+
+	var date Date
+
+	require.NoError(t, date.UnmarshalText([]byte("2021-01-01")))
+	require.Equal(t, NewDate(2021, 1, 1), date)
+
+	require.NoError(t, json.Unmarshal([]byte(`"2021-01-01T09:30"`), &date))
+	require.Equal(t, NewDate(2021, 1, 1).WithTime(9, 30, 0), date)
+}
+
+func TestTask_HasCompletedDate_without_completed_flag(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("x 2020-03-08 Send report")
+	require.NoError(t, err)
+
+	task.Completed = false
+
+	require.True(t, task.HasCompletedDate(), "should not depend on the Completed flag")
+	require.Equal(t, "Send report", task.String(), "completed date is only written for completed tasks")
+}
@@ -6,10 +6,11 @@ package todo
 
 // Go generate directives.
 //
-// These will generate the stringer implementations for TaskSortByType and
-// TaskSegmentType types.
+// These will generate the stringer implementations for TaskSortByType,
+// TaskSegmentType and ExprOp types.
 // Note that to call `go generate ./...` you need `stringer` command installed.
 // You can use `docker compose run go_generate` for convenience.
 //
 //go:generate stringer -type TaskSortByType -trimprefix Sort -output tasksortbytype_string.go
 //go:generate stringer -type TaskSegmentType -trimprefix Segment -output tasksegmenttype_string.go
+//go:generate stringer -type ExprOp -trimprefix Op -output exprop_string.go
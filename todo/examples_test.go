@@ -1,7 +1,9 @@
 package todo_test
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"strings"
 
@@ -243,3 +245,36 @@ func ExampleTaskList_Sort() {
 	// After  #2: [Apple]
 	// After  #3: [Apple]
 }
+
+// ============================================================================
+//  Reader
+// ============================================================================
+
+func ExampleNewReader() {
+	// Any io.Reader can be used, such as os.File of a huge done.txt archive.
+	file := strings.NewReader(`
+		(A) Call Mom @Phone +Family
+		x 2020-11-30 (A) Schedule annual checkup +Health
+		x 2020-12-01 Download Todo.txt mobile app @Phone
+		Pick up milk @GroceryStore
+	`)
+
+	// Read only the completed tasks, one by one.
+	reader := todo.NewReader(file).Filter(todo.FilterCompleted)
+
+	for {
+		task, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(task.ID, task.Todo)
+	}
+	// Output:
+	// 2 Schedule annual checkup +Health
+	// 3 Download Todo.txt mobile app @Phone
+}
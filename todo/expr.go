@@ -0,0 +1,146 @@
+package todo
+
+import "strings"
+
+// ----------------------------------------------------------------------------
+//  Type: ExprOp
+// ----------------------------------------------------------------------------
+
+// ExprOp represents the operator of an Expr node.
+type ExprOp uint8
+
+// Operators of Expr nodes.
+const (
+	OpPredicate ExprOp = iota + 1 // OpPredicate is a leaf node of a named Predicate.
+	OpAnd                         // OpAnd matches if all the children match.
+	OpOr                          // OpOr matches if any of the children match.
+	OpNot                         // OpNot matches if the only child does not match.
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Expr
+// ----------------------------------------------------------------------------
+
+// Expr is a boolean expression tree of predicates. Unlike a composed Predicate,
+// which is an opaque function, an Expr can be inspected and printed. Such as:
+//
+//	expr := todo.ExprAnd(
+//	    todo.ExprPredicate("project:X", todo.FilterByProject("X")),
+//	    todo.ExprNot(todo.ExprPredicate("completed", todo.FilterCompleted)),
+//	    todo.ExprOr(
+//	        todo.ExprPredicate("due:today", todo.FilterDueToday),
+//	        todo.ExprPredicate("overdue", todo.FilterOverdue),
+//	    ),
+//	)
+//
+//	fmt.Println(expr)
+//	// Output: project:X AND NOT completed AND (due:today OR overdue)
+//
+//	filtered := tasklist.Filter(expr.ToPredicate())
+type Expr struct {
+	Predicate Predicate // Predicate of the leaf node. Nil for the other nodes.
+	Name      string    // Name of the leaf node used to print the expression.
+	Children  []*Expr   // Children of the AND, OR and NOT nodes.
+	Op        ExprOp    // Op is the operator of the node.
+}
+
+// ----------------------------------------------------------------------------
+//  Constructors
+// ----------------------------------------------------------------------------
+
+// ExprAnd returns an Expr which matches if all the given expressions match.
+func ExprAnd(exprs ...*Expr) *Expr {
+	return &Expr{Op: OpAnd, Children: exprs, Predicate: nil, Name: emptyStr}
+}
+
+// ExprNot returns an Expr which matches if the given expression does not match.
+func ExprNot(expr *Expr) *Expr {
+	return &Expr{Op: OpNot, Children: []*Expr{expr}, Predicate: nil, Name: emptyStr}
+}
+
+// ExprOr returns an Expr which matches if any of the given expressions match.
+func ExprOr(exprs ...*Expr) *Expr {
+	return &Expr{Op: OpOr, Children: exprs, Predicate: nil, Name: emptyStr}
+}
+
+// ExprPredicate returns a leaf Expr of the given predicate. The name is used to
+// print the expression.
+func ExprPredicate(name string, predicate Predicate) *Expr {
+	return &Expr{Op: OpPredicate, Name: name, Predicate: predicate, Children: nil}
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// Match returns true if the task matches the expression.
+func (expr *Expr) Match(task Task) bool {
+	switch expr.Op {
+	case OpAnd:
+		for _, child := range expr.Children {
+			if !child.Match(task) {
+				return false
+			}
+		}
+
+		return true
+	case OpOr:
+		for _, child := range expr.Children {
+			if child.Match(task) {
+				return true
+			}
+		}
+
+		return false
+	case OpNot:
+		return len(expr.Children) > 0 && !expr.Children[0].Match(task)
+	case OpPredicate:
+		return expr.Predicate != nil && expr.Predicate(task)
+	}
+
+	return false
+}
+
+// ToPredicate returns the expression as a Predicate to use with TaskList.Filter
+// and the other functions which take a Predicate.
+func (expr *Expr) ToPredicate() Predicate {
+	return expr.Match
+}
+
+// String returns the expression in a human readable form. Such as:
+//
+//	project:X AND NOT completed AND (due:today OR overdue)
+//
+// The nested AND and OR expressions are grouped with parentheses.
+func (expr *Expr) String() string {
+	switch expr.Op {
+	case OpAnd, OpOr:
+		parts := make([]string, 0, len(expr.Children))
+
+		for _, child := range expr.Children {
+			parts = append(parts, child.groupString())
+		}
+
+		return strings.Join(parts, " "+strings.ToUpper(expr.Op.String())+" ")
+	case OpNot:
+		if len(expr.Children) == 0 {
+			return "NOT"
+		}
+
+		return "NOT " + expr.Children[0].groupString()
+	case OpPredicate:
+		return expr.Name
+	}
+
+	return emptyStr
+}
+
+// groupString returns the expression string grouped with parentheses if it is a
+// compound of multiple expressions.
+func (expr *Expr) groupString() string {
+	if (expr.Op == OpAnd || expr.Op == OpOr) && len(expr.Children) > 1 {
+		return "(" + expr.String() + ")"
+	}
+
+	return expr.String()
+}
@@ -0,0 +1,49 @@
+package todo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpr(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString("Write +X due:2000-01-01\nx Ship +X due:2000-01-01\nPlan +X\nCall Mom due:2000-01-01")
+	require.NoError(t, err)
+
+	expr := ExprAnd(
+		ExprPredicate("project:X", FilterByProject("X")),
+		ExprNot(ExprPredicate("completed", FilterCompleted)),
+		ExprOr(
+			ExprPredicate("due:today", FilterDueToday),
+			ExprPredicate("overdue", FilterOverdue),
+		),
+	)
+
+	require.Equal(t, "project:X AND NOT completed AND (due:today OR overdue)", expr.String())
+
+	filtered := tasklist.Filter(expr.ToPredicate())
+	require.Len(t, filtered, 1)
+	require.Equal(t, "Write +X", filtered[0].Todo)
+
+	// Inspect the tree
+	require.Equal(t, OpAnd, expr.Op)
+	require.Len(t, expr.Children, 3)
+	require.Equal(t, OpNot, expr.Children[1].Op)
+	require.Equal(t, "completed", expr.Children[1].Children[0].Name)
+
+	// Grouping of NOT
+	require.Equal(t, "NOT (a OR b)",
+		ExprNot(ExprOr(ExprPredicate("a", FilterCompleted), ExprPredicate("b", FilterCompleted))).String())
+}
+
+func TestExprOp_String(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Predicate", OpPredicate.String())
+	require.Equal(t, "And", OpAnd.String())
+	require.Equal(t, "Or", OpOr.String())
+	require.Equal(t, "Not", OpNot.String())
+	require.Equal(t, "ExprOp(0)", ExprOp(0).String())
+}
@@ -15,7 +15,9 @@ func FilterNotCompleted(t Task) bool {
 	return !t.Completed
 }
 
-// FilterDueToday filters tasks that are due today.
+// FilterDueToday filters tasks that are due today. "Today" is taken from the
+// SystemClock in the local time zone. Use FilterDueWithin(0, opts...) or
+// CompileQuery with options to use a different Clock.
 func FilterDueToday(t Task) bool {
 	return t.IsDueToday()
 }
@@ -34,3 +36,20 @@ func FilterHasDueDate(t Task) bool {
 func FilterHasPriority(t Task) bool {
 	return t.HasPriority()
 }
+
+// FilterHasThresholdDate filters tasks that have threshold date.
+func FilterHasThresholdDate(t Task) bool {
+	return t.HasThresholdDate()
+}
+
+// FilterThresholdReached filters tasks whose threshold date is today or in the
+// past, and tasks without threshold date. Which hides the future-dated tasks.
+func FilterThresholdReached(t Task) bool {
+	return t.IsThresholdReached()
+}
+
+// FilterActive filters tasks that are not completed and whose threshold date
+// has been reached. See Task.IsActive.
+func FilterActive(t Task) bool {
+	return t.IsActive()
+}
@@ -0,0 +1,677 @@
+package todo
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Layouts and limits of iCalendar (RFC 5545).
+const (
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405"
+	icalUTCLayout      = "20060102T150405Z"
+	icalLineLimit      = 75 // icalLineLimit is the max octets of a line before folding.
+	icalNewLine        = "\r\n"
+	icalProdID         = "-//KEINOS//go-todotxt//EN"
+	icalPropPriority   = "X-TODOTXT-PRIORITY"
+	icalPropTag        = "X-TODOTXT-TAG"
+	icalTagUID         = "uid" // icalTagUID is the tag key to keep the UID of other apps.
+)
+
+// ----------------------------------------------------------------------------
+//  Type: icalProperty
+// ----------------------------------------------------------------------------
+
+// icalProperty is a content line of iCalendar after unfolding. Such as
+// "DUE;VALUE=DATE:20200105".
+type icalProperty struct {
+	Params map[string]string // Params are the parameters with upper-cased names.
+	Name   string            // Name is the upper-cased property name.
+	Value  string            // Value is the raw value, still escaped.
+	Line   int               // Line is the line number where the property starts.
+}
+
+// ----------------------------------------------------------------------------
+//  Public functions
+// ----------------------------------------------------------------------------
+
+// LoadFromICal reads the tasks from the VTODO components of iCalendar (RFC
+// 5545), such as the one written by TaskList.WriteToICal. The other components,
+// such as VEVENT, are ignored. The properties are mapped as follows:
+//
+//   - SUMMARY to the todo text.
+//   - PRIORITY 1 to 9 to the priority "A" to "I". 0 is no priority. The
+//     X-TODOTXT-PRIORITY property takes precedence if any.
+//   - STATUS:COMPLETED or COMPLETED to the completion.
+//   - COMPLETED, CREATED, DUE and DTSTART to CompletedDate, CreatedDate,
+//     DueDate and ThresholdDate respectively. The date-times are converted to
+//     the configured location, and the ones at midnight are taken as dates.
+//   - CATEGORIES to the contexts, or to the projects if prefixed with "+". The
+//     "@" prefix of the contexts is optional.
+//   - X-TODOTXT-TAG to the additional tags, whose value is such as "dep:3". The
+//     "due" tags and the "t" tags of dates are not accepted, since they are
+//     DUE and DTSTART.
+//   - UID to the "uid" tag, unless it is the one derived by WriteToICal. The
+//     characters which a tag value cannot have, such as ":", are escaped as
+//     "%3A", so that WriteToICal writes back the same UID.
+//
+// The SUMMARY is taken as the todo text as it is, even if it looks like the
+// other fields, such as "(B) Call Mom" or "due:tomorrow". The IDs of the tasks
+// are numbered from 1 in order of appearance. It returns an error with the line
+// number at the first invalid property.
+func LoadFromICal(reader io.Reader, opts ...Option) (TaskList, error) {
+	cfg := NewConfig(opts...)
+
+	props, err := readICalProperties(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	tasklist := NewTaskList()
+
+	var (
+		vtodo  []icalProperty
+		depth  int // depth is the nesting level of the components in VTODO.
+		inTodo bool
+		seen   = map[string]int{} // seen counts the tasks of the same UID seed.
+	)
+
+	for _, prop := range props {
+		switch {
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VTODO") && !inTodo:
+			inTodo, vtodo = true, nil
+		case !inTodo:
+			continue
+		case prop.Name == "BEGIN":
+			depth++
+		case prop.Name == "END" && depth > 0:
+			depth--
+		case prop.Name == "END":
+			task, err := icalToTask(vtodo, seen, cfg)
+			if err != nil {
+				return nil, err
+			}
+
+			task.ID = len(tasklist) + 1
+			tasklist = append(tasklist, *task)
+			inTodo = false
+		case depth == 0:
+			vtodo = append(vtodo, prop)
+		}
+	}
+
+	if inTodo {
+		return nil, errors.New("missing END:VTODO at the end of iCalendar")
+	}
+
+	return tasklist, nil
+}
+
+// LoadFromICalPath loads and returns a TaskList from an iCalendar file (most
+// likely with the ".ics" extension). See LoadFromICal for the mapping.
+func LoadFromICalPath(filename string, opts ...Option) (TaskList, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open iCalendar file: "+filename)
+	}
+
+	defer file.Close()
+
+	return LoadFromICal(file, opts...)
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of TaskList
+// ----------------------------------------------------------------------------
+
+// WriteToICal writes the tasks as the VTODO components of an iCalendar (RFC
+// 5545) object, so that the tasks can be imported to calendar applications.
+// See LoadFromICal for the mapping of the properties.
+//
+// Since the PRIORITY of iCalendar has only 9 levels, the priorities after "H"
+// are all 9. The exact priority and the additional tags are kept in the
+// X-TODOTXT-PRIORITY and X-TODOTXT-TAG properties, so that LoadFromICal reads
+// back the same tasks. Non-task entries are not written.
+//
+// The UID of a task is the value of its "uid" tag if any. Otherwise, it is
+// derived from the created date and the todo text, so that completing,
+// prioritizing or renumbering the task does not change it. Set the "uid" tag
+// to keep the UID even if the todo text is edited. The DTSTAMP is the current
+// time of the configured clock.
+func (tasklist *TaskList) WriteToICal(writer io.Writer, opts ...Option) error {
+	cfg := NewConfig(opts...)
+	bufWriter := bufio.NewWriter(writer)
+	stamp := cfg.Clock.Now().UTC().Format(icalUTCLayout)
+
+	writeICalLine(bufWriter, "BEGIN:VCALENDAR")
+	writeICalLine(bufWriter, "VERSION:2.0")
+	writeICalLine(bufWriter, "PRODID:"+icalProdID)
+
+	seen := map[string]int{} // seen counts the tasks of the same UID seed.
+
+	for i := range *tasklist {
+		task := &(*tasklist)[i]
+		if task.NonTask {
+			continue
+		}
+
+		seed := task.icalUIDSeed()
+
+		for _, line := range task.icalLines(stamp, seen[seed], cfg) {
+			writeICalLine(bufWriter, line)
+		}
+
+		seen[seed]++
+	}
+
+	writeICalLine(bufWriter, "END:VCALENDAR")
+
+	return errors.Wrap(bufWriter.Flush(), "failed to write iCalendar")
+}
+
+// WriteToICalPath writes the tasks to an iCalendar file (most likely with the
+// ".ics" extension). See WriteToICal.
+func (tasklist *TaskList) WriteToICalPath(filename string, opts ...Option) error {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, PermReadWrite)
+	if err != nil {
+		return errors.Wrap(err, "failed to create iCalendar file: "+filename)
+	}
+
+	if err := tasklist.WriteToICal(file, opts...); err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	return errors.Wrap(file.Close(), "failed to save iCalendar to the path: "+filename)
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of Task
+// ----------------------------------------------------------------------------
+
+// icalLines returns the content lines of the VTODO component of the task,
+// before folding. The seq is the sequence number of the tasks of the same UID
+// seed. See icalDerivedUID.
+func (task *Task) icalLines(stamp string, seq int, cfg *Config) []string {
+	lines := []string{
+		"BEGIN:VTODO",
+		"UID:" + task.icalUID(seq),
+		"DTSTAMP:" + stamp,
+	}
+
+	if task.HasCreatedDate() {
+		lines = append(lines, "CREATED:"+formatICalUTC(task.CreatedDate, cfg))
+	}
+
+	lines = append(lines, "SUMMARY:"+escapeICalText(task.Todo))
+
+	if task.HasPriority() {
+		lines = append(lines,
+			"PRIORITY:"+strconv.Itoa(min(int(task.Priority[0]-'A')+1, 9)),
+			icalPropPriority+":"+task.Priority,
+		)
+	}
+
+	if task.Completed {
+		lines = append(lines, "STATUS:COMPLETED")
+
+		if task.HasCompletedDate() {
+			lines = append(lines, "COMPLETED:"+formatICalUTC(task.CompletedDate, cfg))
+		}
+	} else {
+		lines = append(lines, "STATUS:NEEDS-ACTION")
+	}
+
+	if task.HasThresholdDate() {
+		lines = append(lines, "DTSTART"+formatICalDate(task.ThresholdDate))
+	}
+
+	if task.HasDueDate() {
+		lines = append(lines, "DUE"+formatICalDate(task.DueDate))
+	}
+
+	categories := make([]string, 0, len(task.Contexts)+len(task.Projects))
+
+	for _, context := range task.Contexts {
+		categories = append(categories, escapeICalText("@"+context))
+	}
+
+	for _, project := range task.Projects {
+		categories = append(categories, escapeICalText("+"+project))
+	}
+
+	if len(categories) > 0 {
+		lines = append(lines, "CATEGORIES:"+strings.Join(categories, ","))
+	}
+
+	for _, tag := range task.AdditionalTags {
+		lines = append(lines, icalPropTag+":"+escapeICalText(tag.String()))
+	}
+
+	return append(lines, "END:VTODO")
+}
+
+// icalDerivedUID returns the UID derived from the created date and the todo
+// text of the task. The seq is the sequence number of the tasks of the same
+// seed in the list, so that the identical tasks are told apart by their order.
+func (task *Task) icalDerivedUID(seq int) string {
+	sum := sha256.Sum256([]byte(task.icalUIDSeed() + "\n" + strconv.Itoa(seq)))
+
+	return hex.EncodeToString(sum[:16]) + "@go-todotxt"
+}
+
+// icalUID returns the UID of the task. It is the value of the "uid" tag if any,
+// otherwise the derived one. See icalDerivedUID.
+func (task *Task) icalUID(seq int) string {
+	if uid, ok := task.AdditionalTags.Get(icalTagUID); ok {
+		return unescapeICalUID(uid)
+	}
+
+	return task.icalDerivedUID(seq)
+}
+
+// icalUIDSeed returns the content of the task to derive the UID from. It does
+// not depend on the ID, which changes on archiving and reloading.
+func (task *Task) icalUIDSeed() string {
+	seed := task.Todo
+	if task.HasCreatedDate() {
+		seed = task.CreatedDate.Format(icalDateLayout) + " " + seed
+	}
+
+	return seed
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// addICalTag adds the additional tag of the X-TODOTXT-TAG value. Such as
+// "dep:3". The value is validated as the setters of the tags do.
+func addICalTag(task *Task, text string, cfg *Config) error {
+	key, value, _ := strings.Cut(text, ":")
+	if err := checkTagText(key, value); err != nil {
+		return err
+	}
+
+	if key == "due" {
+		return errors.New("due date must be DUE")
+	}
+
+	if _, err := cfg.parseDate(value); key == "t" && err == nil {
+		return errors.New("threshold date must be DTSTART")
+	}
+
+	if err := validateTag(key, value); err != nil {
+		return err
+	}
+
+	task.AdditionalTags.Add(key, value)
+
+	return nil
+}
+
+// escapeICalText escapes the value of the TEXT type of iCalendar.
+func escapeICalText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+// escapeICalUID escapes the characters of the UID which a tag value cannot have.
+// Such as "urn:uuid:1234" to "urn%3Auuid%3A1234".
+func escapeICalUID(uid string) string {
+	return strings.NewReplacer(
+		"%", "%25",
+		":", "%3A",
+		" ", "%20",
+		"\t", "%09",
+	).Replace(uid)
+}
+
+// formatICalDate returns the parameters and the value of the DATE or the
+// floating DATE-TIME type of the date. Such as ";VALUE=DATE:20200105".
+func formatICalDate(date Date) string {
+	if date.HasTime() {
+		return ":" + date.Format(icalDateTimeLayout)
+	}
+
+	return ";VALUE=DATE:" + date.Format(icalDateLayout)
+}
+
+// formatICalUTC returns the date in the UTC DATE-TIME type of iCalendar. The
+// dates without time of day are the midnight in the configured location.
+func formatICalUTC(date Date, cfg *Config) string {
+	return date.Time(cfg.Location).UTC().Format(icalUTCLayout)
+}
+
+// icalToTask returns the task of the properties of a VTODO component. The task
+// is built from the properties as they are, so that the SUMMARY is never taken
+// as the other fields. Such as "x ray appointment", which is not completed.
+//
+// The UID is kept as the "uid" tag unless it is the derived one, whose sequence
+// number is counted in the given seen map. See icalDerivedUID.
+//
+//nolint:cyclop,funlen,gocognit // complexity is high but it is a simple switch of properties
+func icalToTask(props []icalProperty, seen map[string]int, cfg *Config) (*Task, error) {
+	task := new(Task)
+
+	var priorityLevel, exactPriority, uid string
+
+	for _, prop := range props {
+		var err error
+
+		switch prop.Name {
+		case "UID":
+			uid = strings.TrimSpace(unescapeICalText(prop.Value))
+		case "SUMMARY":
+			task.Todo = strings.Join(strings.Fields(unescapeICalText(prop.Value)), " ")
+		case "PRIORITY":
+			priorityLevel, err = parseICalPriority(prop.Value)
+		case icalPropPriority:
+			exactPriority = strings.ToUpper(prop.Value)
+			if len(exactPriority) != 1 || exactPriority[0] < 'A' || exactPriority[0] > 'Z' {
+				err = errors.New("expected A to Z")
+			}
+		case "STATUS":
+			task.Completed = task.Completed || strings.EqualFold(prop.Value, "COMPLETED")
+		case "COMPLETED":
+			task.CompletedDate, err = parseICalDate(prop, cfg)
+			task.Completed = true
+		case "CREATED":
+			task.CreatedDate, err = parseICalDate(prop, cfg)
+		case "DTSTART":
+			task.ThresholdDate, err = parseICalDate(prop, cfg)
+		case "DUE":
+			task.DueDate, err = parseICalDate(prop, cfg)
+		case "CATEGORIES":
+			for _, category := range splitICalList(prop.Value) {
+				category = strings.Join(strings.Fields(unescapeICalText(category)), "-")
+
+				switch {
+				case isEmpty(category):
+				case strings.HasPrefix(category, "+"):
+					task.Projects = append(task.Projects, strings.TrimPrefix(category, "+"))
+				default:
+					task.Contexts = append(task.Contexts, strings.TrimPrefix(category, "@"))
+				}
+			}
+		case icalPropTag:
+			err = addICalTag(task, unescapeICalText(prop.Value), cfg)
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d: invalid %s %q", prop.Line, prop.Name, prop.Value)
+		}
+	}
+
+	task.Priority = priorityLevel
+	if isNotEmpty(exactPriority) {
+		task.Priority = exactPriority
+	}
+
+	// The contexts and projects in the todo text are kept as well
+	task.Contexts = mergeValues(task.Contexts, getSlice(task.Todo, contextRx))
+	task.Projects = mergeValues(task.Projects, getSlice(task.Todo, projectRx))
+
+	// Keep the UID of other apps, unless it is written by the "uid" tag
+	seed := task.icalUIDSeed()
+	if isNotEmpty(uid) && !task.AdditionalTags.Has(icalTagUID) && uid != task.icalDerivedUID(seen[seed]) {
+		task.AdditionalTags.Add(icalTagUID, escapeICalUID(uid))
+	}
+
+	seen[seed]++
+
+	// Keep the time of day as it is
+	lineCfg := *cfg
+	lineCfg.DateTime = cfg.DateTime || task.hasTimeOfDay()
+	task.Original = task.stringWith(&lineCfg)
+
+	return task, nil
+}
+
+// parseICalDate parses the value of the DATE or DATE-TIME type of the property.
+// The date-times in UTC or with TZID are converted to the configured location,
+// and the floating ones are taken as they are.
+func parseICalDate(prop icalProperty, cfg *Config) (Date, error) {
+	if strings.EqualFold(prop.Params["VALUE"], "DATE") || len(prop.Value) == len(icalDateLayout) {
+		parsed, err := time.Parse(icalDateLayout, prop.Value)
+
+		return DateOf(parsed), errors.Wrap(err, "failed to parse date")
+	}
+
+	loc := cfg.Location
+
+	if tzid := prop.Params["TZID"]; isNotEmpty(tzid) {
+		tzLoc, err := time.LoadLocation(tzid)
+		if err != nil {
+			return Date{}, errors.Wrap(err, "failed to load time zone")
+		}
+
+		loc = tzLoc
+	}
+
+	layout := icalDateTimeLayout
+	if strings.HasSuffix(prop.Value, "Z") {
+		layout, loc = icalUTCLayout, time.UTC
+	}
+
+	parsed, err := time.ParseInLocation(layout, prop.Value, loc)
+	if err != nil {
+		return Date{}, errors.Wrap(err, "failed to parse date-time")
+	}
+
+	parsed = parsed.In(cfg.Location)
+	if hour, minute, sec := parsed.Clock(); hour == 0 && minute == 0 && sec == 0 {
+		return DateOf(parsed), nil
+	}
+
+	return DateTimeOf(parsed), nil
+}
+
+// parseICalPriority returns the priority of the PRIORITY value of 1 to 9 as "A"
+// to "I". It returns an empty string for 0, which is undefined.
+func parseICalPriority(value string) (string, error) {
+	level, err := strconv.Atoi(value)
+	if err != nil || level < 0 || level > 9 {
+		return emptyStr, errors.New("expected 0 to 9")
+	}
+
+	if level == 0 {
+		return emptyStr, nil
+	}
+
+	return string(rune('A' + level - 1)), nil
+}
+
+// parseICalProperty parses a content line after unfolding. Such as
+// "DUE;TZID=Europe/Paris:20200105T140000".
+func parseICalProperty(text string, line int) (icalProperty, error) {
+	prop := icalProperty{Params: map[string]string{}, Name: emptyStr, Value: emptyStr, Line: line}
+
+	// The value starts at the first colon outside of the quoted parameters
+	inQuote := false
+	colon := -1
+
+	for i, char := range text {
+		if char == '"' {
+			inQuote = !inQuote
+		} else if char == ':' && !inQuote {
+			colon = i
+
+			break
+		}
+	}
+
+	if colon < 0 {
+		return prop, errors.Errorf("line %d: invalid content line %q, missing colon", line, text)
+	}
+
+	prop.Value = text[colon+1:]
+	fields := strings.Split(text[:colon], ";")
+	prop.Name = strings.ToUpper(fields[0])
+
+	for _, param := range fields[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		prop.Params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	return prop, nil
+}
+
+// readICalProperties reads and unfolds the content lines of iCalendar. The
+// lines are read as a whole regardless of the length.
+func readICalProperties(reader io.Reader) ([]icalProperty, error) {
+	bufReader := bufio.NewReader(reader)
+
+	var (
+		props   []icalProperty
+		current strings.Builder
+		start   int
+	)
+
+	flush := func() error {
+		if current.Len() == 0 {
+			return nil
+		}
+
+		prop, err := parseICalProperty(current.String(), start)
+		if err != nil {
+			return err
+		}
+
+		props = append(props, prop)
+		current.Reset()
+
+		return nil
+	}
+
+	for line := 1; ; line++ {
+		text, readErr := bufReader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return nil, errors.Wrap(readErr, "failed to read iCalendar")
+		}
+
+		if readErr != nil && isEmpty(text) {
+			break
+		}
+
+		text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+
+		// Folded lines start with a space or a tab
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			current.WriteString(text[1:])
+
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return nil, err
+		}
+
+		current.WriteString(text)
+		start = line
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return props, nil
+}
+
+// splitICalList splits the escaped value of a list by the unescaped commas.
+func splitICalList(value string) []string {
+	var (
+		items   []string
+		current strings.Builder
+		escaped bool
+	)
+
+	for _, char := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == ',':
+			items = append(items, current.String())
+			current.Reset()
+
+			continue
+		}
+
+		current.WriteRune(char)
+	}
+
+	return append(items, current.String())
+}
+
+// unescapeICalText unescapes the value of the TEXT type of iCalendar.
+func unescapeICalText(text string) string {
+	var (
+		result  strings.Builder
+		escaped bool
+	)
+
+	for _, char := range text {
+		switch {
+		case escaped && (char == 'n' || char == 'N'):
+			result.WriteRune('\n')
+		case escaped:
+			result.WriteRune(char)
+		case char == '\\':
+			escaped = true
+
+			continue
+		default:
+			result.WriteRune(char)
+		}
+
+		escaped = false
+	}
+
+	return result.String()
+}
+
+// unescapeICalUID unescapes the UID escaped by escapeICalUID.
+func unescapeICalUID(uid string) string {
+	return strings.NewReplacer(
+		"%25", "%",
+		"%3A", ":",
+		"%20", " ",
+		"%09", "\t",
+	).Replace(uid)
+}
+
+// writeICalLine writes the content line, folding it at 75 octets without
+// breaking the UTF-8 characters. The write errors are reported on Flush.
+func writeICalLine(writer *bufio.Writer, line string) {
+	limit := icalLineLimit
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		_, _ = writer.WriteString(line[:cut] + icalNewLine + " ")
+		line = line[cut:]
+		limit = icalLineLimit - 1 // the leading space counts
+	}
+
+	_, _ = writer.WriteString(line + icalNewLine)
+}
@@ -0,0 +1,279 @@
+package todo
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskList_WriteToICal(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString(
+		"(A) 2020-01-01 Call Mom, then Dad @phone +family due:2020-01-05 t:2020-01-03 dep:3 est:30 dep:7\n"+
+			"x 2020-01-04 (K) Pick up milk\n",
+		WithLocation(time.UTC),
+	)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, tasklist.WriteToICal(&buf, WithClock(FixedClock(now)), WithLocation(time.UTC)))
+
+	output := buf.String()
+	require.True(t, strings.HasPrefix(output, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:"))
+	require.True(t, strings.HasSuffix(output, "END:VTODO\r\nEND:VCALENDAR\r\n"))
+	require.Equal(t, 2, strings.Count(output, "BEGIN:VTODO\r\n"))
+
+	for _, expect := range []string{
+		"DTSTAMP:20200102T030405Z\r\n",
+		"CREATED:20200101T000000Z\r\n",
+		"SUMMARY:Call Mom\\, then Dad @phone +family\r\n",
+		"PRIORITY:1\r\nX-TODOTXT-PRIORITY:A\r\n",
+		"STATUS:NEEDS-ACTION\r\n",
+		"DTSTART;VALUE=DATE:20200103\r\n",
+		"DUE;VALUE=DATE:20200105\r\n",
+		"CATEGORIES:@phone,+family\r\n",
+		"X-TODOTXT-TAG:dep:3\r\nX-TODOTXT-TAG:est:30\r\nX-TODOTXT-TAG:dep:7\r\n",
+		"PRIORITY:9\r\nX-TODOTXT-PRIORITY:K\r\n",
+		"STATUS:COMPLETED\r\nCOMPLETED:20200104T000000Z\r\n",
+	} {
+		require.Contains(t, output, expect)
+	}
+
+	// Same tasks have the same UID
+	var again bytes.Buffer
+
+	require.NoError(t, tasklist.WriteToICal(&again, WithClock(FixedClock(now)), WithLocation(time.UTC)))
+	require.Equal(t, output, again.String())
+}
+
+func TestTaskList_WriteToICal_folding(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString(strings.Repeat("日本語のタスク ", 10))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, tasklist.WriteToICal(&buf))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), 75, "line: %q", line)
+	}
+
+	decoded, err := LoadFromICal(&buf)
+	require.NoError(t, err)
+	require.Equal(t, tasklist[0].Todo, decoded[0].Todo, "folded lines should be unfolded")
+}
+
+func TestLoadFromICal_round_trip(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromPath(testInputTask)
+	require.NoError(t, err)
+
+	pathFile := filepath.Join(t.TempDir(), "todo.ics")
+	require.NoError(t, tasklist.WriteToICalPath(pathFile))
+
+	decoded, err := LoadFromICalPath(pathFile)
+	require.NoError(t, err)
+	require.Len(t, decoded, len(tasklist))
+
+	for i := range tasklist {
+		require.Equal(t, tasklist[i].ID, decoded[i].ID)
+		require.Equal(t, tasklist[i].String(), decoded[i].String())
+		require.Equal(t, tasklist[i].Completed, decoded[i].Completed)
+		require.Equal(t, nilIfEmpty(tasklist[i].AdditionalTags), decoded[i].AdditionalTags)
+	}
+
+	_, err = LoadFromICalPath(filepath.Join(t.TempDir(), "missing.ics"))
+	require.Error(t, err)
+}
+
+func TestLoadFromICal_from_other_apps(t *testing.T) {
+	t.Parallel()
+
+	const input = "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Not a task\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:1@example.com\r\n" +
+		"summary:Write report\\; draft\r\n" +
+		"PRIORITY:2\r\n" +
+		"CREATED:20200301T150000Z\r\n" +
+		"DUE;TZID=\"Asia/Tokyo\":20200308T140000\r\n" +
+		"CATEGORIES:Work,+Reports\r\n" +
+		"CATEGORIES:Home Office\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"SUMMARY:Alarm\r\n" +
+		"END:VALARM\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"SUMMARY:Send\r\n" +
+		"  report\r\n" +
+		"PRIORITY:0\r\n" +
+		"COMPLETED:20200309T100000Z\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	tasklist, err := LoadFromICal(strings.NewReader(input), WithLocation(tokyo))
+	require.NoError(t, err)
+	require.Len(t, tasklist, 2)
+
+	task := tasklist[0]
+	require.Equal(t, 1, task.ID)
+	require.Equal(t, "B", task.Priority)
+	require.Equal(t, NewDate(2020, 3, 2), task.CreatedDate, "midnight in the location should be a date")
+	require.Equal(t, NewDate(2020, 3, 8).WithTime(14, 0, 0), task.DueDate)
+	require.Equal(t, []string{"Home-Office", "Work"}, task.Contexts)
+	require.Equal(t, []string{"Reports"}, task.Projects)
+	require.Equal(t,
+		"(B) 2020-03-02 Write report; draft @Home-Office @Work +Reports uid:1@example.com due:2020-03-08",
+		task.String(), "UID of other apps should be kept as a tag")
+
+	require.Equal(t, "Send report", tasklist[1].Todo)
+	require.Empty(t, tasklist[1].Priority)
+	require.True(t, tasklist[1].Completed)
+	require.Equal(t, NewDate(2020, 3, 9).WithTime(19, 0, 0), tasklist[1].CompletedDate)
+}
+
+func TestTaskList_WriteToICal_uid(t *testing.T) {
+	t.Parallel()
+
+	uidsOf := func(tasklist TaskList) []string {
+		var buf bytes.Buffer
+
+		require.NoError(t, tasklist.WriteToICal(&buf))
+
+		uids := []string{}
+
+		for _, line := range strings.Split(buf.String(), "\r\n") {
+			if uid, found := strings.CutPrefix(line, "UID:"); found {
+				uids = append(uids, uid)
+			}
+		}
+
+		return uids
+	}
+	uidOf := func(tasklist TaskList) string {
+		return uidsOf(tasklist)[0]
+	}
+
+	tasklist, err := LoadFromString("Pay rent\n2020-01-01 Call Mom due:2020-01-05\nCall Mom")
+	require.NoError(t, err)
+
+	uids := uidsOf(tasklist)
+	require.Len(t, uids, 3)
+	require.NotEqual(t, uids[1], uids[2], "created date should be a part of the UID")
+
+	// Completing, prioritizing and renumbering the tasks should not change the UIDs
+	tasklist[0].Complete()
+	tasklist[1].Complete()
+	tasklist[1].Priority = "A"
+	tasklist[1].DueDate = NewDate(2020, 1, 6)
+	tasklist[2].ID = 5
+
+	require.Equal(t, uids, uidsOf(tasklist))
+
+	done := tasklist.Archive(ArchiveOptions{})
+	require.Equal(t, uids[2:], uidsOf(tasklist), "archiving should not change the UIDs")
+	require.Equal(t, uids[:2], uidsOf(done))
+
+	// Editing the todo text changes the derived UID, but not the one of the tag
+	tasklist[0].Todo = "Call Mom and Dad"
+	require.NotEqual(t, uids[2], uidOf(tasklist))
+
+	require.NoError(t, tasklist[0].SetTag("uid", "1234"))
+	require.Equal(t, "1234", uidOf(tasklist))
+
+	// Identical tasks are told apart by their order
+	tasklist, err = LoadFromString("Water plants\nWater plants")
+	require.NoError(t, err)
+
+	uids = uidsOf(tasklist)
+	require.NotEqual(t, uids[0], uids[1], "identical tasks should have their own UIDs")
+
+	// The UID of other apps should be kept on the round trip
+	const input = "BEGIN:VTODO\r\nUID:urn:uuid:1234 5678%\r\nSUMMARY:Call Mom\r\nEND:VTODO\r\n"
+
+	imported, err := LoadFromICal(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, "Call Mom uid:urn%3Auuid%3A1234%205678%25", imported[0].String())
+	require.Equal(t, "urn:uuid:1234 5678%", uidOf(imported))
+
+	// The derived UID should not be kept as a tag
+	tasklist, err = LoadFromString("Call Mom")
+	require.NoError(t, err)
+
+	exported := strings.Replace(input, "urn:uuid:1234 5678%", uidOf(tasklist), 1)
+
+	imported, err = LoadFromICal(strings.NewReader(exported))
+	require.NoError(t, err)
+	require.False(t, imported[0].AdditionalTags.Has("uid"))
+}
+
+func TestLoadFromICal_summary_as_it_is(t *testing.T) {
+	t.Parallel()
+
+	for _, summary := range []string{
+		"x ray appointment", "(B) call", "2020-01-01 Call Mom", "Call Mom due:foo t:2020-01-01",
+	} {
+		input := "BEGIN:VTODO\r\nSUMMARY:" + summary + "\r\nEND:VTODO\r\n"
+
+		tasklist, err := LoadFromICal(strings.NewReader(input))
+		require.NoError(t, err, "summary: %q", summary)
+
+		task := tasklist[0]
+		require.Equal(t, summary, task.Todo, "summary should be the todo text as it is")
+		require.False(t, task.Completed)
+		require.Empty(t, task.Priority)
+		require.True(t, task.CreatedDate.IsZero())
+		require.True(t, task.DueDate.IsZero())
+		require.True(t, task.ThresholdDate.IsZero())
+		require.Empty(t, task.AdditionalTags)
+	}
+}
+
+func TestLoadFromICal_long_line(t *testing.T) {
+	t.Parallel()
+
+	todo := strings.Repeat("a", 100*1024)
+	input := "BEGIN:VTODO\nSUMMARY:" + todo + "\nEND:VTODO"
+
+	tasklist, err := LoadFromICal(strings.NewReader(input))
+	require.NoError(t, err, "lines longer than 64 KiB should be read")
+	require.Len(t, tasklist, 1)
+	require.Equal(t, todo, tasklist[0].Todo)
+}
+
+func TestLoadFromICal_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		expect string
+	}{
+		{input: "BEGIN:VTODO\nPRIORITY:10\nEND:VTODO", expect: `line 2: invalid PRIORITY "10"`},
+		{input: "BEGIN:VTODO\nDUE:tomorrow\nEND:VTODO", expect: `line 2: invalid DUE "tomorrow"`},
+		{input: "BEGIN:VTODO\nX-TODOTXT-PRIORITY:AB\nEND:VTODO", expect: `invalid X-TODOTXT-PRIORITY`},
+		{input: "BEGIN:VTODO\nX-TODOTXT-TAG:dep\nEND:VTODO", expect: `invalid X-TODOTXT-TAG`},
+		{input: "BEGIN:VTODO\nX-TODOTXT-TAG:due:2020-01-05\nEND:VTODO", expect: `due date must be DUE`},
+		{input: "BEGIN:VTODO\nX-TODOTXT-TAG:t:2020-01-05\nEND:VTODO", expect: `threshold date must be DTSTART`},
+		{input: "BEGIN:VTODO\nDUE;TZID=Nowhere/City:20200308T140000\nEND:VTODO", expect: `invalid DUE`},
+		{input: "BEGIN:VTODO\nSUMMARY Call Mom\nEND:VTODO", expect: `line 2: invalid content line`},
+		{input: "BEGIN:VTODO\nSUMMARY:Call Mom", expect: `missing END:VTODO`},
+	} {
+		_, err := LoadFromICal(strings.NewReader(test.input))
+		require.ErrorContains(t, err, test.expect, "input: %q", test.input)
+	}
+}
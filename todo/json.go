@@ -0,0 +1,309 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: taskJSON
+// ----------------------------------------------------------------------------
+
+// taskJSON is the JSON representation of a Task. See Task.MarshalJSON for the
+// schema.
+type taskJSON struct {
+	CompletedDate Date          `json:"completed_date"`
+	CreatedDate   Date          `json:"created_date"`
+	DueDate       Date          `json:"due_date"`
+	ThresholdDate Date          `json:"threshold_date"`
+	Original      string        `json:"original"`
+	Todo          string        `json:"todo"`
+	Priority      string        `json:"priority"`
+	Contexts      []string      `json:"contexts"`
+	Projects      []string      `json:"projects"`
+	Tags          jsonTags      `json:"tags"`
+	Segments      []segmentJSON `json:"segments,omitempty"`
+	ID            int           `json:"id"`
+	Completed     bool          `json:"completed"`
+	NonTask       bool          `json:"non_task"`
+}
+
+// segmentJSON is the JSON representation of a TaskSegment.
+type segmentJSON struct {
+	Type      string   `json:"type"`
+	Display   string   `json:"display"`
+	Originals []string `json:"originals"`
+}
+
+// jsonTags is the JSON representation of Tags. It is an object of the keys and
+// their values in order of appearance. Such as:
+//
+//	{"dep": ["3", "7"], "est": ["30"]}
+type jsonTags Tags
+
+// ----------------------------------------------------------------------------
+//  Methods of Task
+// ----------------------------------------------------------------------------
+
+// MarshalJSON implements the json.Marshaler interface. The schema is:
+//
+//	{
+//	  "id": 1,
+//	  "original": "(A) 2020-01-01 Call Mom @phone due:2020-01-05 dep:3 dep:7",
+//	  "todo": "Call Mom @phone",
+//	  "priority": "A",
+//	  "completed": false,
+//	  "completed_date": null,
+//	  "created_date": "2020-01-01",
+//	  "due_date": "2020-01-05",
+//	  "threshold_date": null,
+//	  "contexts": ["phone"],
+//	  "projects": [],
+//	  "tags": {"dep": ["3", "7"]},
+//	  "non_task": false,
+//	  "segments": [{"type": "Priority", "display": "(A)", "originals": ["A"]}, ...]
+//	}
+//
+// The unset dates are null, and the dates with time of day are such as
+// "2020-01-05T14:00". The "priority" is an empty string if unset. The "tags"
+// keep the order of appearance of the keys. The "segments" are the result of
+// Task.Segments, and they are ignored on unmarshaling.
+func (task Task) MarshalJSON() ([]byte, error) {
+	data := taskJSON{
+		ID:            task.ID,
+		Original:      task.Original,
+		Todo:          task.Todo,
+		Priority:      task.Priority,
+		Completed:     task.Completed,
+		CompletedDate: task.CompletedDate,
+		CreatedDate:   task.CreatedDate,
+		DueDate:       task.DueDate,
+		ThresholdDate: task.ThresholdDate,
+		Contexts:      nonNilStrings(task.Contexts),
+		Projects:      nonNilStrings(task.Projects),
+		Tags:          jsonTags(task.AdditionalTags),
+		NonTask:       task.NonTask,
+		Segments:      nil,
+	}
+
+	if !task.NonTask {
+		data.Segments = []segmentJSON{}
+
+		for _, seg := range task.Segments(WithDateTime(task.hasTimeOfDay())) {
+			data.Segments = append(data.Segments, segmentJSON{
+				Type:      seg.Type.String(),
+				Display:   seg.Display,
+				Originals: seg.Originals,
+			})
+		}
+	}
+
+	result, err := json.Marshal(data)
+
+	return result, errors.Wrap(err, "failed to marshal task")
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts the JSON
+// as Task.MarshalJSON returns. The missing fields are zero values.
+//
+// If the "original" line can be parsed, the task keeps it to serialize the
+// task losslessly, as ParseTask does. The other fields take precedence over
+// the parsed ones, so that the edited fields are applied. See Config.PreserveOriginal.
+func (task *Task) UnmarshalJSON(data []byte) error {
+	var decoded taskJSON
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return errors.Wrap(err, "failed to unmarshal task")
+	}
+
+	result := Task{
+		ID:             decoded.ID,
+		Original:       decoded.Original,
+		Todo:           decoded.Todo,
+		Priority:       decoded.Priority,
+		Completed:      decoded.Completed,
+		CompletedDate:  decoded.CompletedDate,
+		CreatedDate:    decoded.CreatedDate,
+		DueDate:        decoded.DueDate,
+		ThresholdDate:  decoded.ThresholdDate,
+		Contexts:       nilIfEmpty(decoded.Contexts),
+		Projects:       nilIfEmpty(decoded.Projects),
+		AdditionalTags: Tags(nilIfEmpty(decoded.Tags)),
+		NonTask:        decoded.NonTask,
+		origin:         nil,
+	}
+
+	if !result.NonTask && isNotEmpty(result.Original) {
+		if parsed, err := ParseTask(result.Original, WithDateTime(result.hasTimeOfDay())); err == nil {
+			result.origin = parsed.origin
+
+			// Keep the parsed values if they are the same, so that the task is
+			// not taken as modified. Such as the order of the tags.
+			result.Contexts = sameOrParsed(result.Contexts, parsed.Contexts)
+			result.Projects = sameOrParsed(result.Projects, parsed.Projects)
+
+			if slices.Equal(result.AdditionalTags, parsed.AdditionalTags.grouped()) {
+				result.AdditionalTags = parsed.AdditionalTags
+			}
+		}
+	}
+
+	*task = result
+
+	return nil
+}
+
+// hasTimeOfDay returns true if any of the dates of the task has a time of day.
+func (task *Task) hasTimeOfDay() bool {
+	return task.CompletedDate.HasTime() || task.CreatedDate.HasTime() ||
+		task.DueDate.HasTime() || task.ThresholdDate.HasTime()
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of TaskList
+// ----------------------------------------------------------------------------
+
+// MarshalJSON implements the json.Marshaler interface. The TaskList is an array
+// of the tasks in the schema of Task.MarshalJSON. An empty TaskList is an empty
+// array, not null.
+func (tasklist TaskList) MarshalJSON() ([]byte, error) {
+	tasks := []Task(tasklist)
+	if tasks == nil {
+		tasks = []Task{}
+	}
+
+	result, err := json.Marshal(tasks)
+
+	return result, errors.Wrap(err, "failed to marshal tasklist")
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts an array
+// of the tasks in the schema of Task.MarshalJSON. The IDs of the tasks are kept
+// as they are.
+func (tasklist *TaskList) UnmarshalJSON(data []byte) error {
+	var tasks []Task
+
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return errors.Wrap(err, "failed to unmarshal tasklist")
+	}
+
+	*tasklist = TaskList(tasks)
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of jsonTags
+// ----------------------------------------------------------------------------
+
+// MarshalJSON implements the json.Marshaler interface.
+func (tags jsonTags) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, key := range Tags(tags).Keys() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal tag key")
+		}
+
+		valuesJSON, err := json.Marshal(Tags(tags).Values(key))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal tag values")
+		}
+
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+		buf.Write(valuesJSON)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The order of the
+// keys is kept.
+func (tags *jsonTags) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return errors.Wrap(err, "failed to read tags")
+	}
+
+	if token == nil {
+		*tags = nil
+
+		return nil
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return errors.Errorf("invalid tags %s, expected an object of arrays", data)
+	}
+
+	result := jsonTags{}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return errors.Wrap(err, "failed to read tag key")
+		}
+
+		key, _ := token.(string) // keys of an object are always strings
+
+		var values []string
+
+		if err := decoder.Decode(&values); err != nil {
+			return errors.Wrapf(err, "failed to read values of tag %q", key)
+		}
+
+		for _, value := range values {
+			result = append(result, Tag{Key: key, Value: value})
+		}
+	}
+
+	*tags = result
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// nilIfEmpty returns nil if the slice is empty, as the parsed tasks have.
+func nilIfEmpty[S ~[]E, E any](slice S) S {
+	if len(slice) == 0 {
+		return nil
+	}
+
+	return slice
+}
+
+// nonNilStrings returns an empty slice instead of nil, to marshal it as an
+// empty array instead of null.
+func nonNilStrings(slice []string) []string {
+	if slice == nil {
+		return []string{}
+	}
+
+	return slice
+}
+
+// sameOrParsed returns the parsed slice if it is the same as the decoded one.
+func sameOrParsed(decoded, parsed []string) []string {
+	if slices.Equal(decoded, parsed) {
+		return parsed
+	}
+
+	return decoded
+}
@@ -0,0 +1,130 @@
+package todo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTask_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("(A) 2020-01-01 Call Mom @phone due:2020-01-05 dep:3 est:30 dep:7")
+	require.NoError(t, err)
+
+	task.ID = 1
+
+	data, err := json.Marshal(task)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	delete(decoded, "segments")
+
+	expectJSON := `{
+		"id": 1,
+		"original": "(A) 2020-01-01 Call Mom @phone due:2020-01-05 dep:3 est:30 dep:7",
+		"todo": "Call Mom @phone",
+		"priority": "A",
+		"completed": false,
+		"completed_date": null,
+		"created_date": "2020-01-01",
+		"due_date": "2020-01-05",
+		"threshold_date": null,
+		"contexts": ["phone"],
+		"projects": [],
+		"tags": {"dep": ["3", "7"], "est": ["30"]},
+		"non_task": false
+	}`
+
+	actualJSON, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	require.JSONEq(t, expectJSON, string(actualJSON))
+
+	// Tags keep the order of appearance of the keys
+	require.Contains(t, string(data), `"tags":{"dep":["3","7"],"est":["30"]}`)
+	require.Contains(t, string(data), `{"type":"Priority","display":"(A)","originals":["A"]}`)
+}
+
+func TestTask_UnmarshalJSON_round_trip(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromPath(testInputTask)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(tasklist)
+	require.NoError(t, err)
+
+	var decoded TaskList
+
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, tasklist, decoded)
+
+	for i := range tasklist {
+		require.Equal(t, tasklist[i].String(), decoded[i].String())
+	}
+}
+
+func TestTask_UnmarshalJSON_edited(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+		"id": 3,
+		"original": "Call Mom dep:3 est:30 dep:7 due:2020-01-05",
+		"todo": "Call Mom",
+		"priority": "B",
+		"due_date": "2020-01-06T14:00",
+		"tags": {"dep": ["3", "7"], "est": ["30"]}
+	}`)
+
+	var task Task
+
+	require.NoError(t, json.Unmarshal(data, &task))
+	require.Equal(t, 3, task.ID)
+	require.Equal(t, "B", task.Priority)
+	require.Equal(t, NewDate(2020, 1, 6).WithTime(14, 0, 0), task.DueDate)
+	require.Equal(t, Tags{{"dep", "3"}, {"est", "30"}, {"dep", "7"}}, task.AdditionalTags,
+		"the order of the original line should be kept if the tags are the same")
+	require.True(t, task.CreatedDate.IsZero(), "missing date should be unset")
+
+	// Edited fields are applied on the original line
+	require.Equal(t, "(B) Call Mom dep:3 est:30 dep:7 due:2020-01-06T14:00",
+		task.StringWith(WithPreserveOriginal(true), WithDateTime(true)))
+}
+
+func TestTaskList_JSON_empty_and_non_task(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(TaskList(nil))
+	require.NoError(t, err)
+	require.Equal(t, "[]", string(data))
+
+	tasklist, err := LoadFromString("# comment\nCall Mom", WithKeepNonTaskLines(true))
+	require.NoError(t, err)
+
+	data, err = json.Marshal(tasklist)
+	require.NoError(t, err)
+
+	var decoded TaskList
+
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, tasklist, decoded)
+	require.True(t, decoded[0].NonTask)
+}
+
+func TestTask_UnmarshalJSON_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		`[]`,
+		`{"due_date": "tomorrow"}`,
+		`{"tags": ["dep:3"]}`,
+		`{"tags": {"dep": "3"}}`,
+	} {
+		var task Task
+
+		require.Error(t, json.Unmarshal([]byte(input), &task), "input: %s", input)
+	}
+}
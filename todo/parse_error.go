@@ -0,0 +1,110 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: ParseError
+// ----------------------------------------------------------------------------
+
+// ParseError represents an error occurred while parsing a task line. Such as an
+// invalid date in the created date position or in the 'due:' tag.
+//
+// Use errors.As to retrieve it from the errors returned by ParseTask and the
+// Load* functions.
+type ParseError struct {
+	Err     error           // Err is the underlying error.
+	Text    string          // Text is the raw text of the line.
+	Value   string          // Value is the raw text of the segment that failed to parse.
+	Line    int             // Line is the line number in the file starting from 1. Zero if not loaded from a file.
+	Offset  int             // Offset is the byte offset of the segment in the line.
+	Segment TaskSegmentType // Segment is the type of the segment that failed to parse.
+}
+
+// Error returns the error message with the position of the segment.
+//
+// For example:
+//
+//	"line 3, offset 20: invalid DueDate "2020-13-45": failed to parse time: ..."
+func (e *ParseError) Error() string {
+	pos := fmt.Sprintf("offset %d", e.Offset)
+	if e.Line > 0 {
+		pos = fmt.Sprintf("line %d, %s", e.Line, pos)
+	}
+
+	return fmt.Sprintf("%s: invalid %s %q: %v", pos, e.Segment, e.Value, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ----------------------------------------------------------------------------
+//  Type: ParseErrors
+// ----------------------------------------------------------------------------
+
+// ParseErrors is a list of ParseError. It is returned by the lenient Load*
+// functions, such as LoadFromFileLenient, which collect all the errors instead
+// of stopping at the first one.
+type ParseErrors []*ParseError
+
+// Error returns the error messages of all the ParseError, one per line.
+func (errs ParseErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the list of errors. It allows errors.Is and errors.As to
+// inspect each ParseError.
+func (errs ParseErrors) Unwrap() []error {
+	list := make([]error, 0, len(errs))
+
+	for _, err := range errs {
+		list = append(list, err)
+	}
+
+	return list
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// newParseError returns a new ParseError of the segment found in the given
+// text at the given byte offset.
+func newParseError(err error, text string, offset int, segType TaskSegmentType, value string) *ParseError {
+	return &ParseError{
+		Err:     err,
+		Text:    text,
+		Value:   value,
+		Line:    0,
+		Offset:  offset,
+		Segment: segType,
+	}
+}
+
+// asParseError returns the *ParseError wrapped in the given error, if any.
+func asParseError(err error) (*ParseError, bool) {
+	var parseErr *ParseError
+
+	ok := errors.As(err, &parseErr)
+
+	return parseErr, ok
+}
+
+// isParseErrors returns true if the given error is a ParseErrors.
+func isParseErrors(err error) bool {
+	var parseErrs ParseErrors
+
+	return errors.As(err, &parseErrs)
+}
@@ -0,0 +1,123 @@
+package todo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseError_from_file(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		path          string
+		expectValue   string
+		expectLine    int
+		expectOffset  int
+		expectSegment TaskSegmentType
+	}{
+		{testInputTasklistCreatedDateError, "2013-13-01", 3, 4, SegmentCreatedDate},
+		{testInputTasklistDueDateError, "due:2014-02-32", 4, 72, SegmentDueDate},
+		{testInputTasklistCompletedDateError, "2014-25-04", 6, 2, SegmentCompletedDate},
+	} {
+		_, err := LoadFromPath(test.path)
+		require.Error(t, err, "invalid date should be an error: %s", test.path)
+
+		var parseErr *ParseError
+
+		require.ErrorAs(t, err, &parseErr, "the error should wrap a ParseError")
+
+		require.Equal(t, test.expectLine, parseErr.Line, "unexpected line number: %s", test.path)
+		require.Equal(t, test.expectOffset, parseErr.Offset, "unexpected offset: %s", test.path)
+		require.Equal(t, test.expectSegment, parseErr.Segment, "unexpected segment type: %s", test.path)
+		require.Equal(t, test.expectValue, parseErr.Value, "unexpected segment value: %s", test.path)
+		require.Equal(t, test.expectValue,
+			parseErr.Text[parseErr.Offset:parseErr.Offset+len(test.expectValue)],
+			"offset should point to the segment in the line: %s", test.path)
+	}
+}
+
+func TestParseError_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseTask("(A) Call Mom due:2020-13-45")
+	require.Error(t, err, "invalid due date should be an error")
+
+	var parseErr *ParseError
+
+	require.ErrorAs(t, err, &parseErr, "the error should wrap a ParseError")
+	require.Zero(t, parseErr.Line, "line number should be zero if not loaded from a file")
+	require.Contains(t, parseErr.Error(), `offset 13: invalid DueDate "due:2020-13-45"`)
+	require.Contains(t, err.Error(), `parsing time "2020-13-45": month out of range`)
+
+	// Line number is added when loaded from a file
+	parseErr.Line = 3
+
+	require.Contains(t, parseErr.Error(), `line 3, offset 13: invalid DueDate`)
+}
+
+func TestLoadFromFileLenient(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromFileLenient(strings.NewReader(`(A) Call Mom @Phone
+		x 2020-13-01 Schedule annual checkup +Health
+
+		(C) Add cover sheets @Office due:2020-02-30
+		Plan backyard herb garden @Home
+	`))
+	require.Error(t, err, "invalid lines should be reported")
+
+	// Valid tasks are returned
+	require.Len(t, tasklist, 2, "valid tasks should be returned")
+	require.Equal(t, "(A) Call Mom @Phone", tasklist[0].String())
+	require.Equal(t, 1, tasklist[0].ID)
+	require.Equal(t, "Plan backyard herb garden @Home", tasklist[1].String())
+	require.Equal(t, 2, tasklist[1].ID)
+
+	// All the errors are collected
+	var parseErrs ParseErrors
+
+	require.ErrorAs(t, err, &parseErrs, "the error should be a ParseErrors")
+	require.Len(t, parseErrs, 2, "all the invalid lines should be reported")
+
+	require.Equal(t, 2, parseErrs[0].Line)
+	require.Equal(t, SegmentCompletedDate, parseErrs[0].Segment)
+	require.Equal(t, "2020-13-01", parseErrs[0].Text[parseErrs[0].Offset:parseErrs[0].Offset+10],
+		"offset should include the indentation of the line")
+
+	require.Equal(t, 4, parseErrs[1].Line)
+	require.Equal(t, SegmentDueDate, parseErrs[1].Segment)
+
+	require.Equal(t, parseErrs[0].Error()+"\n"+parseErrs[1].Error(), err.Error())
+
+	// errors.As can find each ParseError
+	var parseErr *ParseError
+
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, 2, parseErr.Line, "the first error should be found")
+	require.True(t, errors.Is(err, parseErrs[1]), "each error should be found")
+}
+
+func TestLoadFromFileLenient_fail(t *testing.T) {
+	t.Parallel()
+
+	// Non parse errors are returned as is
+	tasklist, err := LoadFromFileLenient(nil)
+
+	require.Error(t, err, "expected error when loading from nil")
+	require.Nil(t, tasklist, "returned object should be nil on error")
+	require.Contains(t, err.Error(), "nil io.Reader")
+
+	tasklist, err = LoadFromPathLenient("some_file_that_does_not_exists.txt")
+
+	require.Error(t, err, "expected error when loading from non-existent path")
+	require.Nil(t, tasklist, "returned object should be nil on error")
+
+	// No errors
+	tasklist, err = LoadFromPathLenient(testInputTasklist)
+
+	require.NoError(t, err, "valid file should not be an error")
+	require.Len(t, tasklist, 63)
+}
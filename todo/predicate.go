@@ -1,6 +1,9 @@
 package todo
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // ----------------------------------------------------------------------------
 //  Type: Predicate
@@ -15,6 +18,120 @@ type Predicate func(Task) bool
 //  Constructors
 // ----------------------------------------------------------------------------
 
+// FilterAll returns a filter for tasks that match all the given predicates. It
+// is the AND combinator of the predicates. With no predicates, it matches any
+// task.
+func FilterAll(predicates ...Predicate) Predicate {
+	return func(t Task) bool {
+		for _, predicate := range predicates {
+			if !predicate(t) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// FilterAnd returns a filter for tasks that match both of the predicates, and
+// any more given. Such as:
+//
+//	// project X and not completed and (due today or overdue)
+//	filter := FilterAnd(
+//	    FilterByProject("X"),
+//	    FilterNotCompleted,
+//	    FilterOr(FilterDueToday, FilterOverdue),
+//	)
+func FilterAnd(predicate1, predicate2 Predicate, predicates ...Predicate) Predicate {
+	return FilterAll(append([]Predicate{predicate1, predicate2}, predicates...)...)
+}
+
+// FilterAny returns a filter for tasks that match any of the given predicates.
+// It is the OR combinator of the predicates, as TaskList.Filter does. With no
+// predicates, it matches no task.
+func FilterAny(predicates ...Predicate) Predicate {
+	return func(t Task) bool {
+		for _, predicate := range predicates {
+			if predicate(t) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// FilterCompletedBetween returns a filter for tasks that were completed between
+// the given dates, inclusive. The dates are compared as calendar dates in the
+// location of the options. See WithLocation.
+func FilterCompletedBetween(from, to time.Time, opts ...Option) Predicate {
+	return filterDateBetween(func(t *Task) Date { return t.CompletedDate }, from, to, opts...)
+}
+
+// FilterCompletedOn returns a filter for tasks that were completed on the given
+// date. See FilterCompletedBetween.
+func FilterCompletedOn(date time.Time, opts ...Option) Predicate {
+	return FilterCompletedBetween(date, date, opts...)
+}
+
+// FilterCreatedWithin returns a filter for tasks that were created within the
+// given number of days up to today. Such as 0 for today and 7 for the last week
+// including today. Today is taken from the Clock and the location of the
+// options. See WithClock and WithLocation.
+func FilterCreatedWithin(days int, opts ...Option) Predicate {
+	cfg := NewConfig(opts...)
+
+	return func(t Task) bool {
+		today := cfg.today()
+
+		return filterDateRange(func(t *Task) Date { return t.CreatedDate }, today.AddDays(-days), today)(t)
+	}
+}
+
+// FilterDueAfter returns a filter for tasks that are due after the given date,
+// exclusive. See FilterDueBetween.
+func FilterDueAfter(date time.Time, opts ...Option) Predicate {
+	ref := DateOf(date.In(NewConfig(opts...).Location))
+
+	return func(t Task) bool {
+		return t.HasDueDate() && t.DueDate.DateOnly().After(ref)
+	}
+}
+
+// FilterDueBefore returns a filter for tasks that are due before the given
+// date, exclusive. See FilterDueBetween.
+func FilterDueBefore(date time.Time, opts ...Option) Predicate {
+	ref := DateOf(date.In(NewConfig(opts...).Location))
+
+	return func(t Task) bool {
+		return t.HasDueDate() && t.DueDate.DateOnly().Before(ref)
+	}
+}
+
+// FilterDueBetween returns a filter for tasks that are due between the given
+// dates, inclusive.
+//
+// The dates are compared as calendar dates, not as durations, so the results
+// do not change around DST. The given dates are converted to the location of
+// the options before comparison. See WithLocation.
+func FilterDueBetween(from, to time.Time, opts ...Option) Predicate {
+	return filterDateBetween(func(t *Task) Date { return t.DueDate }, from, to, opts...)
+}
+
+// FilterDueWithin returns a filter for tasks that are due within the given
+// number of days from today, inclusive. Such as 0 for today and 3 for today and
+// the next 3 days. Overdue tasks are not included. Today is taken from the
+// Clock and the location of the options. See WithClock and WithLocation.
+func FilterDueWithin(days int, opts ...Option) Predicate {
+	cfg := NewConfig(opts...)
+
+	return func(t Task) bool {
+		today := cfg.today()
+
+		return filterDateRange(func(t *Task) Date { return t.DueDate }, today, today.AddDays(days))(t)
+	}
+}
+
 // FilterByContext returns a filter for tasks that have the given context.
 // String comparison in the filters is case-insensitive.
 func FilterByContext(context string) Predicate {
@@ -53,9 +170,59 @@ func FilterByProject(project string) Predicate {
 	}
 }
 
+// FilterByTag returns a filter for tasks that have the additional tag with the
+// given key. If values are given, the task must have any of the values among
+// the values of the tag, such as "dep:3 dep:7". String comparison of the values
+// is case-insensitive.
+func FilterByTag(key string, values ...string) Predicate {
+	return func(t Task) bool {
+		if len(values) == 0 {
+			return t.AdditionalTags.Has(key)
+		}
+
+		for _, tagValue := range t.AdditionalTags.Values(key) {
+			for _, value := range values {
+				if strings.EqualFold(tagValue, value) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+}
+
 // FilterNot returns a reversed filter for existing predicate.
 func FilterNot(predicate Predicate) Predicate {
 	return func(t Task) bool {
 		return !predicate(t)
 	}
 }
+
+// FilterOr returns a filter for tasks that match either of the predicates, or
+// any more given.
+func FilterOr(predicate1, predicate2 Predicate, predicates ...Predicate) Predicate {
+	return FilterAny(append([]Predicate{predicate1, predicate2}, predicates...)...)
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// filterDateBetween returns a filter for tasks whose date of the field is
+// between the given dates, inclusive. Tasks without the date do not match.
+func filterDateBetween(field func(t *Task) Date, from, to time.Time, opts ...Option) Predicate {
+	cfg := NewConfig(opts...)
+
+	return filterDateRange(field, DateOf(from.In(cfg.Location)), DateOf(to.In(cfg.Location)))
+}
+
+// filterDateRange returns a filter for tasks whose date of the field is between
+// the given dates, inclusive. Tasks without the date do not match.
+func filterDateRange(field func(t *Task) Date, from, to Date) Predicate {
+	return func(t Task) bool {
+		date := field(&t).DateOnly()
+
+		return !date.IsZero() && !date.Before(from) && !date.After(to)
+	}
+}
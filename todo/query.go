@@ -0,0 +1,679 @@
+package todo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Query language
+// ----------------------------------------------------------------------------
+//  A query is a list of terms which are combined with AND by default. Such as:
+//
+//    +Work @office pri:A-C due<=+3d not:done "report"
+//
+//  Terms:
+//    +Project, @context     tasks with the project or context
+//    pri:A, pri:A-C         tasks with the priority, or in the range
+//    pri:none, pri:any      tasks without or with any priority
+//    is:STATE, not:STATE    tasks in (or not in) the state. The states are done
+//                           (completed), active, overdue, today (due today),
+//                           recurring and threshold (threshold reached)
+//    FIELD OP DATE          date comparison of due, t (threshold), created and
+//                           completed. OP is one of : = != < <= > >= and DATE is
+//                           YYYY-MM-DD (or the layout of WithDateLayout),
+//                           today, tomorrow, yesterday or relative to today
+//                           such as +3d, -1w, +2m, +1y and +5b
+//    FIELD:none, FIELD:any  tasks without or with the date
+//    key OP value           tag comparison. Numbers and dates are compared as
+//                           such, otherwise as case-insensitive strings
+//    word, "quoted text"    case-insensitive full-text match
+//    /regexp/, /regexp/i    regular expression match of the task string
+//
+//  Operators (in order of precedence):
+//    ( ... )                grouping
+//    NOT, not, !, -term     negation
+//    AND, and, &&           conjunction (implicit between terms)
+//    OR, or, ||             disjunction
+
+// QueryError represents a syntax error in a query string.
+type QueryError struct {
+	Query  string // Query is the whole query string.
+	Msg    string // Msg describes the error.
+	Offset int    // Offset is the byte offset of the error in the query.
+}
+
+// Error returns the error message with the position in the query.
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("syntax error at offset %d in query %q: %s", e.Offset, e.Query, e.Msg)
+}
+
+// ----------------------------------------------------------------------------
+//  Public functions
+// ----------------------------------------------------------------------------
+
+// CompileQuery compiles the query string into a Predicate. See ParseQuery.
+func CompileQuery(query string, opts ...Option) (Predicate, error) {
+	expr, err := ParseQuery(query, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return expr.ToPredicate(), nil
+}
+
+// ParseQuery parses the query string into an Expr. An empty query matches any
+// task. If the query is invalid, the returned error is a *QueryError.
+//
+// The relative dates, such as "today" or "+3d", and the states, such as
+// "is:overdue", are based on today from the Clock and the location of the
+// options, and they are resolved on each match rather than on parsing. See
+// WithClock and WithLocation.
+//
+//	expr, err := todo.ParseQuery(`+Work @office pri:A-C due<=+3d not:done "report"`)
+//	if err != nil {
+//	    ...
+//	}
+//
+//	filtered := tasklist.Filter(expr.ToPredicate())
+func ParseQuery(query string, opts ...Option) (*Expr, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &queryParser{cfg: NewConfig(opts...), query: query, tokens: tokens, pos: 0}
+
+	if parser.peek().typ == queryTokenEOF {
+		return ExprAnd(), nil
+	}
+
+	expr, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if token := parser.peek(); token.typ != queryTokenEOF {
+		return nil, parser.errorf(token, "unexpected %q", token.text)
+	}
+
+	return expr, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Lexer
+// ----------------------------------------------------------------------------
+
+type queryTokenType uint8
+
+const (
+	queryTokenEOF queryTokenType = iota
+	queryTokenWord
+	queryTokenQuoted
+	queryTokenRegexp
+	queryTokenLParen
+	queryTokenRParen
+)
+
+type queryToken struct {
+	text   string         // text of the token, unquoted for quoted and regexp tokens.
+	flags  string         // flags of the regexp token. Such as "i".
+	offset int            // offset of the token in the query.
+	typ    queryTokenType // typ is the type of the token.
+}
+
+// lexQuery splits the query into tokens.
+func lexQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+
+	for pos := 0; pos < len(query); {
+		char := query[pos]
+
+		switch {
+		case strings.IndexByte(whitespaces, char) >= 0:
+			pos++
+		case char == '(':
+			tokens = append(tokens, queryToken{typ: queryTokenLParen, text: "(", offset: pos})
+			pos++
+		case char == ')':
+			tokens = append(tokens, queryToken{typ: queryTokenRParen, text: ")", offset: pos})
+			pos++
+		case char == '"' || char == '/':
+			token, end, err := lexQueryDelimited(query, pos)
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token)
+			pos = end
+		default:
+			end := pos
+			for end < len(query) && strings.IndexByte(whitespaces+"()", query[end]) < 0 {
+				end++
+			}
+
+			tokens = append(tokens, queryToken{typ: queryTokenWord, text: query[pos:end], offset: pos})
+			pos = end
+		}
+	}
+
+	return append(tokens, queryToken{typ: queryTokenEOF, text: "end of query", offset: len(query)}), nil
+}
+
+// lexQueryDelimited reads a quoted text or a regexp token starting at pos. The
+// delimiter can be escaped with a backslash. It returns the token and the
+// offset right after it.
+func lexQueryDelimited(query string, pos int) (queryToken, int, error) {
+	delim := query[pos]
+	token := queryToken{typ: queryTokenQuoted, offset: pos}
+
+	if delim == '/' {
+		token.typ = queryTokenRegexp
+	}
+
+	var strBld strings.Builder
+
+	for end := pos + 1; end < len(query); end++ {
+		switch query[end] {
+		case '\\':
+			if end+1 < len(query) && (query[end+1] == delim || (delim == '"' && query[end+1] == '\\')) {
+				end++
+			}
+		case delim:
+			token.text = strBld.String()
+			end++
+
+			// Flags of the regexp. Such as "/report/i"
+			for token.typ == queryTokenRegexp && end < len(query) && query[end] == 'i' {
+				token.flags = "i"
+				end++
+			}
+
+			return token, end, nil
+		}
+
+		strBld.WriteByte(query[end])
+	}
+
+	return token, 0, &QueryError{Query: query, Offset: pos, Msg: fmt.Sprintf("missing closing %q", delim)}
+}
+
+// ----------------------------------------------------------------------------
+//  Parser
+// ----------------------------------------------------------------------------
+
+type queryParser struct {
+	cfg    *Config
+	query  string
+	tokens []queryToken
+	pos    int
+}
+
+// errorf returns a QueryError at the position of the token.
+func (p *queryParser) errorf(token queryToken, format string, args ...any) error {
+	return &QueryError{Query: p.query, Offset: token.offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isKeyword returns true if the next token is a word of the given keywords.
+func (p *queryParser) isKeyword(keywords ...string) bool {
+	token := p.peek()
+
+	if token.typ != queryTokenWord {
+		return false
+	}
+
+	for _, keyword := range keywords {
+		if token.text == keyword {
+			return true
+		}
+	}
+
+	return false
+}
+
+// next returns the next token and advances the position.
+func (p *queryParser) next() queryToken {
+	token := p.tokens[p.pos]
+
+	if token.typ != queryTokenEOF {
+		p.pos++
+	}
+
+	return token
+}
+
+// parseAnd parses the terms combined with AND, which is implicit between terms.
+func (p *queryParser) parseAnd() (*Expr, error) {
+	exprs := []*Expr{}
+
+	for {
+		if p.isKeyword("AND", "and", "&&") {
+			if len(exprs) == 0 {
+				return nil, p.errorf(p.peek(), "missing term before %q", p.peek().text)
+			}
+
+			p.next()
+		}
+
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, expr)
+
+		if token := p.peek(); token.typ == queryTokenEOF || token.typ == queryTokenRParen ||
+			p.isKeyword("OR", "or", "||") {
+			break
+		}
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+
+	return ExprAnd(exprs...), nil
+}
+
+// parseOr parses the expressions combined with OR.
+func (p *queryParser) parseOr() (*Expr, error) {
+	exprs := []*Expr{}
+
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, expr)
+
+		if !p.isKeyword("OR", "or", "||") {
+			break
+		}
+
+		p.next()
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+
+	return ExprOr(exprs...), nil
+}
+
+// parseUnary parses a term, a negation or a group in parentheses.
+func (p *queryParser) parseUnary() (*Expr, error) {
+	token := p.peek()
+
+	switch token.typ {
+	case queryTokenLParen:
+		p.next()
+
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.next(); closing.typ != queryTokenRParen {
+			return nil, p.errorf(closing, "missing %q for %q at offset %d", ")", "(", token.offset)
+		}
+
+		return expr, nil
+	case queryTokenRParen:
+		return nil, p.errorf(token, "unexpected %q", token.text)
+	case queryTokenEOF:
+		return nil, p.errorf(token, "missing term at the end of query")
+	case queryTokenQuoted:
+		p.next()
+
+		return queryTextExpr(token.text), nil
+	case queryTokenRegexp:
+		p.next()
+
+		return p.regexpExpr(token)
+	case queryTokenWord:
+		// handled below
+	}
+
+	if p.isKeyword("NOT", "not", "!") {
+		p.next()
+
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return ExprNot(expr), nil
+	}
+
+	if p.isKeyword("AND", "and", "&&", "OR", "or", "||") {
+		return nil, p.errorf(token, "missing term before %q", token.text)
+	}
+
+	p.next()
+
+	// Negation prefix. Such as "-@home" or "!+Work"
+	if len(token.text) > 1 && (token.text[0] == '-' || token.text[0] == '!') {
+		token.text = token.text[1:]
+		token.offset++
+
+		expr, err := p.termExpr(token)
+		if err != nil {
+			return nil, err
+		}
+
+		return ExprNot(expr), nil
+	}
+
+	return p.termExpr(token)
+}
+
+// peek returns the next token without advancing the position.
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+// regexpExpr returns the Expr of the regexp token.
+func (p *queryParser) regexpExpr(token queryToken) (*Expr, error) {
+	pattern := token.text
+	if token.flags == "i" {
+		pattern = "(?i)" + pattern
+	}
+
+	rx, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, p.errorf(token, "invalid regexp: %v", err)
+	}
+
+	return ExprPredicate("/"+token.text+"/"+token.flags, func(t Task) bool {
+		return rx.MatchString(t.String())
+	}), nil
+}
+
+// ----------------------------------------------------------------------------
+//  Terms
+// ----------------------------------------------------------------------------
+
+// queryCompareRx matches the comparison terms. Such as "due<=+3d" or "pri:A".
+var queryCompareRx = regexp.MustCompile(`^([A-Za-z][\w-]*)(<=|>=|!=|<|>|=|:)(.*)$`)
+
+// queryPriorityRx matches the priority or the range of priorities. Such as "A"
+// or "A-C".
+var queryPriorityRx = regexp.MustCompile(`^([A-Za-z])(?:-([A-Za-z]))?$`)
+
+// queryRelativeDateRx matches the relative dates. Such as "+3d" or "-1w".
+var queryRelativeDateRx = regexp.MustCompile(`^([+-])(\d+)([dbwmy])$`)
+
+// queryStates are the states of "is:" and "not:" terms.
+//
+//nolint:gochecknoglobals // global variable is intentional as a lookup table
+var queryStates = map[string]func(cfg *Config) Predicate{
+	"active":    func(cfg *Config) Predicate { return func(t Task) bool { return t.isActive(cfg) } },
+	"completed": func(*Config) Predicate { return FilterCompleted },
+	"done":      func(*Config) Predicate { return FilterCompleted },
+	"overdue":   func(cfg *Config) Predicate { return func(t Task) bool { return t.isOverdue(cfg) } },
+	"recurring": func(*Config) Predicate { return func(t Task) bool { return t.IsRecurring() } },
+	"threshold": func(cfg *Config) Predicate { return func(t Task) bool { return t.isThresholdReached(cfg) } },
+	"today":     func(cfg *Config) Predicate { return func(t Task) bool { return t.isDueToday(cfg) } },
+}
+
+// queryDateFields are the date fields of the comparison terms.
+//
+//nolint:gochecknoglobals // global variable is intentional as a lookup table
+var queryDateFields = map[string]func(t *Task) Date{
+	"completed": func(t *Task) Date { return t.CompletedDate },
+	"created":   func(t *Task) Date { return t.CreatedDate },
+	"due":       func(t *Task) Date { return t.DueDate },
+	"t":         func(t *Task) Date { return t.ThresholdDate },
+	"threshold": func(t *Task) Date { return t.ThresholdDate },
+}
+
+// termExpr returns the Expr of the word term.
+func (p *queryParser) termExpr(token queryToken) (*Expr, error) {
+	word := token.text
+
+	switch {
+	case len(word) > 1 && word[0] == '+':
+		return ExprPredicate(word, FilterByProject(word[1:])), nil
+	case len(word) > 1 && word[0] == '@':
+		return ExprPredicate(word, FilterByContext(word[1:])), nil
+	}
+
+	match := queryCompareRx.FindStringSubmatch(word)
+	if match == nil {
+		return queryTextExpr(word), nil
+	}
+
+	key, operator, value := strings.ToLower(match[1]), match[2], match[3]
+
+	if isEmpty(value) {
+		return nil, p.errorf(token, "missing value after %q", match[1]+operator)
+	}
+
+	switch {
+	case key == "is" || key == "not":
+		state, ok := queryStates[strings.ToLower(value)]
+		if !ok || operator != ":" {
+			return nil, p.errorf(token, "unknown state %q", word)
+		}
+
+		predicate := state(p.cfg)
+
+		if key == "not" {
+			return ExprNot(ExprPredicate(strings.ToLower(value), predicate)), nil
+		}
+
+		return ExprPredicate(strings.ToLower(value), predicate), nil
+	case key == "pri" || key == "priority":
+		return p.priorityExpr(token, operator, value)
+	case queryDateFields[key] != nil:
+		return p.dateExpr(token, key, operator, value)
+	}
+
+	return p.tagExpr(token, match[1], operator, value)
+}
+
+// dateExpr returns the Expr of the date comparison term.
+func (p *queryParser) dateExpr(token queryToken, key, operator, value string) (*Expr, error) {
+	field := queryDateFields[key]
+
+	switch strings.ToLower(value) {
+	case "none", "any":
+		if operator != ":" && operator != "=" {
+			return nil, p.errorf(token, "operator %q is not supported for %q", operator, value)
+		}
+
+		hasDate := strings.EqualFold(value, "any")
+
+		return ExprPredicate(token.text, func(t Task) bool {
+			return !field(&t).IsZero() == hasDate
+		}), nil
+	}
+
+	cfg := p.cfg
+
+	// Validate the date here but resolve it on each match, so that the relative
+	// dates follow today of a long-lived Expr
+	if _, err := parseQueryDate(value, cfg); err != nil {
+		return nil, p.errorf(token, "invalid date %q: %v", value, err)
+	}
+
+	compare := queryCompareFunc(operator)
+
+	return ExprPredicate(token.text, func(t Task) bool {
+		taskDate := field(&t)
+		if taskDate.IsZero() {
+			return false
+		}
+
+		date, err := parseQueryDate(value, cfg)
+		if err != nil {
+			return false
+		}
+
+		return compare(taskDate.DateOnly().Compare(date))
+	}), nil
+}
+
+// priorityExpr returns the Expr of the priority term.
+func (p *queryParser) priorityExpr(token queryToken, operator, value string) (*Expr, error) {
+	if operator != ":" && operator != "=" && operator != "!=" {
+		return nil, p.errorf(token, "operator %q is not supported for priority", operator)
+	}
+
+	var predicate Predicate
+
+	switch strings.ToLower(value) {
+	case "none":
+		predicate = FilterNot(FilterHasPriority)
+	case "any":
+		predicate = FilterHasPriority
+	default:
+		match := queryPriorityRx.FindStringSubmatch(value)
+		if match == nil {
+			return nil, p.errorf(token, "invalid priority %q, expected such as \"A\" or \"A-C\"", value)
+		}
+
+		low, high := strings.ToUpper(match[1]), strings.ToUpper(match[2])
+		if isEmpty(high) {
+			high = low
+		}
+
+		if low > high {
+			low, high = high, low
+		}
+
+		predicate = func(t Task) bool {
+			return t.HasPriority() && low <= t.Priority && t.Priority <= high
+		}
+	}
+
+	if operator == "!=" {
+		predicate = FilterNot(predicate)
+	}
+
+	return ExprPredicate(token.text, predicate), nil
+}
+
+// tagExpr returns the Expr of the tag comparison term.
+func (p *queryParser) tagExpr(token queryToken, key, operator, value string) (*Expr, error) {
+	if operator == ":" || operator == "=" || operator == "!=" {
+		predicate := FilterByTag(key, value)
+
+		if operator == "!=" {
+			predicate = FilterNot(predicate)
+		}
+
+		return ExprPredicate(token.text, predicate), nil
+	}
+
+	compare := queryCompareFunc(operator)
+	cfg := p.cfg
+
+	return ExprPredicate(token.text, func(t Task) bool {
+		for _, tagValue := range t.AdditionalTags.Values(key) {
+			if result, ok := compareQueryValues(tagValue, value, cfg); ok && compare(result) {
+				return true
+			}
+		}
+
+		return false
+	}), nil
+}
+
+// queryTextExpr returns the Expr of the full-text term.
+func queryTextExpr(text string) *Expr {
+	lower := strings.ToLower(text)
+
+	return ExprPredicate(strconv.Quote(text), func(t Task) bool {
+		return strings.Contains(strings.ToLower(t.String()), lower)
+	})
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// compareQueryValues compares the tag value with the query value as numbers,
+// dates or case-insensitive strings in this order of precedence. It returns
+// false if they are not comparable. The dates are in the configured layout, and
+// the relative dates are based on today of the configuration.
+func compareQueryValues(tagValue, queryValue string, cfg *Config) (int, bool) {
+	if numTag, err := strconv.ParseFloat(tagValue, 64); err == nil {
+		if numQuery, err := strconv.ParseFloat(queryValue, 64); err == nil {
+			switch {
+			case numTag < numQuery:
+				return -1, true
+			case numTag > numQuery:
+				return 1, true
+			}
+
+			return 0, true
+		}
+	}
+
+	if dateTag, err := cfg.parseDate(tagValue); err == nil {
+		dateQuery, err := parseQueryDate(queryValue, cfg)
+		if err != nil {
+			return 0, false
+		}
+
+		return dateTag.Compare(dateQuery), true
+	}
+
+	return strings.Compare(strings.ToLower(tagValue), strings.ToLower(queryValue)), true
+}
+
+// queryCompareFunc returns the function which checks the result of a comparison
+// against the operator.
+func queryCompareFunc(operator string) func(result int) bool {
+	switch operator {
+	case "<":
+		return func(result int) bool { return result < 0 }
+	case "<=":
+		return func(result int) bool { return result <= 0 }
+	case ">":
+		return func(result int) bool { return result > 0 }
+	case ">=":
+		return func(result int) bool { return result >= 0 }
+	case "!=":
+		return func(result int) bool { return result != 0 }
+	}
+
+	return func(result int) bool { return result == 0 }
+}
+
+// parseQueryDate parses the date in a query. Such as "2020-01-31" in the
+// configured layout, "today", "tomorrow", "yesterday" or relative dates to
+// today such as "+3d".
+func parseQueryDate(value string, cfg *Config) (Date, error) {
+	today := cfg.today()
+
+	switch strings.ToLower(value) {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDays(1), nil
+	case "yesterday":
+		return today.AddDays(-1), nil
+	}
+
+	if match := queryRelativeDateRx.FindStringSubmatch(value); match != nil {
+		num, err := strconv.Atoi(match[2])
+		if err != nil {
+			return Date{}, errors.Wrap(err, "failed to parse relative date")
+		}
+
+		if match[1] == "-" {
+			num = -num
+		}
+
+		return Recurrence{Interval: num, Unit: RecurrenceUnit(match[3][0]), Strict: false}.Next(today), nil
+	}
+
+	return cfg.parseDate(value)
+}
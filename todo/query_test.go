@@ -0,0 +1,225 @@
+package todo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Parallel()
+
+	today := time.Now()
+	inDays := func(days int) string {
+		return today.AddDate(0, 0, days).Format(DateLayout)
+	}
+
+	tasklist, err := LoadFromString(
+		"(A) Write report +Work @office due:" + inDays(1) + "\n" + // 1
+			"(B) Review report +Work @office due:" + inDays(10) + "\n" + // 2
+			"x (C) Send report +Work @office due:" + inDays(2) + "\n" + // 3
+			"(D) Call Mom @phone est:30 due:" + inDays(-1) + "\n" + // 4
+			"Plan trip +Travel t:" + inDays(5) + " est:120 dep:3 dep:7\n" + // 5
+			"2020-01-01 Water plants rec:1w\n", // 6
+	)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		query  string
+		expect []int
+	}{
+		{query: ``, expect: []int{1, 2, 3, 4, 5, 6}},
+		{query: `+Work @office pri:A-C due<=+3d not:done "report"`, expect: []int{1}},
+		{query: `+work`, expect: []int{1, 2, 3}},
+		{query: `-@office`, expect: []int{4, 5, 6}},
+		{query: `pri:B`, expect: []int{2}},
+		{query: `pri:C-A`, expect: []int{1, 2, 3}},
+		{query: `pri:none`, expect: []int{5, 6}},
+		{query: `pri!=A pri:any`, expect: []int{2, 3, 4}},
+		{query: `is:done`, expect: []int{3}},
+		{query: `is:overdue`, expect: []int{4}},
+		{query: `is:recurring`, expect: []int{6}},
+		{query: `not:threshold`, expect: []int{5}},
+		{query: `is:active`, expect: []int{1, 2, 4, 6}},
+		{query: `due:none`, expect: []int{5, 6}},
+		{query: `due>today`, expect: []int{1, 2, 3}},
+		{query: `due<` + inDays(2), expect: []int{1, 4}},
+		{query: `due=tomorrow`, expect: []int{1}},
+		{query: `due>=yesterday due<=today`, expect: []int{4}},
+		{query: `t>+1w`, expect: []int{}},
+		{query: `created<2021-01-01`, expect: []int{6}},
+		{query: `est>=60`, expect: []int{5}},
+		{query: `est<60`, expect: []int{4}},
+		{query: `dep:7`, expect: []int{5}},
+		{query: `dep!=7 +Travel`, expect: []int{}},
+		{query: `report OR trip`, expect: []int{1, 2, 3, 5}},
+		{query: `+Work AND (pri:A || pri:C) && NOT is:done`, expect: []int{1}},
+		{query: `(@phone or +Travel) and not est:30`, expect: []int{5}},
+		{query: `!is:done +Work`, expect: []int{1, 2}},
+		{query: `/^\(A\)/`, expect: []int{1}},
+		{query: `/WATER/i`, expect: []int{6}},
+		{query: `"plan trip"`, expect: []int{5}},
+		{query: `"say \"hi\""`, expect: []int{}},
+	} {
+		expr, err := ParseQuery(test.query)
+		require.NoError(t, err, "query: %s", test.query)
+
+		actual := []int{}
+
+		for _, task := range tasklist.Filter(expr.ToPredicate()) {
+			actual = append(actual, task.ID)
+		}
+
+		require.Equal(t, test.expect, actual, "query: %s\nexpr: %s", test.query, expr)
+	}
+}
+
+func TestParseQuery_with_clock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 3, 8, 23, 30, 0, 0, time.UTC)
+	opts := []Option{WithClock(FixedClock(now)), WithLocation(time.FixedZone("JST", 9*60*60))}
+
+	tasklist, err := LoadFromString(
+		"Write report due:2020-03-08\n" + // 1
+			"Review report due:2020-03-09 t:2020-03-09\n" + // 2
+			"Send report due:2020-03-10 start:2020-03-11\n", // 3
+	)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		query  string
+		expect []int
+	}{
+		{query: `is:overdue`, expect: []int{1}},
+		{query: `is:today`, expect: []int{2}},
+		{query: `is:threshold`, expect: []int{1, 2, 3}},
+		{query: `due=tomorrow`, expect: []int{3}},
+		{query: `due<=+1d due>=yesterday`, expect: []int{1, 2, 3}},
+		{query: `start>+1d`, expect: []int{3}},
+	} {
+		filter, err := CompileQuery(test.query, opts...)
+		require.NoError(t, err, "query: %s", test.query)
+
+		actual := []int{}
+
+		for _, task := range tasklist.Filter(filter) {
+			actual = append(actual, task.ID)
+		}
+
+		require.Equal(t, test.expect, actual, "query: %s", test.query)
+	}
+}
+
+func TestParseQuery_relative_dates_on_match(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 3, 8, 12, 0, 0, 0, time.UTC)
+	opts := []Option{WithClock(ClockFunc(func() time.Time { return now })), WithLocation(time.UTC)}
+
+	task, err := ParseTask("Write report due:2020-03-09 review:2020-03-09")
+	require.NoError(t, err)
+
+	filters := []Predicate{}
+
+	for _, query := range []string{`due=tomorrow`, `due<=+1d`, `review>=tomorrow`} {
+		filter, err := CompileQuery(query, opts...)
+		require.NoError(t, err, "query: %s", query)
+		require.True(t, filter(*task), "query: %s", query)
+
+		filters = append(filters, filter)
+	}
+
+	// A day later, the same filters should use the new today
+	now = now.AddDate(0, 0, 1)
+
+	require.False(t, filters[0](*task), "due=tomorrow should be resolved on each match")
+	require.True(t, filters[1](*task), "due<=+1d should still match")
+	require.False(t, filters[2](*task), "review>=tomorrow should be resolved on each match")
+}
+
+func TestParseQuery_date_layout(t *testing.T) {
+	t.Parallel()
+
+	opts := []Option{
+		WithDateLayout("02.01.2006"),
+		WithClock(FixedClock(time.Date(2020, 3, 8, 12, 0, 0, 0, time.UTC))),
+		WithLocation(time.UTC),
+	}
+
+	tasklist, err := LoadFromString("Write report due:09.03.2020 review:09.03.2020\nSend report due:20.03.2020", opts...)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		query  string
+		expect []int
+	}{
+		{query: `due<=10.03.2020`, expect: []int{1}},
+		{query: `due>tomorrow`, expect: []int{2}},
+		{query: `review<10.03.2020`, expect: []int{1}},
+		{query: `review>=tomorrow`, expect: []int{1}},
+	} {
+		filter, err := CompileQuery(test.query, opts...)
+		require.NoError(t, err, "query: %s", test.query)
+
+		actual := []int{}
+
+		for _, task := range tasklist.Filter(filter) {
+			actual = append(actual, task.ID)
+		}
+
+		require.Equal(t, test.expect, actual, "query: %s", test.query)
+	}
+
+	_, err = CompileQuery(`due<2020-03-10`, opts...)
+	require.Error(t, err, "dates should be in the configured layout")
+}
+
+func TestParseQuery_string(t *testing.T) {
+	t.Parallel()
+
+	expr, err := ParseQuery(`+Work (pri:A OR -@home) not:done "report"`)
+	require.NoError(t, err)
+	require.Equal(t, `+Work AND (pri:A OR NOT @home) AND NOT done AND "report"`, expr.String())
+}
+
+func TestParseQuery_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		query  string
+		expect string
+		offset int
+	}{
+		{query: `+Work (pri:A`, offset: 12, expect: `missing ")" for "(" at offset 6`},
+		{query: `+Work )`, offset: 6, expect: `unexpected ")"`},
+		{query: `+Work OR`, offset: 8, expect: `missing term at the end of query`},
+		{query: `AND +Work`, offset: 0, expect: `missing term before "AND"`},
+		{query: `+Work OR AND @home`, offset: 9, expect: `missing term before "AND"`},
+		{query: `"report`, offset: 0, expect: `missing closing '"'`},
+		{query: `/[a-/`, offset: 0, expect: `invalid regexp`},
+		{query: `due<=soon`, offset: 0, expect: `invalid date "soon"`},
+		{query: `due<none`, offset: 0, expect: `operator "<" is not supported for "none"`},
+		{query: `pri:AB`, offset: 0, expect: `invalid priority "AB"`},
+		{query: `pri<A`, offset: 0, expect: `operator "<" is not supported for priority`},
+		{query: `is:sleeping`, offset: 0, expect: `unknown state "is:sleeping"`},
+		{query: `+Work est:`, offset: 6, expect: `missing value after "est:"`},
+	} {
+		_, err := ParseQuery(test.query)
+		require.Error(t, err, "query: %s", test.query)
+
+		var queryErr *QueryError
+
+		require.ErrorAs(t, err, &queryErr)
+		require.Equal(t, test.offset, queryErr.Offset, "query: %s", test.query)
+		require.Contains(t, queryErr.Error(), test.expect, "query: %s", test.query)
+	}
+
+	_, err := CompileQuery(`(`)
+	require.Error(t, err)
+
+	predicate, err := CompileQuery(`+Work`)
+	require.NoError(t, err)
+	require.True(t, predicate(Task{Projects: []string{"Work"}})) //nolint:exhaustruct // test data
+}
@@ -0,0 +1,162 @@
+package todo
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Reader
+// ----------------------------------------------------------------------------
+
+// Reader reads tasks one by one from an io.Reader in todo.txt format.
+//
+// Unlike LoadFromFile, it does not hold the whole list in memory and there is
+// no limit on the length of a line. Which is useful to process very large files,
+// such as done.txt archives, in constant memory.
+type Reader struct {
+	reader  *bufio.Reader
+	cfg     *Config
+	filters []Predicate
+	lineNum int
+	taskID  int
+}
+
+// ----------------------------------------------------------------------------
+//  Constructor
+// ----------------------------------------------------------------------------
+
+// NewReader returns a new Reader which reads tasks from the given io.Reader.
+// The options are used to change the default configuration. See Config.
+func NewReader(reader io.Reader, opts ...Option) *Reader {
+	return newReader(reader, NewConfig(opts...))
+}
+
+// newReader returns a new Reader with the given configuration.
+func newReader(reader io.Reader, cfg *Config) *Reader {
+	return &Reader{
+		reader:  bufio.NewReader(reader),
+		cfg:     cfg,
+		filters: nil,
+		lineNum: 0,
+		taskID:  0,
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// Filter sets the predicates to filter the tasks to read. As TaskList.Filter
+// does, a task is returned by Next if any of the predicates returns true, and
+// non-task entries are skipped. It returns the Reader itself for chaining.
+func (r *Reader) Filter(filter Predicate, filters ...Predicate) *Reader {
+	r.filters = append([]Predicate{filter}, filters...)
+
+	return r
+}
+
+// Line returns the line number of the last read line, starting from 1.
+func (r *Reader) Line() int {
+	return r.lineNum
+}
+
+// Next reads and returns the next task. It returns io.EOF when there are no
+// more tasks to read.
+//
+// The Task.ID is numbered in order of the tasks in the file, including the ones
+// skipped by the filters. If a line failed to parse, the returned error wraps
+// a *ParseError with the line number. The reading can be continued by calling
+// Next again.
+func (r *Reader) Next() (*Task, error) {
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+
+		task, err := r.parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		if task != nil && r.match(task) {
+			return task, nil
+		}
+	}
+}
+
+// match returns true if the task matches any of the filters. Non-task entries
+// only match if there are no filters.
+func (r *Reader) match(task *Task) bool {
+	if len(r.filters) == 0 {
+		return true
+	}
+
+	if task.NonTask {
+		return false
+	}
+
+	for _, filter := range r.filters {
+		if filter(*task) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseLine parses the given line into a Task. It returns nil if the line is a
+// blank or comment line to skip.
+func (r *Reader) parseLine(line string) (*Task, error) {
+	text := strings.Trim(line, whitespaces)
+
+	// Ignore blank or comment lines, or keep them as non-task entries
+	if isEmpty(text) || r.cfg.isComment(text) {
+		if r.cfg.KeepNonTaskLines {
+			//nolint:exhaustruct // other fields are missing intentionally
+			return &Task{Original: line, NonTask: true}, nil
+		}
+
+		return nil, nil //nolint:nilnil // nil task means to skip the line
+	}
+
+	task, err := parseTask(text, r.cfg)
+	if err != nil {
+		if parseErr, ok := asParseError(err); ok {
+			// Set the position in the file
+			parseErr.Line = r.lineNum
+			parseErr.Offset += len(line) - len(strings.TrimLeft(line, whitespaces))
+			parseErr.Text = line
+		}
+
+		return nil, err
+	}
+
+	r.taskID++
+	task.ID = r.taskID
+
+	return task, nil
+}
+
+// readLine reads a whole line without the end of line characters. It returns
+// io.EOF as is when there are no more lines.
+func (r *Reader) readLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			return emptyStr, errors.Wrap(err, "failed to read line")
+		}
+
+		if isEmpty(line) {
+			return emptyStr, io.EOF
+		}
+	}
+
+	r.lineNum++
+
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
@@ -0,0 +1,40 @@
+//go:build go1.23
+
+package todo
+
+import (
+	"io"
+	"iter"
+
+	"github.com/pkg/errors"
+)
+
+// All returns an iterator over the remaining tasks of the Reader. It is the
+// range-over-func form of Next, for Go 1.23 or later.
+//
+// The parse errors are yielded along with a nil task and the iteration goes on
+// to the next line. Any other error is yielded and ends the iteration.
+//
+//	for task, err := range reader.All() {
+//	    if err != nil {
+//	        ...
+//	    }
+//	}
+func (r *Reader) All() iter.Seq2[*Task, error] {
+	return func(yield func(*Task, error) bool) {
+		for {
+			task, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if !yield(task, err) {
+				return
+			}
+
+			if _, ok := asParseError(err); err != nil && !ok {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+//go:build go1.23
+
+package todo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_All(t *testing.T) {
+	t.Parallel()
+
+	reader := NewReader(strings.NewReader("(A) Call Mom\n(B) Pay bills due:2020-13-45\n(C) Pick up milk\n"))
+
+	var (
+		todos []string
+		errs  []error
+	)
+
+	for task, err := range reader.All() {
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		todos = append(todos, task.Todo)
+	}
+
+	require.Equal(t, []string{"Call Mom", "Pick up milk"}, todos)
+	require.Len(t, errs, 1, "parse errors should be yielded and the iteration should go on")
+
+	// Break
+	reader = NewReader(strings.NewReader("(A) Call Mom\n(B) Pay bills\n"))
+
+	for task := range reader.All() {
+		require.Equal(t, "Call Mom", task.Todo)
+
+		break
+	}
+
+	task, err := reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, "Pay bills", task.Todo, "the rest of the tasks should be left in the reader")
+
+	// Read error ends the iteration
+	count := 0
+
+	for _, err := range NewReader(iotest.ErrReader(errors.New("forced error"))).All() {
+		require.Error(t, err)
+
+		count++
+	}
+
+	require.Equal(t, 1, count, "read error should end the iteration")
+}
@@ -0,0 +1,112 @@
+package todo
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_Next(t *testing.T) {
+	t.Parallel()
+
+	reader := NewReader(strings.NewReader("(A) Call Mom @Phone\r\n# comment\n\nx Pick up milk @GroceryStore"))
+
+	task, err := reader.Next()
+	require.NoError(t, err, "failed to read the first task")
+	require.Equal(t, "(A) Call Mom @Phone", task.String(), "end of line characters should be removed")
+	require.Equal(t, 1, task.ID)
+	require.Equal(t, 1, reader.Line())
+
+	task, err = reader.Next()
+	require.NoError(t, err, "failed to read the last task without new line")
+	require.Equal(t, "x Pick up milk @GroceryStore", task.String())
+	require.Equal(t, 2, task.ID, "comment and blank lines should not be numbered")
+	require.Equal(t, 4, reader.Line())
+
+	task, err = reader.Next()
+	require.ErrorIs(t, err, io.EOF, "it should return io.EOF at the end")
+	require.Nil(t, task)
+}
+
+func TestReader_Next_very_long_line(t *testing.T) {
+	t.Parallel()
+
+	// Longer than the default token size of bufio.Scanner (64 KiB)
+	longText := strings.Repeat("a", 1024*1024)
+	reader := NewReader(strings.NewReader("(A) " + longText + " @Phone\n"))
+
+	task, err := reader.Next()
+	require.NoError(t, err, "very long line should be read")
+	require.Equal(t, longText+" @Phone", task.Todo)
+}
+
+func TestReader_Next_parse_error(t *testing.T) {
+	t.Parallel()
+
+	reader := NewReader(strings.NewReader("(A) Call Mom\n  (B) Pay bills due:2020-13-45\n(C) Pick up milk\n"))
+
+	_, err := reader.Next()
+	require.NoError(t, err)
+
+	_, err = reader.Next()
+	require.Error(t, err, "invalid due date should be an error")
+
+	var parseErr *ParseError
+
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, 2, parseErr.Line)
+	require.Equal(t, 16, parseErr.Offset, "offset should include the indentation")
+
+	// Continue reading after a parse error
+	task, err := reader.Next()
+	require.NoError(t, err, "it should continue reading after a parse error")
+	require.Equal(t, "(C) Pick up milk", task.String())
+	require.Equal(t, 2, task.ID, "failed lines should not be numbered")
+}
+
+func TestReader_Next_read_error(t *testing.T) {
+	t.Parallel()
+
+	reader := NewReader(iotest.ErrReader(errors.New("forced error")))
+
+	task, err := reader.Next()
+	require.Error(t, err)
+	require.NotErrorIs(t, err, io.EOF)
+	require.Nil(t, task)
+	require.Contains(t, err.Error(), "forced error")
+}
+
+func TestReader_Filter(t *testing.T) {
+	t.Parallel()
+
+	file := strings.NewReader("# tasks\n(A) Call Mom @Phone\nx Pick up milk\n(B) Pay bills @Phone\n")
+	reader := NewReader(file, WithKeepNonTaskLines(true)).Filter(FilterByContext("phone"), FilterCompleted)
+
+	var ids []int
+
+	for {
+		task, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+		require.False(t, task.IsNonTask(), "non-task entries should be skipped by the filters")
+
+		ids = append(ids, task.ID)
+	}
+
+	require.Equal(t, []int{1, 2, 3}, ids, "IDs should be numbered regardless of the filters")
+
+	// Without filters, non-task entries are read
+	reader = NewReader(strings.NewReader("# tasks\n(A) Call Mom\n"), WithKeepNonTaskLines(true))
+
+	task, err := reader.Next()
+	require.NoError(t, err)
+	require.True(t, task.IsNonTask())
+	require.Equal(t, "# tasks", task.String())
+}
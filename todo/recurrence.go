@@ -0,0 +1,263 @@
+package todo
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: RecurrenceUnit
+// ----------------------------------------------------------------------------
+
+// RecurrenceUnit represents the unit of the interval of a Recurrence.
+type RecurrenceUnit byte
+
+// Units of the interval of a Recurrence. The values are the characters used in
+// the "rec:" tag.
+const (
+	RecurDays         RecurrenceUnit = 'd'
+	RecurBusinessDays RecurrenceUnit = 'b'
+	RecurWeeks        RecurrenceUnit = 'w'
+	RecurMonths       RecurrenceUnit = 'm'
+	RecurYears        RecurrenceUnit = 'y'
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Recurrence
+// ----------------------------------------------------------------------------
+
+// Recurrence represents the recurrence rule of a task in the "rec:" tag. Such as
+// "rec:1w", "rec:+2d" or "rec:5b".
+//
+// If Strict is true ("rec:+1w"), the next instance is scheduled from the due
+// date of the completed task. Otherwise, it is scheduled from the date of the
+// completion.
+type Recurrence struct {
+	Interval int            // Interval is the number of units between the instances.
+	Unit     RecurrenceUnit // Unit of the interval.
+	Strict   bool           // Strict flag. If true, the next date is based on the due date.
+}
+
+// recurrenceRx matches the value of the "rec:" tag. Such as "1w" or "+2d".
+var recurrenceRx = regexp.MustCompile(`^(\+?)(\d*)([dbwmy])$`)
+
+// ParseRecurrence parses the value of the "rec:" tag into a Recurrence. The
+// interval defaults to 1 if omitted, such as "rec:w".
+func ParseRecurrence(value string) (Recurrence, error) {
+	match := recurrenceRx.FindStringSubmatch(value)
+	if match == nil {
+		return Recurrence{}, errors.Errorf("invalid recurrence %q, expected such as \"1w\" or \"+2d\"", value)
+	}
+
+	interval := 1
+
+	if isNotEmpty(match[2]) {
+		num, err := strconv.Atoi(match[2])
+		if err != nil {
+			return Recurrence{}, errors.Wrap(err, "failed to parse interval of recurrence")
+		}
+
+		interval = num
+	}
+
+	if interval < 1 {
+		return Recurrence{}, errors.Errorf("invalid recurrence %q, interval must be greater than 0", value)
+	}
+
+	return Recurrence{
+		Interval: interval,
+		Unit:     RecurrenceUnit(match[3][0]),
+		Strict:   isNotEmpty(match[1]),
+	}, nil
+}
+
+// Next returns the date of the next instance from the given date.
+//
+// Adding months or years keeps the day of the month. If the day does not exist
+// in the resulting month, the last day of the month is used instead. Such as
+// 2020-01-31 plus 1m is 2020-02-29.
+func (rec Recurrence) Next(from Date) Date {
+	switch rec.Unit {
+	case RecurBusinessDays:
+		return addBusinessDays(from, rec.Interval)
+	case RecurWeeks:
+		return from.AddDays(7 * rec.Interval)
+	case RecurMonths:
+		return addMonths(from, rec.Interval)
+	case RecurYears:
+		return addMonths(from, 12*rec.Interval)
+	case RecurDays:
+		return from.AddDays(rec.Interval)
+	}
+
+	return from.AddDays(rec.Interval)
+}
+
+// String returns the recurrence rule as the value of the "rec:" tag.
+func (rec Recurrence) String() string {
+	prefix := emptyStr
+	if rec.Strict {
+		prefix = "+"
+	}
+
+	return prefix + strconv.Itoa(rec.Interval) + string(rec.Unit)
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of Task
+// ----------------------------------------------------------------------------
+
+// IsRecurring returns true if the task has a "rec:" tag.
+func (task *Task) IsRecurring() bool {
+	return task.AdditionalTags.Has("rec")
+}
+
+// NextInstance returns a new task which is the next instance of the recurring
+// task. The task itself is not modified.
+//
+// The next due date is calculated from the due date of the task if the rule is
+// strict ("rec:+1w"), or from the completed date otherwise ("rec:1w"). The
+// threshold date is shifted to keep the same distance to the due date. If the
+// task has neither due nor threshold date, the due date is set to the next date
+// from the completion.
+//
+// The completed date defaults to today if the task is not completed yet. The
+// returned task is not completed and its created date is the completed date if
+// the task had a created date. Today is taken from the Clock and the location of
+// the options. It returns an error which wraps ErrTagNotFound if the task is not
+// recurring.
+func (task *Task) NextInstance(opts ...Option) (*Task, error) {
+	rec, err := task.Recurrence()
+	if err != nil {
+		return nil, err
+	}
+
+	completed := task.CompletedDate
+	if !task.HasCompletedDate() {
+		completed = NewConfig(opts...).today()
+	}
+
+	next := task.cloneFields()
+	next.ID = 0
+	next.Original = emptyStr
+	next.Completed = false
+	next.CompletedDate = Date{}
+
+	if task.HasCreatedDate() {
+		next.CreatedDate = completed
+	}
+
+	switch {
+	case task.HasDueDate():
+		base := completed
+		if rec.Strict {
+			base = task.DueDate
+		}
+
+		next.DueDate = rec.Next(base.DateOnly()).withTimeOf(task.DueDate)
+
+		if task.HasThresholdDate() {
+			next.ThresholdDate = next.DueDate.AddDays(task.ThresholdDate.Sub(task.DueDate)).withTimeOf(task.ThresholdDate)
+		}
+	case task.HasThresholdDate():
+		base := completed
+		if rec.Strict {
+			base = task.ThresholdDate
+		}
+
+		next.ThresholdDate = rec.Next(base.DateOnly()).withTimeOf(task.ThresholdDate)
+	default:
+		next.DueDate = rec.Next(completed.DateOnly())
+	}
+
+	return &next, nil
+}
+
+// Recurrence returns the recurrence rule of the "rec:" tag. It returns an error
+// which wraps ErrTagNotFound if the task is not recurring, or a *TagError if
+// the rule is invalid.
+func (task *Task) Recurrence() (Recurrence, error) {
+	return decodeTagTyped[Recurrence](task, "rec", TagCodecRecurrence)
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of TaskList
+// ----------------------------------------------------------------------------
+
+// CompleteTask completes the task with the given ID. If the task is recurring,
+// the next instance is added to the end of the TaskList with a new ID and it is
+// returned. Otherwise, it returns nil. See Task.NextInstance.
+//
+// Completing an already completed task does not add another instance. If the
+// recurrence rule is invalid, it returns a *TagError and the task is not
+// completed. The options are used to get today. See WithClock.
+func (tasklist *TaskList) CompleteTask(taskID int, opts ...Option) (*Task, error) {
+	task, err := tasklist.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.Completed {
+		return nil, nil //nolint:nilnil // nil task means no instance is added
+	}
+
+	if !task.IsRecurring() {
+		task.Complete(opts...)
+
+		return nil, nil //nolint:nilnil // nil task means no instance is added
+	}
+
+	// Validate the rule first, so that the task is not completed on error
+	if _, err := task.Recurrence(); err != nil {
+		return nil, errors.Wrap(err, "failed to create the next instance of the recurring task")
+	}
+
+	task.Complete(opts...)
+
+	next, err := task.NextInstance(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the next instance of the recurring task")
+	}
+
+	tasklist.AddTask(next)
+
+	return tasklist.GetTask(next.ID)
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// addBusinessDays adds the given number of days to the date, skipping Saturdays
+// and Sundays. The days can be negative to go back.
+func addBusinessDays(from Date, days int) Date {
+	next, step := from, 1
+
+	if days < 0 {
+		days, step = -days, -1
+	}
+
+	for added := 0; added < days; {
+		next = next.AddDays(step)
+
+		if next.Weekday() != time.Saturday && next.Weekday() != time.Sunday {
+			added++
+		}
+	}
+
+	return next
+}
+
+// addMonths adds the given number of months to the date. The day is clamped to
+// the last day of the resulting month.
+func addMonths(from Date, months int) Date {
+	year, month, day := from.Date()
+
+	// Day 0 of the month after is the last day of the target month
+	lastDay := NewDate(year, month+time.Month(months)+1, 0).Day()
+
+	return NewDate(year, month+time.Month(months), min(day, lastDay))
+}
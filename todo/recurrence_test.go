@@ -0,0 +1,161 @@
+package todo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		expect Recurrence
+	}{
+		{input: "1w", expect: Recurrence{Interval: 1, Unit: RecurWeeks, Strict: false}},
+		{input: "+2d", expect: Recurrence{Interval: 2, Unit: RecurDays, Strict: true}},
+		{input: "m", expect: Recurrence{Interval: 1, Unit: RecurMonths, Strict: false}},
+		{input: "5b", expect: Recurrence{Interval: 5, Unit: RecurBusinessDays, Strict: false}},
+		{input: "+1y", expect: Recurrence{Interval: 1, Unit: RecurYears, Strict: true}},
+	} {
+		actual, err := ParseRecurrence(test.input)
+		require.NoError(t, err, "input: %s", test.input)
+		require.Equal(t, test.expect, actual, "input: %s", test.input)
+	}
+
+	for _, input := range []string{"", "1", "1x", "-1d", "0d", "+"} {
+		_, err := ParseRecurrence(input)
+		require.Error(t, err, "input %q should be an error", input)
+	}
+
+	require.Equal(t, "+2d", Recurrence{Interval: 2, Unit: RecurDays, Strict: true}.String())
+}
+
+func TestRecurrence_Next(t *testing.T) {
+	t.Parallel()
+
+	from := NewDate(2020, 1, 31) // Friday
+
+	for _, test := range []struct {
+		rec    string
+		expect string
+	}{
+		{rec: "3d", expect: "2020-02-03"},
+		{rec: "1b", expect: "2020-02-03"},
+		{rec: "6b", expect: "2020-02-10"},
+		{rec: "2w", expect: "2020-02-14"},
+		{rec: "1m", expect: "2020-02-29"},
+		{rec: "3m", expect: "2020-04-30"},
+		{rec: "1y", expect: "2021-01-31"},
+	} {
+		rec, err := ParseRecurrence(test.rec)
+		require.NoError(t, err)
+		require.Equal(t, test.expect, rec.Next(from).Format(DateLayout), "rec: %s", test.rec)
+	}
+}
+
+func TestTask_NextInstance(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		expect string
+	}{
+		// Relative to the completed date
+		{
+			input:  "x 2020-05-10 2020-05-01 Water plants t:2020-05-03 due:2020-05-05 rec:1w",
+			expect: "2020-05-10 Water plants rec:1w t:2020-05-15 due:2020-05-17",
+		},
+		// Strict from the due date
+		{
+			input:  "x 2020-05-10 2020-05-01 Pay rent due:2020-05-01 rec:+1m",
+			expect: "2020-05-10 Pay rent rec:+1m due:2020-06-01",
+		},
+		// Threshold date only
+		{
+			input:  "x 2020-05-10 Review t:2020-05-04 rec:+1w",
+			expect: "Review rec:+1w t:2020-05-11",
+		},
+		// No dates
+		{
+			input:  "x 2020-05-10 Backup rec:2d",
+			expect: "Backup rec:2d due:2020-05-12",
+		},
+	} {
+		task, err := ParseTask(test.input)
+		require.NoError(t, err)
+
+		next, err := task.NextInstance()
+		require.NoError(t, err)
+		require.Equal(t, test.expect, next.String(), "input: %s", test.input)
+		require.True(t, task.Completed, "the original task should not be modified")
+	}
+
+	// Not recurring
+	task, err := ParseTask("Backup")
+	require.NoError(t, err)
+	require.False(t, task.IsRecurring())
+
+	_, err = task.NextInstance()
+	require.ErrorIs(t, err, ErrTagNotFound)
+
+	// Invalid rule is an error on use, not on parsing
+	task, err = ParseTask("Backup rec:often")
+	require.NoError(t, err)
+	require.True(t, task.IsRecurring())
+
+	_, err = task.NextInstance()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `invalid tag "rec:often"`)
+}
+
+func TestTaskList_CompleteTask(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString("Water plants due:2020-05-05 rec:+1w\nCall Mom")
+	require.NoError(t, err)
+
+	next, err := tasklist.CompleteTask(1)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	require.Equal(t, 3, next.ID)
+	require.Equal(t, "Water plants rec:+1w due:2020-05-12", next.String())
+	require.Len(t, tasklist, 3)
+	require.True(t, tasklist[0].Completed)
+
+	// Already completed
+	next, err = tasklist.CompleteTask(1)
+	require.NoError(t, err)
+	require.Nil(t, next)
+	require.Len(t, tasklist, 3)
+
+	// Not recurring
+	next, err = tasklist.CompleteTask(2)
+	require.NoError(t, err)
+	require.Nil(t, next)
+	require.True(t, tasklist[1].Completed)
+
+	// Not found
+	_, err = tasklist.CompleteTask(99)
+	require.Error(t, err)
+}
+
+func TestTaskList_CompleteTask_invalid_rule(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString("Water plants rec:daily\nBackup rec:0d\nCall Mom")
+	require.NoError(t, err, "invalid rules should not fail the load")
+	require.Len(t, tasklist, 3)
+
+	for _, taskID := range []int{1, 2} {
+		_, err = tasklist.CompleteTask(taskID)
+
+		var tagErr *TagError
+
+		require.ErrorAs(t, err, &tagErr, "task ID: %d", taskID)
+		require.False(t, tasklist[taskID-1].Completed, "task should not be completed on error")
+	}
+
+	require.Len(t, tasklist, 3, "no instance should be added on error")
+}
@@ -0,0 +1,429 @@
+package todo
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: ReportFormat
+// ----------------------------------------------------------------------------
+
+// ReportFormat represents the output format of the report. See
+// TaskList.WriteReport.
+type ReportFormat uint8
+
+// Formats of the report.
+const (
+	ReportText     ReportFormat = iota + 1 // ReportText is the plain text with aligned columns.
+	ReportMarkdown                         // ReportMarkdown is the Markdown with a task list per group.
+	ReportHTML                             // ReportHTML is the HTML fragment with a list per group.
+)
+
+// ----------------------------------------------------------------------------
+//  Type: ReportGroupByType
+// ----------------------------------------------------------------------------
+
+// ReportGroupByType represents the grouping of the tasks in the report.
+type ReportGroupByType uint8
+
+// Groupings of the report. The tasks in more than one project or context are
+// listed in each group.
+const (
+	GroupByNone     ReportGroupByType = iota + 1 // GroupByNone is a single group without name.
+	GroupByProject                               // GroupByProject groups by "+project".
+	GroupByContext                               // GroupByContext groups by "@context".
+	GroupByPriority                              // GroupByPriority groups by "(A)" to "(Z)".
+	GroupByDueWeek                               // GroupByDueWeek groups by ISO week of the due date, such as "2020-W10".
+)
+
+// ----------------------------------------------------------------------------
+//  Type: ReportOptions
+// ----------------------------------------------------------------------------
+
+// ReportOptions represents the options of the report. The zero value is the
+// default, which is the plain text of a single group.
+type ReportOptions struct {
+	// Template overrides the built-in template of the Format. The template is
+	// executed with a *Report. The default is nil, which uses the built-in one.
+	// See ReportFuncs.
+	Template *template.Template
+	// Format is the format of the report. The default is ReportText.
+	Format ReportFormat
+	// GroupBy is the grouping of the tasks. The default is GroupByNone.
+	GroupBy ReportGroupByType
+	// Inline writes the tasks from their InlineSegments, in order of the task
+	// line. The default is 'false', which uses Task.Segments.
+	Inline bool
+}
+
+// format returns the format of the report.
+func (o ReportOptions) format() ReportFormat {
+	if o.Format == 0 {
+		return ReportText
+	}
+
+	return o.Format
+}
+
+// groupBy returns the grouping of the tasks.
+func (o ReportOptions) groupBy() ReportGroupByType {
+	if o.GroupBy == 0 {
+		return GroupByNone
+	}
+
+	return o.GroupBy
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Report
+// ----------------------------------------------------------------------------
+
+// Report is the data of a report, which is given to the templates of
+// TaskList.WriteReport.
+type Report struct {
+	Groups  []*ReportGroup // Groups in order of name. The group of the tasks without group is the last.
+	Tasks   []*ReportTask  // Tasks are all the tasks in the report.
+	Today   Date           // Today is the date of the report.
+	Overdue int            // Overdue is the number of the overdue tasks.
+	GroupBy ReportGroupByType
+}
+
+// ReportGroup is a group of tasks in the report.
+type ReportGroup struct {
+	Name      string        // Name of the group. Such as "+Novel", or "No project" for the tasks without project.
+	Tasks     []*ReportTask // Tasks of the group in order of the TaskList.
+	Overdue   int           // Overdue is the number of the overdue tasks in the group.
+	ungrouped bool
+}
+
+// ReportTask is a task in the report.
+type ReportTask struct {
+	Segments []*ReportSegment // Segments are the ones of Task.Segments, without the duplicated contexts and projects.
+	Task     Task
+	Overdue  bool // Overdue is true if the task is not completed and overdue.
+}
+
+// ReportSegment is a segment of a task in the report.
+type ReportSegment struct {
+	TaskSegment
+	Overdue bool // Overdue is true for the due date segment of the overdue tasks, to highlight it.
+}
+
+// Count returns the number of the tasks in the report.
+func (report *Report) Count() int {
+	return len(report.Tasks)
+}
+
+// Count returns the number of the tasks in the group.
+func (group *ReportGroup) Count() int {
+	return len(group.Tasks)
+}
+
+// Body returns the segments after the completion mark and the priority. Such
+// as the created date, the todo text and the tags.
+func (reportTask *ReportTask) Body() []*ReportSegment {
+	body := make([]*ReportSegment, 0, len(reportTask.Segments))
+
+	for _, seg := range reportTask.Segments {
+		if seg.Type != SegmentIsCompleted && seg.Type != SegmentPriority {
+			body = append(body, seg)
+		}
+	}
+
+	return body
+}
+
+// ----------------------------------------------------------------------------
+//  Built-in templates
+// ----------------------------------------------------------------------------
+
+// reportTemplates are the built-in templates of the report formats.
+//
+//nolint:gochecknoglobals // the templates are parsed once and never changed
+var reportTemplates = map[ReportFormat]*template.Template{
+	ReportText: template.Must(template.New("text").Funcs(ReportFuncs()).Parse(
+		`{{define "count"}}{{.Count}}{{if .Overdue}}, {{.Overdue}} overdue{{end}}{{end}}` +
+			`Tasks ({{template "count" .}})` + "\n" +
+			`{{range .Groups}}` + "\n" +
+			`{{if .Name}}{{.Name}} ({{template "count" .}})` + "\n" + `{{end}}` +
+			`{{range .Tasks}}` +
+			`  [{{if .Task.Completed}}x{{else}} {{end}}]` + "\t" +
+			`{{range .Segments}}{{if segmentIs . "Priority"}}{{.Display}}{{end}}{{end}}` + "\t" +
+			`{{range $i, $seg := .Body}}{{if $i}} {{end}}{{$seg.Display}}{{if $seg.Overdue}} (overdue){{end}}{{end}}` +
+			"\n" + `{{end}}{{end}}`,
+	)),
+	ReportMarkdown: template.Must(template.New("markdown").Funcs(ReportFuncs()).Parse(
+		`{{define "count"}}{{.Count}}{{if .Overdue}}, {{.Overdue}} overdue{{end}}{{end}}` +
+			`# Tasks ({{template "count" .}})` + "\n" +
+			`{{range .Groups}}` + "\n" +
+			`{{if .Name}}## {{markdown .Name}} ({{template "count" .}})` + "\n\n" + `{{end}}` +
+			`{{range .Tasks}}` +
+			`- [{{if .Task.Completed}}x{{else}} {{end}}]` +
+			`{{range .Segments}}{{if not (segmentIs . "IsCompleted")}} ` +
+			`{{if .Overdue}}**{{markdown .Display}}**{{else}}{{markdown .Display}}{{end}}` +
+			`{{end}}{{end}}` +
+			"\n" + `{{end}}{{end}}`,
+	)),
+	ReportHTML: template.Must(template.New("html").Funcs(ReportFuncs()).Parse(
+		`{{define "count"}}<small>{{.Count}}{{if .Overdue}}, {{.Overdue}} overdue{{end}}</small>{{end}}` +
+			`<section class="todo-report">` + "\n" +
+			`<h1>Tasks {{template "count" .}}</h1>` + "\n" +
+			`{{range .Groups}}<section class="todo-group">` + "\n" +
+			`{{if .Name}}<h2>{{html .Name}} {{template "count" .}}</h2>` + "\n" + `{{end}}` +
+			`<ul>` + "\n" +
+			`{{range .Tasks}}` +
+			`<li class="todo-task{{if .Task.Completed}} completed{{end}}{{if .Overdue}} overdue{{end}}">` +
+			`{{range $i, $seg := .Segments}}{{if $i}} {{end}}` +
+			`{{if $seg.Overdue}}<mark class="{{$seg.Type}}">{{html $seg.Display}}</mark>` +
+			`{{else}}<span class="{{$seg.Type}}">{{html $seg.Display}}</span>{{end}}` +
+			`{{end}}</li>` + "\n" +
+			`{{end}}</ul>` + "\n" + `</section>` + "\n" +
+			`{{end}}</section>` + "\n",
+	)),
+}
+
+// ReportFuncs returns the functions available in the templates of the report,
+// to use them in the user-supplied templates as well. Such as:
+//
+//	tmpl, err := template.New("digest").Funcs(todo.ReportFuncs()).Parse(text)
+//
+// The functions are:
+//
+//   - markdown: escapes the Markdown special characters of the string.
+//   - segmentIs: reports whether the *ReportSegment is of the TaskSegmentType
+//     name, such as {{if segmentIs . "DueDate"}}.
+//
+// Note that the built-in "html" function of text/template escapes HTML.
+func ReportFuncs() template.FuncMap {
+	return template.FuncMap{
+		"markdown": escapeMarkdown,
+		"segmentIs": func(seg *ReportSegment, name string) bool {
+			return seg.Type.String() == name
+		},
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of TaskList
+// ----------------------------------------------------------------------------
+
+// Report returns the data of the report of the tasks, grouped by the GroupBy
+// of the ReportOptions. Use it to render the report by yourself. Non-task
+// entries are not included.
+func (tasklist *TaskList) Report(reportOpts ReportOptions, opts ...Option) *Report {
+	return tasklist.report(reportOpts, NewConfig(opts...))
+}
+
+// WriteReport writes the report of the tasks in the Format of the ReportOptions,
+// grouped by the GroupBy, such as:
+//
+//	err := tasklist.WriteReport(os.Stdout, todo.ReportOptions{
+//	    Format:  todo.ReportMarkdown,
+//	    GroupBy: todo.GroupByProject,
+//	})
+//
+// Each group has the count of the tasks and of the overdue ones. The tasks are
+// written from their segments, see Task.Segments, and the due dates of the
+// overdue tasks are highlighted.
+//
+// To change the layout, give a text/template as the Template of the options.
+// The template is executed with the *Report, and the functions of ReportFuncs
+// are available. Such as:
+//
+//	tmpl := template.Must(template.New("digest").Funcs(todo.ReportFuncs()).Parse(
+//	    `{{range .Groups}}{{.Name}}: {{.Count}}{{"\n"}}{{end}}`,
+//	))
+//
+//	err := tasklist.WriteReport(os.Stdout, todo.ReportOptions{Template: tmpl})
+//
+// The options are used to get "today" and to write the segments. See Config.
+func (tasklist *TaskList) WriteReport(writer io.Writer, reportOpts ReportOptions, opts ...Option) error {
+	cfg := NewConfig(opts...)
+
+	tmpl := reportOpts.Template
+	if tmpl == nil {
+		builtin, ok := reportTemplates[reportOpts.format()]
+		if !ok {
+			return errors.Errorf("unknown report format %d", reportOpts.Format)
+		}
+
+		tmpl = builtin
+	}
+
+	// Align the columns of the built-in plain text
+	var tabWriter *tabwriter.Writer
+
+	if reportOpts.Template == nil && reportOpts.format() == ReportText {
+		tabWriter = tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0) //nolint:mnd // padding of 2 spaces
+		writer = tabWriter
+	}
+
+	if err := tmpl.Execute(writer, tasklist.report(reportOpts, cfg)); err != nil {
+		return errors.Wrap(err, "failed to write report")
+	}
+
+	if tabWriter != nil {
+		return errors.Wrap(tabWriter.Flush(), "failed to write report")
+	}
+
+	return nil
+}
+
+// report is the same as Report but with the given configuration.
+func (tasklist *TaskList) report(reportOpts ReportOptions, cfg *Config) *Report {
+	report := &Report{
+		Groups:  nil,
+		Tasks:   nil,
+		Today:   cfg.today(),
+		Overdue: 0,
+		GroupBy: reportOpts.groupBy(),
+	}
+
+	groups := map[string]*ReportGroup{}
+
+	for i := range *tasklist {
+		task := &(*tasklist)[i]
+		if task.NonTask {
+			continue
+		}
+
+		reportTask := newReportTask(task, reportOpts.Inline, cfg)
+		report.Tasks = append(report.Tasks, reportTask)
+
+		if reportTask.Overdue {
+			report.Overdue++
+		}
+
+		names, ungrouped := reportGroupNames(task, report.GroupBy)
+
+		for _, name := range names {
+			group, ok := groups[name]
+			if !ok {
+				group = &ReportGroup{Name: name, Tasks: nil, Overdue: 0, ungrouped: ungrouped}
+				groups[name] = group
+				report.Groups = append(report.Groups, group)
+			}
+
+			group.Tasks = append(group.Tasks, reportTask)
+
+			if reportTask.Overdue {
+				group.Overdue++
+			}
+		}
+	}
+
+	slices.SortStableFunc(report.Groups, func(a, b *ReportGroup) int {
+		if a.ungrouped != b.ungrouped {
+			if a.ungrouped {
+				return 1
+			}
+
+			return -1
+		}
+
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return report
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// escapeMarkdown escapes the characters which have meanings in Markdown.
+func escapeMarkdown(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"`", "\\`",
+		"*", `\*`,
+		"_", `\_`,
+		"[", `\[`,
+		"]", `\]`,
+		"<", `\<`,
+		">", `\>`,
+	).Replace(text)
+}
+
+// newReportTask returns the task in the report with its segments. They are the
+// inline segments if inline is 'true'.
+func newReportTask(task *Task, inline bool, cfg *Config) *ReportTask {
+	reportTask := &ReportTask{
+		Segments: nil,
+		Task:     *task,
+		Overdue:  !task.Completed && task.isOverdue(cfg),
+	}
+
+	segs := task.Segments(WithConfig(*cfg))
+	if inline {
+		segs = task.inlineSegments(cfg)
+	}
+
+	for _, seg := range segs {
+		// The contexts and projects in the todo text are already displayed
+		if !inline && (seg.Type == SegmentContext || seg.Type == SegmentProject) &&
+			strings.Contains(task.Todo, seg.Display) {
+			continue
+		}
+
+		reportTask.Segments = append(reportTask.Segments, &ReportSegment{
+			TaskSegment: *seg,
+			Overdue:     reportTask.Overdue && seg.Type == SegmentDueDate,
+		})
+	}
+
+	return reportTask
+}
+
+// reportGroupNames returns the names of the groups of the task. It returns true
+// if the task is in none of the groups, such as "No project".
+func reportGroupNames(task *Task, groupBy ReportGroupByType) ([]string, bool) {
+	var (
+		names []string
+		none  string
+	)
+
+	switch groupBy {
+	case GroupByProject:
+		for _, project := range task.Projects {
+			names = append(names, "+"+project)
+		}
+
+		none = "No project"
+	case GroupByContext:
+		for _, context := range task.Contexts {
+			names = append(names, "@"+context)
+		}
+
+		none = "No context"
+	case GroupByPriority:
+		if task.HasPriority() {
+			names = append(names, "("+task.Priority+")")
+		}
+
+		none = "No priority"
+	case GroupByDueWeek:
+		if task.HasDueDate() {
+			year, week := task.DueDate.ISOWeek()
+			names = append(names, fmt.Sprintf("%d-W%02d", year, week))
+		}
+
+		none = "No due date"
+	default:
+		return []string{emptyStr}, false
+	}
+
+	if len(names) == 0 {
+		return []string{none}, true
+	}
+
+	return names, false
+}
@@ -0,0 +1,180 @@
+package todo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testReportTasks = "(B) 2020-03-01 Outline chapter_5 +Novel @Computer due:2020-03-02\n" +
+	"x 2020-03-03 Call Mom @phone due:2020-03-01\n" +
+	"(A) Write <draft> +Novel +Blog due:2020-03-20 est:3\n"
+
+func testReportOptions(opts ...Option) []Option {
+	now := time.Date(2020, 3, 8, 12, 0, 0, 0, time.UTC)
+
+	return append([]Option{WithClock(FixedClock(now)), WithLocation(time.UTC)}, opts...)
+}
+
+func TestTaskList_WriteReport_text(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString(testReportTasks)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, tasklist.WriteReport(&buf, ReportOptions{GroupBy: GroupByProject}, testReportOptions()...))
+	require.Equal(t, "Tasks (3, 1 overdue)\n"+
+		"\n"+
+		"+Blog (1)\n"+
+		"  [ ]  (A)  Write <draft> +Novel +Blog est:3 due:2020-03-20\n"+
+		"\n"+
+		"+Novel (2, 1 overdue)\n"+
+		"  [ ]  (B)  2020-03-01 Outline chapter_5 +Novel @Computer due:2020-03-02 (overdue)\n"+
+		"  [ ]  (A)  Write <draft> +Novel +Blog est:3 due:2020-03-20\n"+
+		"\n"+
+		"No project (1)\n"+
+		"  [x]    2020-03-03 Call Mom @phone due:2020-03-01\n",
+		buf.String(), "completed tasks should not be overdue")
+
+	// Default is the plain text of a single group
+	buf.Reset()
+
+	require.NoError(t, tasklist.WriteReport(&buf, ReportOptions{}, testReportOptions()...))
+	require.Equal(t, "Tasks (3, 1 overdue)\n"+
+		"\n"+
+		"  [ ]  (B)  2020-03-01 Outline chapter_5 +Novel @Computer due:2020-03-02 (overdue)\n"+
+		"  [x]       2020-03-03 Call Mom @phone due:2020-03-01\n"+
+		"  [ ]  (A)  Write <draft> +Novel +Blog est:3 due:2020-03-20\n",
+		buf.String())
+}
+
+func TestTaskList_WriteReport_markdown(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString(testReportTasks)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, tasklist.WriteReport(&buf,
+		ReportOptions{Format: ReportMarkdown, GroupBy: GroupByPriority}, testReportOptions()...))
+	require.Equal(t, "# Tasks (3, 1 overdue)\n"+
+		"\n"+
+		"## (A) (1)\n"+
+		"\n"+
+		"- [ ] (A) Write \\<draft\\> +Novel +Blog est:3 due:2020-03-20\n"+
+		"\n"+
+		"## (B) (1, 1 overdue)\n"+
+		"\n"+
+		"- [ ] (B) 2020-03-01 Outline chapter\\_5 +Novel @Computer **due:2020-03-02**\n"+
+		"\n"+
+		"## No priority (1)\n"+
+		"\n"+
+		"- [x] 2020-03-03 Call Mom @phone due:2020-03-01\n",
+		buf.String())
+}
+
+func TestTaskList_WriteReport_html(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString(testReportTasks)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, tasklist.WriteReport(&buf,
+		ReportOptions{Format: ReportHTML, GroupBy: GroupByContext}, testReportOptions()...))
+
+	output := buf.String()
+	require.True(t, strings.HasPrefix(output,
+		"<section class=\"todo-report\">\n<h1>Tasks <small>3, 1 overdue</small></h1>\n"))
+	require.Contains(t, output, "<h2>@Computer <small>1, 1 overdue</small></h2>\n")
+	require.Contains(t, output, `<li class="todo-task overdue"><span class="Priority">(B)</span> `)
+	require.Contains(t, output, `<mark class="DueDate">due:2020-03-02</mark>`)
+	require.Contains(t, output, `<li class="todo-task completed"><span class="IsCompleted">x</span> `)
+	require.Contains(t, output, `<span class="TodoText">Write &lt;draft&gt; +Novel +Blog</span>`)
+	require.Less(t, strings.Index(output, "@phone <small>"), strings.Index(output, "No context <small>"),
+		"the group without context should be the last")
+}
+
+func TestTaskList_Report(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString(
+		"Call Mom @phone due:2020-03-10\nWrite report +work @office @phone due:2020-03-02\n# comment\nPlan trip",
+		WithKeepNonTaskLines(true),
+	)
+	require.NoError(t, err)
+
+	report := tasklist.Report(ReportOptions{GroupBy: GroupByDueWeek}, testReportOptions()...)
+	require.Equal(t, 3, report.Count(), "non-task entries should not be included")
+	require.Equal(t, NewDate(2020, 3, 8), report.Today)
+	require.Len(t, report.Groups, 3)
+
+	for i, expect := range []struct {
+		name  string
+		count int
+	}{
+		{name: "2020-W10", count: 1},
+		{name: "2020-W11", count: 1},
+		{name: "No due date", count: 1},
+	} {
+		require.Equal(t, expect.name, report.Groups[i].Name)
+		require.Equal(t, expect.count, report.Groups[i].Count())
+	}
+
+	// Tasks in more than one context are in each group
+	report = tasklist.Report(ReportOptions{GroupBy: GroupByContext}, testReportOptions()...)
+	require.Equal(t, "@phone", report.Groups[1].Name)
+	require.Equal(t, 2, report.Groups[1].Count())
+	require.Equal(t, 1, report.Groups[1].Overdue)
+
+	// Contexts and projects in the todo text are not duplicated
+	task := report.Groups[1].Tasks[1]
+	require.Len(t, task.Body(), 2)
+	require.Equal(t, "Write report +work @office @phone", task.Body()[0].Display)
+	require.True(t, task.Body()[1].Overdue)
+
+	// Inline segments are in order of the task line
+	report = tasklist.Report(ReportOptions{GroupBy: GroupByContext, Inline: true}, testReportOptions()...)
+	task = report.Groups[1].Tasks[1]
+
+	displays := make([]string, 0, len(task.Segments))
+	for _, seg := range task.Segments {
+		displays = append(displays, seg.Display)
+	}
+
+	require.Equal(t, []string{"Write report", "+work", "@office", "@phone", "due:2020-03-02"}, displays)
+	require.True(t, task.Segments[4].Overdue)
+}
+
+func TestTaskList_WriteReport_template(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString(testReportTasks)
+	require.NoError(t, err)
+
+	tmpl := template.Must(template.New("digest").Funcs(ReportFuncs()).Parse(
+		`{{range .Groups}}{{.Name}}: {{.Count}}` +
+			`{{range .Tasks}}{{range .Segments}}{{if segmentIs . "DueDate"}} {{markdown .Display}}{{end}}{{end}}{{end}}` +
+			"\n{{end}}",
+	))
+
+	var buf bytes.Buffer
+
+	require.NoError(t, tasklist.WriteReport(&buf,
+		ReportOptions{Template: tmpl, GroupBy: GroupByProject}, testReportOptions()...))
+	require.Equal(t, "+Blog: 1 due:2020-03-20\n+Novel: 2 due:2020-03-02 due:2020-03-20\nNo project: 1 due:2020-03-01\n",
+		buf.String())
+
+	require.Error(t, tasklist.WriteReport(&buf, ReportOptions{Format: ReportFormat(99)}))
+
+	broken := template.Must(template.New("broken").Parse(`{{.Unknown}}`))
+	require.Error(t, tasklist.WriteReport(&buf, ReportOptions{Template: broken}))
+}
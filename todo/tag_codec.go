@@ -0,0 +1,286 @@
+package todo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: TagCodec
+// ----------------------------------------------------------------------------
+
+// TagCodec converts the value of an additional tag between its string form in
+// todo.txt and a Go value.
+//
+// A TagCodec can be registered for a tag key with RegisterTagCodec. Then the
+// value of the tag is validated on parsing, and Task.TagValue and
+// Task.SetTagValue use it to decode and encode the value.
+type TagCodec interface {
+	// Decode converts the string value of a tag to a Go value.
+	Decode(value string) (any, error)
+	// Encode converts a Go value to the string value of a tag.
+	Encode(value any) (string, error)
+}
+
+// Built-in TagCodecs for the typed accessors of Task.
+//
+//nolint:gochecknoglobals // global variables are intentional as they are stateless
+var (
+	// TagCodecBool converts a tag value to a bool. Such as "true", "false", "yes"
+	// and "no".
+	TagCodecBool TagCodec = tagCodec[bool]{decode: parseTagBool, encode: strconv.FormatBool}
+	// TagCodecDate converts a tag value to a Date in DateLayout.
+	TagCodecDate TagCodec = tagCodec[Date]{decode: ParseDate, encode: Date.String}
+	// TagCodecDuration converts a tag value to a time.Duration. In addition to
+	// the units of time.ParseDuration, "d" for days and "w" for weeks are
+	// supported. Such as "2w", "1d12h" and "90m".
+	TagCodecDuration TagCodec = tagCodec[time.Duration]{decode: parseTagDuration, encode: formatTagDuration}
+	// TagCodecInt converts a tag value to an int.
+	TagCodecInt TagCodec = tagCodec[int]{decode: strconv.Atoi, encode: strconv.Itoa}
+	// TagCodecList converts a comma separated tag value to a []string.
+	TagCodecList TagCodec = tagCodec[[]string]{decode: parseTagList, encode: formatTagList}
+	// TagCodecRecurrence converts a tag value to a Recurrence. Such as "1w" or
+	// "+2d". The "rec" tag is only decoded on use, such as Task.Recurrence.
+	// Register it for the "rec" tag to validate the rules on loading as well.
+	TagCodecRecurrence TagCodec = tagCodec[Recurrence]{decode: ParseRecurrence, encode: Recurrence.String}
+	// TagCodecString keeps a tag value as a string.
+	TagCodecString TagCodec = tagCodec[string]{decode: parseTagString, encode: formatTagString}
+)
+
+// ----------------------------------------------------------------------------
+//  Type: TagError
+// ----------------------------------------------------------------------------
+
+// ErrTagNotFound is returned by the typed tag accessors of Task if the task
+// does not have the tag.
+var ErrTagNotFound = errors.New("tag not found")
+
+// TagError represents an error of a tag value which failed to be decoded or
+// encoded. It tells which tag failed.
+type TagError struct {
+	Err   error  // Err is the underlying error.
+	Key   string // Key of the tag.
+	Value string // Value of the tag. Empty if the tag was not found or failed to be encoded.
+}
+
+// Error returns the error message with the tag.
+func (e *TagError) Error() string {
+	return fmt.Sprintf("invalid tag %q: %v", e.Key+":"+e.Value, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// ----------------------------------------------------------------------------
+//  Registry
+// ----------------------------------------------------------------------------
+
+// tagCodecs holds the registered TagCodecs by tag key. None is registered by
+// default, so that any value of a tag is loaded as it is.
+//
+//nolint:gochecknoglobals // global variable is intentional as a registry
+var tagCodecs = struct {
+	codecs map[string]TagCodec
+	sync.RWMutex
+}{
+	codecs: map[string]TagCodec{},
+}
+
+// LookupTagCodec returns the TagCodec registered for the given tag key.
+func LookupTagCodec(key string) (TagCodec, bool) {
+	tagCodecs.RLock()
+	defer tagCodecs.RUnlock()
+
+	codec, ok := tagCodecs.codecs[key]
+
+	return codec, ok
+}
+
+// RegisterTagCodec registers the TagCodec for the given tag key. If codec is
+// nil, the registered TagCodec of the key is removed.
+//
+// Once registered, the values of the tag are validated on parsing tasks and an
+// invalid value is reported as a ParseError which wraps a TagError. It is
+// usually called in an init function, for example:
+//
+//	todo.RegisterTagCodec("est", todo.TagCodecDuration)
+//
+// Note that the "due" and "t" tags are parsed into Task.DueDate and
+// Task.ThresholdDate respectively, and are not affected.
+func RegisterTagCodec(key string, codec TagCodec) {
+	tagCodecs.Lock()
+	defer tagCodecs.Unlock()
+
+	if codec == nil {
+		delete(tagCodecs.codecs, key)
+
+		return
+	}
+
+	tagCodecs.codecs[key] = codec
+}
+
+// validateTag validates the value of the tag with the registered TagCodec, if
+// any.
+func validateTag(key, value string) error {
+	codec, ok := LookupTagCodec(key)
+	if !ok {
+		return nil
+	}
+
+	if _, err := codec.Decode(value); err != nil {
+		return &TagError{Err: err, Key: key, Value: value}
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Type: tagCodec
+// ----------------------------------------------------------------------------
+
+// tagCodec is a generic implementation of TagCodec for the type T.
+type tagCodec[T any] struct {
+	decode func(value string) (T, error)
+	encode func(value T) string
+}
+
+// Decode converts the string value of a tag to T.
+func (c tagCodec[T]) Decode(value string) (any, error) {
+	decoded, err := c.decode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+// Encode converts T to the string value of a tag. It returns an error if the
+// value is not of type T.
+func (c tagCodec[T]) Encode(value any) (string, error) {
+	typed, ok := value.(T)
+	if !ok {
+		return emptyStr, errors.Errorf("unsupported type %T, expected %T", value, typed)
+	}
+
+	return c.encode(typed), nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// durationRx matches the leading weeks and days of a duration. Such as "2w",
+// "1d12h" or "1w2d".
+var durationRx = regexp.MustCompile(`^(?:(\d+)w)?(?:(\d+)d)?(.*)$`)
+
+// formatTagDuration formats the duration with days as the largest unit. Such as
+// "1d12h" or "90m".
+func formatTagDuration(value time.Duration) string {
+	if value == 0 {
+		return "0s"
+	}
+
+	var strBld strings.Builder
+
+	if value < 0 {
+		strBld.WriteString("-")
+
+		value = -value
+	}
+
+	for _, unit := range []struct {
+		name string
+		size time.Duration
+	}{
+		{"d", oneDay},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	} {
+		if value >= unit.size {
+			strBld.WriteString(strconv.FormatInt(int64(value/unit.size), 10) + unit.name)
+
+			value %= unit.size
+		}
+	}
+
+	if value > 0 {
+		strBld.WriteString(value.String())
+	}
+
+	return strBld.String()
+}
+
+func formatTagList(value []string) string {
+	return strings.Join(value, ",")
+}
+
+func formatTagString(value string) string {
+	return value
+}
+
+func parseTagBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+
+	return parsed, errors.Wrap(err, "failed to parse bool")
+}
+
+func parseTagDuration(value string) (time.Duration, error) {
+	match := durationRx.FindStringSubmatch(value)
+
+	var parsed time.Duration
+
+	for i, unit := range []time.Duration{7 * oneDay, oneDay} {
+		if isNotEmpty(match[i+1]) {
+			num, err := strconv.Atoi(match[i+1])
+			if err != nil {
+				return 0, errors.Wrap(err, "failed to parse duration")
+			}
+
+			parsed += time.Duration(num) * unit
+		}
+	}
+
+	if rest := match[3]; isNotEmpty(rest) || parsed == 0 {
+		dur, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to parse duration")
+		}
+
+		parsed += dur
+	}
+
+	return parsed, nil
+}
+
+func parseTagList(value string) ([]string, error) {
+	list := []string{}
+
+	for _, item := range strings.Split(value, ",") {
+		if isNotEmpty(item) {
+			list = append(list, item)
+		}
+	}
+
+	return list, nil
+}
+
+func parseTagString(value string) (string, error) {
+	return value, nil
+}
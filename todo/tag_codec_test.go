@@ -0,0 +1,100 @@
+package todo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagCodecDuration(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input   string
+		encoded string
+		expect  time.Duration
+	}{
+		{input: "2w", encoded: "14d", expect: 14 * oneDay},
+		{input: "1w2d", encoded: "9d", expect: 9 * oneDay},
+		{input: "1d12h", encoded: "1d12h", expect: oneDay + 12*time.Hour},
+		{input: "90m", encoded: "1h30m", expect: 90 * time.Minute},
+		{input: "0", encoded: "0s", expect: 0},
+	} {
+		decoded, err := TagCodecDuration.Decode(test.input)
+		require.NoError(t, err, "input: %s", test.input)
+		require.Equal(t, test.expect, decoded, "input: %s", test.input)
+
+		encoded, err := TagCodecDuration.Encode(decoded)
+		require.NoError(t, err)
+		require.Equal(t, test.encoded, encoded, "input: %s", test.input)
+	}
+
+	for _, input := range []string{"", "1x", "d", "2w3"} {
+		_, err := TagCodecDuration.Decode(input)
+		require.Error(t, err, "input %q should be an error", input)
+	}
+}
+
+func TestTagCodec_Encode_unsupported_type(t *testing.T) {
+	t.Parallel()
+
+	_, err := TagCodecInt.Encode("10")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported type string, expected int")
+}
+
+//nolint:paralleltest // do not parallel to avoid race conditions
+func TestRegisterTagCodec(t *testing.T) {
+	RegisterTagCodec("est", TagCodecDuration)
+
+	defer RegisterTagCodec("est", nil)
+
+	codec, ok := LookupTagCodec("est")
+	require.True(t, ok)
+	require.NotNil(t, codec)
+
+	// Valid value
+	task, err := ParseTask("Write report est:2h")
+	require.NoError(t, err)
+
+	value, err := task.TagValue("est")
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Hour, value)
+
+	// Invalid value
+	_, err = ParseTask("Write report est:soon")
+	require.Error(t, err, "invalid tag value should be an error on parse")
+
+	var parseErr *ParseError
+
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, SegmentTag, parseErr.Segment)
+	require.Equal(t, "est:soon", parseErr.Value)
+	require.Equal(t, 13, parseErr.Offset)
+
+	var tagErr *TagError
+
+	require.ErrorAs(t, err, &tagErr, "it should wrap a TagError")
+	require.Equal(t, "est", tagErr.Key)
+	require.Equal(t, "soon", tagErr.Value)
+
+	// Setters validate the value as well, so that the task line can be loaded
+	require.ErrorAs(t, task.SetTag("est", "abc"), &tagErr)
+	require.ErrorAs(t, task.AddTag("est", "abc"), &tagErr)
+	require.Equal(t, "abc", tagErr.Value)
+	require.Equal(t, "Write report est:2h", task.String(), "invalid values should not be set")
+
+	require.NoError(t, task.SetTag("est", "30m"))
+	require.Equal(t, "Write report est:30m", task.String())
+
+	// Unregister
+	RegisterTagCodec("est", nil)
+
+	_, ok = LookupTagCodec("est")
+	require.False(t, ok)
+
+	_, err = ParseTask("Write report est:soon")
+	require.NoError(t, err, "unregistered tags should not be validated")
+}
@@ -0,0 +1,147 @@
+package todo
+
+import (
+	"slices"
+	"sort"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Tag
+// ----------------------------------------------------------------------------
+
+// Tag represents an additional tag of a task in a key:value format.
+type Tag struct {
+	Key   string // Key of the tag. Such as "dep" of "dep:3".
+	Value string // Value of the tag. Such as "3" of "dep:3".
+}
+
+// String returns the tag in a key:value format.
+func (tag Tag) String() string {
+	return tag.Key + ":" + tag.Value
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Tags
+// ----------------------------------------------------------------------------
+
+// Tags is a list of additional tags in order of appearance. The same key may
+// appear more than once, such as "dep:3 dep:7".
+type Tags []Tag
+
+// Add appends a tag with the given key and value.
+func (tags *Tags) Add(key, value string) {
+	*tags = append(*tags, Tag{Key: key, Value: value})
+}
+
+// Get returns the first value of the tag with the given key. It returns false
+// if there is no tag with the key.
+func (tags Tags) Get(key string) (string, bool) {
+	for _, tag := range tags {
+		if tag.Key == key {
+			return tag.Value, true
+		}
+	}
+
+	return emptyStr, false
+}
+
+// Has returns true if there is any tag with the given key.
+func (tags Tags) Has(key string) bool {
+	_, ok := tags.Get(key)
+
+	return ok
+}
+
+// Keys returns the unique keys of the tags in order of appearance.
+func (tags Tags) Keys() []string {
+	keys := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		if !slices.Contains(keys, tag.Key) {
+			keys = append(keys, tag.Key)
+		}
+	}
+
+	return keys
+}
+
+// Remove removes all the tags with the given key.
+func (tags *Tags) Remove(key string) {
+	*tags = slices.DeleteFunc(*tags, func(tag Tag) bool {
+		return tag.Key == key
+	})
+}
+
+// RemoveValue removes the tags with the given key and value.
+func (tags *Tags) RemoveValue(key, value string) {
+	*tags = slices.DeleteFunc(*tags, func(tag Tag) bool {
+		return tag.Key == key && tag.Value == value
+	})
+}
+
+// Set sets the value of the tag with the given key. If there are more than one
+// tag with the key, the first one is updated and the others are removed. If
+// there is no tag with the key, it is appended.
+func (tags *Tags) Set(key, value string) {
+	found := false
+	updated := (*tags)[:0]
+
+	for _, tag := range *tags {
+		if tag.Key == key {
+			if found {
+				continue
+			}
+
+			found = true
+			tag.Value = value
+		}
+
+		updated = append(updated, tag)
+	}
+
+	*tags = updated
+
+	if !found {
+		tags.Add(key, value)
+	}
+}
+
+// Values returns all the values of the tags with the given key in order of
+// appearance.
+func (tags Tags) Values(key string) []string {
+	var values []string
+
+	for _, tag := range tags {
+		if tag.Key == key {
+			values = append(values, tag.Value)
+		}
+	}
+
+	return values
+}
+
+// grouped returns a copy of the tags grouped by key, in order of the first
+// appearance of the keys. The values of the same key keep their order.
+func (tags Tags) grouped() Tags {
+	grouped := make(Tags, 0, len(tags))
+
+	for _, key := range tags.Keys() {
+		for _, value := range tags.Values(key) {
+			grouped = append(grouped, Tag{Key: key, Value: value})
+		}
+	}
+
+	return grouped
+}
+
+// sorted returns a copy of the tags sorted by key. The values of the same key
+// keep their order of appearance.
+func (tags Tags) sorted() Tags {
+	sorted := slices.Clone(tags)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	return sorted
+}
@@ -0,0 +1,61 @@
+package todo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+
+	tags := Tags{}
+
+	tags.Add("dep", "3")
+	tags.Add("est", "2h")
+	tags.Add("dep", "7")
+
+	value, ok := tags.Get("dep")
+	require.True(t, ok)
+	require.Equal(t, "3", value, "it should return the first value")
+	require.Equal(t, []string{"3", "7"}, tags.Values("dep"))
+	require.Equal(t, []string{"dep", "est"}, tags.Keys())
+	require.True(t, tags.Has("est"))
+
+	tags.RemoveValue("dep", "3")
+	require.Equal(t, []string{"7"}, tags.Values("dep"))
+
+	tags.Add("dep", "9")
+	tags.Set("dep", "1")
+	require.Equal(t, Tags{{Key: "est", Value: "2h"}, {Key: "dep", Value: "1"}}, tags,
+		"set should replace all the values with one at the position of the first")
+
+	tags.Set("who", "bob")
+	tags.Remove("dep")
+	require.Equal(t, Tags{{Key: "est", Value: "2h"}, {Key: "who", Value: "bob"}}, tags)
+
+	_, ok = tags.Get("dep")
+	require.False(t, ok)
+	require.Nil(t, tags.Values("dep"))
+}
+
+func TestTask_multi_valued_tags(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("Deploy dep:3 est:2h dep:7")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"3", "7"}, task.TagValues("dep"))
+	require.Equal(t, "Deploy dep:3 dep:7 est:2h", task.String(), "all the values should be written")
+
+	require.NoError(t, task.AddTag("dep", "9"))
+	task.RemoveTagValue("dep", "3")
+	require.Equal(t, "Deploy dep:7 dep:9 est:2h", task.String())
+
+	// Lossless serialization keeps the positions of the values
+	task, err = ParseTask("Deploy dep:3 est:2h dep:7", WithPreserveOriginal(true))
+	require.NoError(t, err)
+
+	require.NoError(t, task.AddTag("dep", "9"))
+	require.Equal(t, "Deploy dep:3 est:2h dep:7 dep:9", task.StringWith(WithPreserveOriginal(true)))
+}
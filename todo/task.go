@@ -25,33 +25,48 @@ import (
 //
 //nolint:godox,recvcheck // False positive TODO in the comment. Stringer requires non-pointer receiver for String()
 type Task struct {
-	DueDate        time.Time         // DueDate is the due date calculated from the 'due:' tag.
-	CompletedDate  time.Time         // CompletedDate is the date the task was completed.
-	CreatedDate    time.Time         // CreatedDate is the date the task was created.
-	AdditionalTags map[string]string // AdditionalTags of the task in a key:value format (e.g. "due:2012-12-12")
-	Original       string            // Original raw task text.
-	Priority       string            // Priority of the task in (A)-(Z) range.
-	Todo           string            // Todo part of task text.
-	Contexts       []string          // Contexts of the task (e.g. @MyContext).
-	Projects       []string          // Projects of the task (e.g. +MyProject).
-	ID             int               // ID of the task internaly.
-	Completed      bool              // Completed flag. If true, the task has been completed.
+	DueDate        Date        // DueDate is the due date calculated from the 'due:' tag.
+	ThresholdDate  Date        // ThresholdDate is the date from which the task is active, calculated from the 't:' tag.
+	CompletedDate  Date        // CompletedDate is the date the task was completed.
+	CreatedDate    Date        // CreatedDate is the date the task was created.
+	AdditionalTags Tags        // AdditionalTags of the task in key:value format in order (e.g. "dep:3 dep:7").
+	origin         *taskOrigin // origin holds the parsed state of the task for lossless serialization.
+	Original       string      // Original raw task text.
+	Priority       string      // Priority of the task in (A)-(Z) range.
+	Todo           string      // Todo part of task text.
+	Contexts       []string    // Contexts of the task (e.g. @MyContext).
+	Projects       []string    // Projects of the task (e.g. +MyProject).
+	ID             int         // ID of the task internaly.
+	Completed      bool        // Completed flag. If true, the task has been completed.
+	NonTask        bool        // NonTask flag. If true, the entry is a comment or blank line kept in Original.
 }
 
 // ----------------------------------------------------------------------------
 //  Constructors
 // ----------------------------------------------------------------------------
 
-// NewTask creates a new empty Task with default values. (CreatedDate is set to Now()).
-func NewTask() Task {
+// NewTask creates a new empty Task with default values. (CreatedDate is set to
+// today, or to now with the WithDateTime(true) option).
+func NewTask(opts ...Option) Task {
 	task := new(Task)
-	task.CreatedDate = time.Now()
+	task.CreatedDate = NewConfig(opts...).now()
 
 	return *task
 }
 
 // ParseTask parses the input text string into a Task struct.
-func ParseTask(text string) (*Task, error) {
+//
+// If a segment of the text, such as a date, is invalid, the returned error
+// wraps a *ParseError which tells the position of the segment.
+//
+// The options are used to change the default configuration. See Config.
+func ParseTask(text string, opts ...Option) (*Task, error) {
+	return parseTask(text, NewConfig(opts...))
+}
+
+// parseTask parses the input text string into a Task struct with the given
+// configuration.
+func parseTask(text string, cfg *Config) (*Task, error) {
 	var err error
 
 	oriText := strings.Trim(text, whitespaces)
@@ -61,19 +76,19 @@ func ParseTask(text string) (*Task, error) {
 
 	// Check for completed (has 'x ' at the beginning)
 	if completedRx.MatchString(oriText) {
-		if err := parseCompleted(oriText, task); err != nil {
+		if err := parseCompleted(oriText, task, cfg); err != nil {
 			return nil, errors.Wrap(err, "failed to parse task")
 		}
 	}
 
 	// Check for priority
-	if priorityRx.MatchString(oriText) {
-		parsePriority(oriText, task)
+	if cfg.priorityRx().MatchString(oriText) {
+		parsePriority(oriText, task, cfg)
 	}
 
 	// Check for created date
-	if createdDateRx.MatchString(oriText) {
-		if err := parseCreatedDate(oriText, task); err != nil {
+	if cfg.createdDateRx().MatchString(oriText) {
+		if err := parseCreatedDate(oriText, task, cfg); err != nil {
 			return nil, errors.Wrap(err, "failed to parse task")
 		}
 	}
@@ -91,8 +106,8 @@ func ParseTask(text string) (*Task, error) {
 	}
 
 	// Check for additional tags
-	if addonTagRx.MatchString(oriText) {
-		if err := parseAdditionalTags(oriText, task); err != nil {
+	if cfg.ParseTags && cfg.addonTagRx().MatchString(oriText) {
+		if err := parseAdditionalTags(oriText, task, cfg); err != nil {
 			return nil, errors.Wrap(err, "failed to parse task")
 		}
 	}
@@ -100,6 +115,9 @@ func ParseTask(text string) (*Task, error) {
 	// Trim any remaining whitespaces from Todo text
 	task.Todo = strings.Trim(task.Todo, "\t\n\r\f ")
 
+	// Keep the parsed state to serialize the task losslessly later
+	task.origin = newTaskOrigin(task, cfg)
+
 	return task, err
 }
 
@@ -108,21 +126,31 @@ func ParseTask(text string) (*Task, error) {
 // ----------------------------------------------------------------------------
 
 // Complete sets Task.Completed to 'true' if the task was not already completed.
-// Also sets Task.CompletedDate to time.Now().
-func (task *Task) Complete() {
+// Also sets Task.CompletedDate to today, or to now with time of day if the
+// WithDateTime(true) option is given. Today is taken from the Clock and the
+// location of the options. See WithClock.
+func (task *Task) Complete(opts ...Option) {
 	if !task.Completed {
 		task.Completed = true
-		task.CompletedDate = time.Now()
+		task.CompletedDate = NewConfig(opts...).now()
 	}
 }
 
-// Due returns the duration left until due date from now. The duration is negative
-// if the task is overdue.
+// Due returns the duration left until the end of the due date from now, or
+// until the time of day if the due date has it. The duration is negative if the
+// task is overdue. Now is taken from the Clock and the due date is in the
+// location of the options.
 //
 // Just as with IsOverdue(), this function does also not take the Completed flag
 // into consideration. You should check Task.Completed first if needed.
-func (task *Task) Due() time.Duration {
-	return time.Until(task.DueDate.AddDate(0, 0, 1))
+func (task *Task) Due(opts ...Option) time.Duration {
+	cfg := NewConfig(opts...)
+
+	if task.DueDate.HasTime() {
+		return task.DueDate.Time(cfg.Location).Sub(cfg.Clock.Now())
+	}
+
+	return task.DueDate.AddDays(1).Time(cfg.Location).Sub(cfg.Clock.Now())
 }
 
 // HasAdditionalTags returns true if the task has any additional tags.
@@ -132,7 +160,7 @@ func (task *Task) HasAdditionalTags() bool {
 
 // HasCompletedDate returns true if the task has a completed date.
 func (task *Task) HasCompletedDate() bool {
-	return !task.CompletedDate.IsZero() && task.Completed
+	return !task.CompletedDate.IsZero()
 }
 
 // HasContexts returns true if the task has any contexts.
@@ -160,32 +188,56 @@ func (task *Task) HasProjects() bool {
 	return len(task.Projects) > 0
 }
 
+// HasThresholdDate returns true if the task has a threshold date. Such as
+// "t:2012-12-12".
+func (task *Task) HasThresholdDate() bool {
+	return !task.ThresholdDate.IsZero()
+}
+
+// IsActive returns true if the task is not completed and its threshold date has
+// been reached. Tasks without a threshold date are active until completed.
+//
+// Which is useful to hide the tasks which should not be started yet.
+func (task *Task) IsActive(opts ...Option) bool {
+	return task.isActive(NewConfig(opts...))
+}
+
 // IsCompleted returns true if the task has already been completed.
 func (task *Task) IsCompleted() bool {
 	return task.Completed
 }
 
-// IsDueToday returns true if the task is due todasy.
-func (task *Task) IsDueToday() bool {
-	if task.HasDueDate() {
-		due := task.Due()
-
-		return 0 < due && due <= oneDay
-	}
+// IsDueToday returns true if the task is due today. The dates are compared as
+// calendar dates, where today is taken from the Clock and the location of the
+// options. See WithClock and WithLocation.
+func (task *Task) IsDueToday(opts ...Option) bool {
+	return task.isDueToday(NewConfig(opts...))
+}
 
-	return false
+// IsNonTask returns true if the entry is not a task but a comment or blank line
+// kept in the TaskList. See WithKeepNonTaskLines.
+func (task *Task) IsNonTask() bool {
+	return task.NonTask
 }
 
-// IsOverdue returns true if due date is in the past.
+// IsOverdue returns true if due date is in the past. As IsDueToday, the dates
+// are compared as calendar dates. If the due date has a time of day, it is
+// overdue once the time has passed.
 //
 // This function does not take the Completed flag into consideration.
 // You should check Task.Completed first if needed.
-func (task *Task) IsOverdue() bool {
-	if task.HasDueDate() {
-		return task.Due() < 0
-	}
+func (task *Task) IsOverdue(opts ...Option) bool {
+	return task.isOverdue(NewConfig(opts...))
+}
 
-	return false
+// IsThresholdReached returns true if the threshold date is today or in the
+// past. It also returns true if the task has no threshold date. As IsDueToday,
+// the dates are compared as calendar dates. If the threshold date has a time of
+// day, it is reached once the time has come.
+//
+// This function does not take the Completed flag into consideration.
+func (task *Task) IsThresholdReached(opts ...Option) bool {
+	return task.isThresholdReached(NewConfig(opts...))
 }
 
 // Reopen sets Task.Completed to 'false' if the task was completed.
@@ -193,7 +245,7 @@ func (task *Task) IsOverdue() bool {
 func (task *Task) Reopen() {
 	if task.Completed {
 		task.Completed = false
-		task.CompletedDate = time.Time{} // time.IsZero() value
+		task.CompletedDate = Date{} // unset
 	}
 }
 
@@ -207,26 +259,38 @@ func (task *Task) Reopen() {
 //
 //	"(A) 2013-07-23 Call Dad @Home @Phone +Family due:2013-07-31 customTag1:Important!"
 //
-//nolint:cyclop // complexity is high (=15), but leave it as is for now
+// To keep the original order of the line of a parsed task instead, use
+// StringWith(WithPreserveOriginal(true)). See Config.PreserveOriginal.
+//
+// For non-task entries, such as comment or blank lines, it returns the
+// Original text as is.
 func (task Task) String() string {
-	var strBld strings.Builder
+	return task.stringWith(NewConfig())
+}
 
-	if task.Completed {
-		strBld.WriteString("x ")
+// StringWith returns a complete task string in todo.txt format with the given
+// options. It is the same as String() but the options are used to change the
+// default configuration. See Config.
+func (task *Task) StringWith(opts ...Option) string {
+	return task.stringWith(NewConfig(opts...))
+}
 
-		if task.HasCompletedDate() {
-			strBld.WriteString(task.CompletedDate.Format(DateLayout) + " ")
-		}
+// stringWith returns a complete task string in todo.txt format with the given
+// configuration.
+//
+//nolint:cyclop // complexity is high (=12), but leave it as is for now
+func (task *Task) stringWith(cfg *Config) string {
+	if task.NonTask {
+		return task.Original
 	}
 
-	if task.HasPriority() && (!task.Completed || !RemoveCompletedPriority) {
-		strBld.WriteString(fmt.Sprintf("(%s) ", task.Priority))
+	if cfg.PreserveOriginal && task.origin != nil {
+		return task.originalString(cfg)
 	}
 
-	if task.HasCreatedDate() {
-		strBld.WriteString(task.CreatedDate.Format(DateLayout) + " ")
-	}
+	var strBld strings.Builder
 
+	strBld.WriteString(task.headerString(cfg))
 	strBld.WriteString(task.Todo)
 
 	if task.HasContexts() {
@@ -252,22 +316,71 @@ func (task Task) String() string {
 	}
 
 	if task.HasAdditionalTags() {
-		// Sort map alphabetically by keys
-		keys := make([]string, 0, len(task.AdditionalTags))
-
-		for key := range task.AdditionalTags {
-			keys = append(keys, key)
+		// Sort alphabetically by keys, keeping the order of the values
+		for _, tag := range task.AdditionalTags.sorted() {
+			strBld.WriteString(" " + tag.String())
 		}
+	}
 
-		sort.Strings(keys)
+	if task.HasThresholdDate() {
+		strBld.WriteString(" t:" + cfg.formatDate(task.ThresholdDate))
+	}
 
-		for _, key := range keys {
-			strBld.WriteString(fmt.Sprintf(" %s:%s", key, task.AdditionalTags[key]))
+	if task.HasDueDate() {
+		strBld.WriteString(" due:" + cfg.formatDate(task.DueDate))
+	}
+
+	return strBld.String()
+}
+
+// isActive is the same as IsActive but with the given configuration.
+func (task *Task) isActive(cfg *Config) bool {
+	return !task.Completed && task.isThresholdReached(cfg)
+}
+
+// isDueToday is the same as IsDueToday but with the given configuration.
+func (task *Task) isDueToday(cfg *Config) bool {
+	return task.HasDueDate() && task.DueDate.DateOnly() == cfg.today()
+}
+
+// isOverdue is the same as IsOverdue but with the given configuration.
+func (task *Task) isOverdue(cfg *Config) bool {
+	if task.DueDate.HasTime() {
+		return task.DueDate.Time(cfg.Location).Before(cfg.Clock.Now())
+	}
+
+	return task.HasDueDate() && task.DueDate.Before(cfg.today())
+}
+
+// isThresholdReached is the same as IsThresholdReached but with the given
+// configuration.
+func (task *Task) isThresholdReached(cfg *Config) bool {
+	if task.ThresholdDate.HasTime() {
+		return !task.ThresholdDate.Time(cfg.Location).After(cfg.Clock.Now())
+	}
+
+	return !task.HasThresholdDate() || !task.ThresholdDate.After(cfg.today())
+}
+
+// headerString returns the leading part of the task string in todo.txt format.
+// Such as the completion mark, completed date, priority and created date.
+func (task *Task) headerString(cfg *Config) string {
+	var strBld strings.Builder
+
+	if task.Completed {
+		strBld.WriteString("x ")
+
+		if task.HasCompletedDate() {
+			strBld.WriteString(cfg.formatDate(task.CompletedDate) + " ")
 		}
 	}
 
-	if task.HasDueDate() {
-		strBld.WriteString(" due:" + task.DueDate.Format(DateLayout))
+	if task.HasPriority() && (!task.Completed || !cfg.RemoveCompletedPriority) {
+		strBld.WriteString(fmt.Sprintf("(%s) ", task.Priority))
+	}
+
+	if task.HasCreatedDate() {
+		strBld.WriteString(cfg.formatDate(task.CreatedDate) + " ")
 	}
 
 	return strBld.String()
@@ -0,0 +1,339 @@
+package todo
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: taskOrigin
+// ----------------------------------------------------------------------------
+
+// taskOrigin holds the state of a task as it was parsed, along with the tokens
+// of the original line. It is used to serialize the task losslessly. See
+// Config.PreserveOriginal.
+type taskOrigin struct {
+	tokens    []lineToken // tokens of the original line in order of appearance.
+	parsed    Task        // parsed is a copy of the task fields right after parsing.
+	bodyStart int         // bodyStart is the byte offset where the header ends.
+}
+
+// lineToken represents a token of the original task line.
+type lineToken struct {
+	key   string          // key of the tag, if the token is a tag.
+	start int             // start is the byte offset of the token in the line.
+	end   int             // end is the byte offset right after the token.
+	typ   TaskSegmentType // typ is the type of the token.
+}
+
+// wordRx matches any word separated by whitespaces.
+var wordRx = regexp.MustCompile(`\S+`)
+
+// ----------------------------------------------------------------------------
+//  Constructor
+// ----------------------------------------------------------------------------
+
+// newTaskOrigin returns the origin of the given parsed task.
+func newTaskOrigin(task *Task, cfg *Config) *taskOrigin {
+	tokens, bodyStart := tokenizeLine(task.Original, cfg)
+
+	return &taskOrigin{
+		tokens:    tokens,
+		parsed:    task.cloneFields(),
+		bodyStart: bodyStart,
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// cloneFields returns a copy of the task without its origin. Slices and maps
+// are copied as well so that the copy does not share them with the task.
+func (task *Task) cloneFields() Task {
+	clone := *task
+	clone.origin = nil
+	clone.Contexts = slices.Clone(task.Contexts)
+	clone.Projects = slices.Clone(task.Projects)
+	clone.AdditionalTags = slices.Clone(task.AdditionalTags)
+
+	return clone
+}
+
+// isHeaderModified returns true if any of the fields in the leading part of
+// the task string differ from the parsed state.
+func (task *Task) isHeaderModified() bool {
+	parsed := &task.origin.parsed
+
+	return task.Completed != parsed.Completed ||
+		task.Priority != parsed.Priority ||
+		task.CompletedDate != parsed.CompletedDate ||
+		task.CreatedDate != parsed.CreatedDate
+}
+
+// isModified returns true if any of the fields differ from the parsed state.
+func (task *Task) isModified() bool {
+	parsed := &task.origin.parsed
+
+	return task.isHeaderModified() ||
+		task.Todo != parsed.Todo ||
+		task.DueDate != parsed.DueDate ||
+		task.ThresholdDate != parsed.ThresholdDate ||
+		!slices.Equal(task.Contexts, parsed.Contexts) ||
+		!slices.Equal(task.Projects, parsed.Projects) ||
+		!slices.Equal(task.AdditionalTags, parsed.AdditionalTags)
+}
+
+// originalString returns the task string keeping the order of the original
+// line. Only the edited parts of the line are changed, and the newly added
+// contexts, projects and tags are appended at the end of the line.
+func (task *Task) originalString(cfg *Config) string {
+	if !task.isModified() {
+		return task.Original
+	}
+
+	header := task.Original[:task.origin.bodyStart]
+	if task.isHeaderModified() {
+		header = task.headerString(cfg)
+	}
+
+	emitted := make(map[string]int, len(task.AdditionalTags)+1)
+
+	var body string
+
+	if task.Todo == task.origin.parsed.Todo {
+		body = task.originalBody(cfg, emitted)
+	} else {
+		body = task.rebuiltBody(cfg, emitted)
+	}
+
+	return strings.TrimRight(header+body+task.appendedString(cfg, emitted), whitespaces)
+}
+
+// originalBody returns the body of the original line, with the values of the
+// tags updated. The tags removed from the task are dropped from the line. The
+// values of a multi-valued tag are put in the place of its tags in order.
+func (task *Task) originalBody(cfg *Config, emitted map[string]int) string {
+	var strBld strings.Builder
+
+	line := task.Original
+	pos := task.origin.bodyStart
+
+	for _, token := range task.origin.tokens {
+		if token.typ != SegmentTag && token.typ != SegmentDueDate && token.typ != SegmentThresholdDate {
+			continue
+		}
+
+		gap := line[pos:token.start]
+		pos = token.end
+
+		if tag, ok := task.tagString(cfg, token.key, emitted); ok {
+			strBld.WriteString(gap + tag)
+
+			continue
+		}
+
+		strBld.WriteString(strings.TrimRight(gap, whitespaces))
+	}
+
+	strBld.WriteString(line[pos:])
+
+	return strings.TrimLeft(strBld.String(), whitespaces)
+}
+
+// rebuiltBody returns the body made of the words in the edited Todo text. The
+// tags are inserted after the same number of words as in the original line.
+func (task *Task) rebuiltBody(cfg *Config, emitted map[string]int) string {
+	words := strings.Fields(task.Todo)
+	parts := make([]string, 0, len(words)+len(task.origin.tokens))
+	numWords, index := 0, 0
+
+	for _, token := range task.origin.tokens {
+		switch token.typ {
+		case SegmentTodoText, SegmentContext, SegmentProject:
+			numWords++
+		case SegmentTag, SegmentDueDate, SegmentThresholdDate:
+			tag, ok := task.tagString(cfg, token.key, emitted)
+			if !ok {
+				continue
+			}
+
+			for ; index < min(numWords, len(words)); index++ {
+				parts = append(parts, words[index])
+			}
+
+			parts = append(parts, tag)
+		default:
+			// header tokens are not part of the body
+		}
+	}
+
+	parts = append(parts, words[index:]...)
+
+	return strings.Join(parts, " ")
+}
+
+// appendedString returns the contexts, projects and tags which are not in the
+// original line. The tags are sorted by key and the threshold and due dates
+// come last.
+func (task *Task) appendedString(cfg *Config, emitted map[string]int) string {
+	var strBld strings.Builder
+
+	for _, context := range task.Contexts {
+		if !strings.Contains(task.Todo, "@"+context) {
+			strBld.WriteString(" @" + context)
+		}
+	}
+
+	for _, project := range task.Projects {
+		if !strings.Contains(task.Todo, "+"+project) {
+			strBld.WriteString(" +" + project)
+		}
+	}
+
+	keys := task.AdditionalTags.Keys()
+
+	sort.Strings(keys)
+
+	for _, key := range append(keys, "t", "due") {
+		for {
+			tag, ok := task.tagString(cfg, key, emitted)
+			if !ok {
+				break
+			}
+
+			strBld.WriteString(" " + tag)
+		}
+	}
+
+	return strBld.String()
+}
+
+// tagString returns the current "key:value" string of the next value of the
+// tag with the given key, counting the values already emitted. It returns false
+// if the task does not have the tag or if all its values have been emitted.
+func (task *Task) tagString(cfg *Config, key string, emitted map[string]int) (string, bool) {
+	switch key {
+	case "due":
+		if !task.HasDueDate() || emitted[key] > 0 {
+			return emptyStr, false
+		}
+
+		emitted[key]++
+
+		return "due:" + cfg.formatDate(task.DueDate), true
+	case "t":
+		if !task.HasThresholdDate() || emitted[key] > 0 {
+			return emptyStr, false
+		}
+
+		emitted[key]++
+
+		return "t:" + cfg.formatDate(task.ThresholdDate), true
+	}
+
+	values := task.AdditionalTags.Values(key)
+	if emitted[key] >= len(values) {
+		return emptyStr, false
+	}
+
+	value := values[emitted[key]]
+	emitted[key]++
+
+	return key + ":" + value, true
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// tokenizeLine splits the task line into tokens in order of appearance. It
+// also returns the byte offset where the leading part of the line (completion
+// mark, completed date, priority and created date) ends. If cfg.ParseTags is
+// false, the "key:value" words are tokenized as text.
+//
+//nolint:cyclop // complexity is 12 but it is a simple sequence of checks
+func tokenizeLine(line string, cfg *Config) ([]lineToken, int) {
+	var tokens []lineToken
+
+	bodyStart := 0
+
+	if loc := completedRx.FindStringIndex(line); loc != nil {
+		tokens = append(tokens, lineToken{typ: SegmentIsCompleted, start: 0, end: 1})
+		bodyStart = loc[1]
+	}
+
+	if loc := cfg.completedDateRx().FindStringSubmatchIndex(line); loc != nil {
+		tokens = append(tokens, lineToken{typ: SegmentCompletedDate, start: loc[2], end: loc[3]})
+		bodyStart = max(bodyStart, loc[1])
+	}
+
+	if loc := cfg.priorityRx().FindStringSubmatchIndex(line); loc != nil {
+		tokens = append(tokens, lineToken{typ: SegmentPriority, start: loc[4] - 1, end: loc[5] + 1})
+		bodyStart = max(bodyStart, loc[1])
+	}
+
+	if loc := cfg.createdDateRx().FindStringSubmatchIndex(line); loc != nil {
+		tokens = append(tokens, lineToken{typ: SegmentCreatedDate, start: loc[4], end: loc[5]})
+		bodyStart = max(bodyStart, loc[1])
+	}
+
+	pos := bodyStart
+
+	var tagLocs [][]int
+	if cfg.ParseTags {
+		tagLocs = cfg.addonTagRx().FindAllStringSubmatchIndex(line[bodyStart:], -1)
+	}
+
+	for _, loc := range tagLocs {
+		start, end := bodyStart+loc[4], bodyStart+loc[7]
+
+		tokens = append(tokens, tokenizeWords(line, pos, start)...)
+		pos = end
+
+		key := line[start : bodyStart+loc[5]]
+
+		switch key {
+		case "due":
+			tokens = append(tokens, lineToken{typ: SegmentDueDate, key: key, start: start, end: end})
+		case "t":
+			typ := SegmentThresholdDate
+			if _, err := cfg.parseDate(line[bodyStart+loc[6] : end]); err != nil {
+				typ = SegmentTag // such as "t:tbd", which is an additional tag
+			}
+
+			tokens = append(tokens, lineToken{typ: typ, key: key, start: start, end: end})
+		default:
+			tokens = append(tokens, lineToken{typ: SegmentTag, key: key, start: start, end: end})
+		}
+	}
+
+	tokens = append(tokens, tokenizeWords(line, pos, len(line))...)
+
+	return tokens, bodyStart
+}
+
+// tokenizeWords splits the line between the given byte offsets into words and
+// returns them as context, project or text tokens.
+func tokenizeWords(line string, start, end int) []lineToken {
+	locs := wordRx.FindAllStringIndex(line[start:end], -1)
+	tokens := make([]lineToken, 0, len(locs))
+
+	for _, loc := range locs {
+		word := line[start+loc[0] : start+loc[1]]
+		token := lineToken{typ: SegmentTodoText, start: start + loc[0], end: start + loc[1]}
+
+		switch {
+		case len(word) > 1 && word[0] == '@':
+			token.typ = SegmentContext
+		case len(word) > 1 && word[0] == '+':
+			token.typ = SegmentProject
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
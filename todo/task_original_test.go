@@ -0,0 +1,120 @@
+package todo
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreserveOriginal_unmodified(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{
+		"(B) 2013-12-01 private:false Outline chapter 5 +Novel @Computer Level:5 due:2014-02-17",
+		"x 2014-01-02 (B) 2013-12-30 @Go Create golang library  test cases +go-todotxt",
+		"+Gardening Plan backyard herb garden +Planning @Home +Improving",
+		"due:2014-01-01 Research self-publishing services +Novel +Novel",
+	} {
+		task, err := ParseTask(text)
+		require.NoError(t, err, "failed to parse task during test setup")
+
+		require.Equal(t, text, task.StringWith(WithPreserveOriginal(true)), "unmodified task should be serialized as is")
+	}
+}
+
+//nolint:funlen // length is 64 but it is a flat list of test cases
+func TestPreserveOriginal_modified(t *testing.T) {
+	t.Parallel()
+
+	const text = "(B) 2013-12-01 private:false Outline chapter 5 +Novel  @Computer Level:5 due:2014-02-17"
+
+	for _, test := range []struct {
+		edit   func(task *Task)
+		expect string
+	}{
+		{
+			edit:   func(task *Task) { task.Priority = "A" },
+			expect: "(A) 2013-12-01 private:false Outline chapter 5 +Novel  @Computer Level:5 due:2014-02-17",
+		},
+		{
+			edit:   func(task *Task) { require.NoError(t, task.SetTag("private", "true")) },
+			expect: "(B) 2013-12-01 private:true Outline chapter 5 +Novel  @Computer Level:5 due:2014-02-17",
+		},
+		{
+			edit:   func(task *Task) { task.RemoveTag("Level") },
+			expect: "(B) 2013-12-01 private:false Outline chapter 5 +Novel  @Computer due:2014-02-17",
+		},
+		{
+			edit: func(task *Task) {
+				date, err := ParseDate("2014-03-01")
+				require.NoError(t, err, "failed to parse time during test")
+
+				task.DueDate = date
+			},
+			expect: "(B) 2013-12-01 private:false Outline chapter 5 +Novel  @Computer Level:5 due:2014-03-01",
+		},
+		{
+			edit: func(task *Task) {
+				task.Contexts = append(task.Contexts, "Home")
+				require.NoError(t, task.SetTag("est", "2h"))
+			},
+			expect: "(B) 2013-12-01 private:false Outline chapter 5 +Novel  @Computer Level:5 due:2014-02-17 " +
+				"@Home est:2h",
+		},
+		{
+			edit:   func(task *Task) { task.Todo = "Outline chapter 6 +Novel @Computer" },
+			expect: "(B) 2013-12-01 private:false Outline chapter 6 +Novel @Computer Level:5 due:2014-02-17",
+		},
+		{
+			edit: func(task *Task) { task.Complete() },
+			// Note that RemoveCompletedPriority is set to false during the tests
+			expect: "x " + time.Now().Format(DateLayout) + " (B) 2013-12-01 private:false Outline chapter 5 " +
+				"+Novel  @Computer Level:5 due:2014-02-17",
+		},
+	} {
+		task, err := ParseTask(text)
+		require.NoError(t, err, "failed to parse task during test setup")
+
+		test.edit(task)
+
+		require.Equal(t, test.expect, task.StringWith(WithPreserveOriginal(true)),
+			"edited task should keep the original order")
+	}
+}
+
+func TestPreserveOriginal_round_trip(t *testing.T) {
+	t.Parallel()
+
+	rawInput, err := os.ReadFile(testInputTasklist)
+	require.NoError(t, err, "failed to read test data")
+
+	tasklist, err := LoadFromString(string(rawInput))
+	require.NoError(t, err, "failed to load test data")
+
+	pathFileOutput := testGetPathFileTemp(t, testOutput)
+	require.NoError(t, tasklist.WriteToPath(pathFileOutput, WithPreserveOriginal(true)),
+		"failed to write tasklist")
+
+	rawOutput, err := os.ReadFile(pathFileOutput)
+	require.NoError(t, err, "failed to read saved tasklist")
+
+	lines := strings.Split(strings.TrimSuffix(string(rawOutput), NewLine), NewLine)
+	require.Len(t, lines, len(tasklist), "number of lines should match the number of tasks")
+
+	for i, line := range lines {
+		require.Equal(t, tasklist[i].Original, line, "line %d was not preserved", i+1)
+	}
+}
+
+func TestPreserveOriginal_not_parsed(t *testing.T) {
+	t.Parallel()
+
+	//nolint:exhaustruct // other fields are missing intentionally
+	task := Task{Todo: "Go shopping..", Contexts: []string{"GroceryStore"}}
+
+	require.Nil(t, task.origin, "tasks not created by ParseTask should not have an origin")
+	require.Equal(t, "Go shopping.. @GroceryStore", task.String())
+}
@@ -3,15 +3,38 @@ package todo
 import (
 	"fmt"
 	"sort"
+	"strings"
 )
 
+// InlineSegments returns the segments of the tokens of the task line in order
+// of appearance, with their byte offsets. The contexts, projects and tags are
+// split out of the todo text at their places. The line is the one of
+// StringWith(WithPreserveOriginal(true)) with the same options, which is the
+// Original for the unmodified tasks. Such as:
+//
+//	line := task.StringWith(todo.WithPreserveOriginal(true))
+//
+//	for _, seg := range task.InlineSegments() {
+//	    fmt.Printf("%d-%d %s %q\n", seg.Start, seg.End, seg.Type, line[seg.Start:seg.End])
+//	}
+//
+// The options are used to change the default configuration. See Config.
+func (task *Task) InlineSegments(opts ...Option) []*TaskSegment {
+	return task.inlineSegments(NewConfig(opts...))
+}
+
 // Segments returns a segmented task string in todo.txt format. The order of
-// segments is the same as String().
+// segments is the same as String(). See InlineSegments for the segments in
+// order of the task line.
+//
+// The options are used to change the default configuration. See Config.
 //
 //nolint:funlen, cyclop // length is 77 and complexity is 15 but leave it as is for now
-func (task *Task) Segments() []*TaskSegment {
+func (task *Task) Segments(opts ...Option) []*TaskSegment {
 	var segs []*TaskSegment
 
+	cfg := NewConfig(opts...)
+
 	newBasicTaskSeg := func(t TaskSegmentType, s string) *TaskSegment {
 		return &TaskSegment{
 			Type:      t,
@@ -31,16 +54,16 @@ func (task *Task) Segments() []*TaskSegment {
 		segs = append(segs, newBasicTaskSeg(SegmentIsCompleted, "x"))
 
 		if task.HasCompletedDate() {
-			segs = append(segs, newBasicTaskSeg(SegmentCompletedDate, task.CompletedDate.Format(DateLayout)))
+			segs = append(segs, newBasicTaskSeg(SegmentCompletedDate, cfg.formatDate(task.CompletedDate)))
 		}
 	}
 
-	if task.HasPriority() && (!task.Completed || !RemoveCompletedPriority) {
+	if task.HasPriority() && (!task.Completed || !cfg.RemoveCompletedPriority) {
 		segs = append(segs, newTaskSeg(SegmentPriority, task.Priority, fmt.Sprintf("(%s)", task.Priority)))
 	}
 
 	if task.HasCreatedDate() {
-		segs = append(segs, newBasicTaskSeg(SegmentCreatedDate, task.CreatedDate.Format(DateLayout)))
+		segs = append(segs, newBasicTaskSeg(SegmentCreatedDate, cfg.formatDate(task.CreatedDate)))
 	}
 
 	segs = append(segs, newBasicTaskSeg(SegmentTodoText, task.Todo))
@@ -62,28 +85,68 @@ func (task *Task) Segments() []*TaskSegment {
 	}
 
 	if task.HasAdditionalTags() {
-		// Sort map alphabetically by keys
-		keys := make([]string, 0, len(task.AdditionalTags))
-
-		for key := range task.AdditionalTags {
-			keys = append(keys, key)
-		}
-
-		sort.Strings(keys)
-
-		for _, key := range keys {
-			val := task.AdditionalTags[key]
-
+		// Sort alphabetically by keys, keeping the order of the values
+		for _, tag := range task.AdditionalTags.sorted() {
 			segs = append(segs, &TaskSegment{
 				Type:      SegmentTag,
-				Originals: []string{key, val},
-				Display:   fmt.Sprintf("%s:%s", key, val),
+				Originals: []string{tag.Key, tag.Value},
+				Display:   tag.String(),
 			})
 		}
 	}
 
+	if task.HasThresholdDate() {
+		segs = append(segs, newBasicTaskSeg(SegmentThresholdDate, "t:"+cfg.formatDate(task.ThresholdDate)))
+	}
+
 	if task.HasDueDate() {
-		segs = append(segs, newBasicTaskSeg(SegmentDueDate, "due:"+task.DueDate.Format(DateLayout)))
+		segs = append(segs, newBasicTaskSeg(SegmentDueDate, "due:"+cfg.formatDate(task.DueDate)))
+	}
+
+	return segs
+}
+
+// inlineSegments returns the segments of the tokens of the task line in order
+// of appearance. The consecutive words of text are a single segment.
+func (task *Task) inlineSegments(cfg *Config) []*TaskSegment {
+	lineCfg := *cfg
+	lineCfg.PreserveOriginal = true
+
+	line := task.stringWith(&lineCfg)
+	tokens, _ := tokenizeLine(line, cfg)
+	segs := make([]*TaskSegment, 0, len(tokens))
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		if token.typ == SegmentTodoText {
+			for i+1 < len(tokens) && tokens[i+1].typ == SegmentTodoText {
+				i++
+				token.end = tokens[i].end
+			}
+		}
+
+		display := line[token.start:token.end]
+		originals := []string{display}
+
+		switch token.typ {
+		case SegmentPriority:
+			originals = []string{strings.Trim(display, "()")}
+		case SegmentContext, SegmentProject:
+			originals = []string{display[1:]}
+		case SegmentTag:
+			originals = []string{token.key, display[len(token.key)+1:]}
+		default:
+			// the display is the original
+		}
+
+		segs = append(segs, &TaskSegment{
+			Display:   display,
+			Originals: originals,
+			Start:     token.start,
+			End:       token.end,
+			Type:      token.typ,
+		})
 	}
 
 	return segs
@@ -0,0 +1,251 @@
+package todo
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Segment-level editing
+// ----------------------------------------------------------------------------
+//  These methods edit a task by the index of its segment in InlineSegments
+//  with the same options. The task line is edited in place of the segment
+//  and parsed again, so the fields of the task and the line are always
+//  consistent. The header segments, such as the priority and the dates
+//  before the todo text, are edited as the fields of the task instead,
+//  since their meaning depends on their position.
+
+// ErrSegmentNotFound is returned by the segment-level editing methods if the
+// index is out of the range of the segments.
+var ErrSegmentNotFound = errors.New("segment not found")
+
+// EditSegment replaces the segment at the given index with the given text as
+// it is written in the task line. Such as "@Home" for a context or "est:30" for
+// a tag. If the text is empty, the segment is removed from the line.
+//
+// Except for the text segments, the replacement must be a single segment of
+// the same type. Otherwise the returned error wraps a *ParseError. The ID of
+// the task is kept and Original is set to the edited line.
+//
+// The header segments set the fields of the task, and the rest of the line is
+// kept as it is. Removing the "x" of a completed task also removes its
+// completed date. Removing the completed date followed by a created date is an
+// error, since the created date would be read as the completed date.
+//
+// The options are used to change the default configuration. See Config.
+func (task *Task) EditSegment(index int, text string, opts ...Option) error {
+	return task.editSegment(index, text, NewConfig(opts...))
+}
+
+// RemoveSegment removes the segment at the given index and the whitespaces in
+// front of it from the task line. It is the same as EditSegment with an empty
+// text.
+func (task *Task) RemoveSegment(index int, opts ...Option) error {
+	return task.editSegment(index, emptyStr, NewConfig(opts...))
+}
+
+// SetSegmentValue sets the value of the segment at the given index. The value
+// is the one in TaskSegment.Originals. Such as "Home" for the "@Home" context,
+// "A" for the "(A)" priority or "30" for the "est:30" tag. The key of the tags,
+// including "due:" and "t:", is kept. If the value is empty, the segment is
+// removed.
+func (task *Task) SetSegmentValue(index int, value string, opts ...Option) error {
+	cfg := NewConfig(opts...)
+
+	seg, err := task.inlineSegment(index, cfg)
+	if err != nil {
+		return err
+	}
+
+	text := value
+
+	if isNotEmpty(value) {
+		switch seg.Type {
+		case SegmentPriority:
+			text = "(" + value + ")"
+		case SegmentContext:
+			text = "@" + value
+		case SegmentProject:
+			text = "+" + value
+		case SegmentTag:
+			text = seg.Originals[0] + ":" + value
+		case SegmentDueDate:
+			text = "due:" + value
+		case SegmentThresholdDate:
+			text = "t:" + value
+		default:
+			// the value is written as is
+		}
+	}
+
+	return task.editSegment(index, text, cfg)
+}
+
+// editHeaderSegment edits the header segment by setting the field of the task,
+// then writes the line again from the fields. It returns an error if the line
+// is not read back as the same fields.
+func (task *Task) editHeaderSegment(seg *TaskSegment, text string, cfg *Config) error {
+	// Keep the priority of the completed task, which is in the segments
+	lineCfg := *cfg
+	lineCfg.PreserveOriginal = true
+	lineCfg.RemoveCompletedPriority = false
+
+	edited := *task
+	if err := edited.setHeaderSegment(seg.Type, text, cfg); err != nil {
+		return &ParseError{
+			Err:     err,
+			Text:    task.stringWith(&lineCfg),
+			Value:   text,
+			Offset:  seg.Start,
+			Segment: seg.Type,
+		}
+	}
+
+	newLine := edited.stringWith(&lineCfg)
+
+	parsed, err := parseTask(newLine, cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to edit segment")
+	}
+
+	if parsed.Completed != edited.Completed || parsed.CompletedDate != edited.CompletedDate ||
+		parsed.CreatedDate != edited.CreatedDate || parsed.Priority != edited.Priority {
+		return &ParseError{
+			Err:     errors.Errorf("the %s segment cannot be edited without changing the others", seg.Type),
+			Text:    newLine,
+			Value:   text,
+			Offset:  seg.Start,
+			Segment: seg.Type,
+		}
+	}
+
+	parsed.ID = task.ID
+	*task = *parsed
+
+	return nil
+}
+
+// editSegment is the same as EditSegment but with the given configuration.
+func (task *Task) editSegment(index int, text string, cfg *Config) error {
+	seg, err := task.inlineSegment(index, cfg)
+	if err != nil {
+		return err
+	}
+
+	switch seg.Type {
+	case SegmentIsCompleted, SegmentCompletedDate, SegmentPriority, SegmentCreatedDate:
+		return task.editHeaderSegment(seg, text, cfg)
+	default:
+		// the line is edited in place of the segment
+	}
+
+	lineCfg := *cfg
+	lineCfg.PreserveOriginal = true
+
+	line := task.stringWith(&lineCfg)
+	prefix, suffix := line[:seg.Start], line[seg.End:]
+
+	if isEmpty(text) {
+		prefix = strings.TrimRight(prefix, whitespaces)
+		if isEmpty(prefix) {
+			suffix = strings.TrimLeft(suffix, whitespaces)
+		}
+	}
+
+	newLine := prefix + text + suffix
+
+	edited, err := parseTask(newLine, cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to edit segment")
+	}
+
+	if isNotEmpty(text) && seg.Type != SegmentTodoText && !edited.hasInlineSegment(seg.Type, seg.Start, len(text), cfg) {
+		return &ParseError{
+			Err:     errors.Errorf("expected a single %s segment", seg.Type),
+			Text:    newLine,
+			Value:   text,
+			Offset:  seg.Start,
+			Segment: seg.Type,
+		}
+	}
+
+	edited.ID = task.ID
+	*task = *edited
+
+	return nil
+}
+
+// hasInlineSegment returns true if the task has the inline segment of the given
+// type at the given byte offset and length.
+func (task *Task) hasInlineSegment(typ TaskSegmentType, start, length int, cfg *Config) bool {
+	for _, seg := range task.inlineSegments(cfg) {
+		if seg.Start == start {
+			return seg.Type == typ && seg.End == start+length
+		}
+	}
+
+	return false
+}
+
+// inlineSegment returns the inline segment at the given index.
+func (task *Task) inlineSegment(index int, cfg *Config) (*TaskSegment, error) {
+	if task.NonTask {
+		return nil, errors.Wrap(ErrSegmentNotFound, "non-task entries have no segments")
+	}
+
+	segs := task.inlineSegments(cfg)
+	if index < 0 || index >= len(segs) {
+		return nil, errors.Wrapf(ErrSegmentNotFound, "index %d of %d segments", index, len(segs))
+	}
+
+	return segs[index], nil
+}
+
+// setHeaderSegment sets the field of the header segment type to the text as it
+// is written in the task line. If the text is empty, the field is cleared.
+func (task *Task) setHeaderSegment(typ TaskSegmentType, text string, cfg *Config) error {
+	var err error
+
+	switch typ {
+	case SegmentIsCompleted:
+		switch text {
+		case emptyStr:
+			task.Completed, task.CompletedDate = false, Date{}
+		case "x":
+			task.Completed = true
+		default:
+			err = errors.New(`expected "x"`)
+		}
+	case SegmentCompletedDate:
+		task.CompletedDate, err = parseHeaderDate(text, cfg)
+	case SegmentPriority:
+		if isNotEmpty(text) && (len(text) != 3 || text[0] != '(' || text[2] != ')' || text[1] < 'A' || text[1] > 'Z') {
+			return errors.New("expected a priority such as \"(A)\"")
+		}
+
+		task.Priority = strings.Trim(text, "()")
+	case SegmentCreatedDate:
+		task.CreatedDate, err = parseHeaderDate(text, cfg)
+	default:
+		err = errors.Errorf("%s is not a header segment", typ)
+	}
+
+	return err
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// parseHeaderDate parses the date of the header segment. It returns the zero
+// Date for an empty text.
+func parseHeaderDate(text string, cfg *Config) (Date, error) {
+	if isEmpty(text) {
+		return Date{}, nil
+	}
+
+	date, err := cfg.parseDate(text)
+
+	return date, errors.Wrap(err, "failed to parse date")
+}
@@ -0,0 +1,204 @@
+package todo
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const testEditSegmentTask = "(A) 2020-03-01 Call @Mom about +Trip est:30 due:2020-03-09"
+
+func TestTask_EditSegment(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		text   string
+		expect string
+		index  int
+	}{
+		{index: 0, text: "(B)", expect: "(B) 2020-03-01 Call @Mom about +Trip est:30 due:2020-03-09"},
+		{index: 2, text: "Phone", expect: "(A) 2020-03-01 Phone @Mom about +Trip est:30 due:2020-03-09"},
+		{index: 3, text: "@Dad", expect: "(A) 2020-03-01 Call @Dad about +Trip est:30 due:2020-03-09"},
+		{index: 4, text: "about the", expect: "(A) 2020-03-01 Call @Mom about the +Trip est:30 due:2020-03-09"},
+		{index: 6, text: "est:45", expect: "(A) 2020-03-01 Call @Mom about +Trip est:45 due:2020-03-09"},
+		{index: 7, text: "due:2020-03-10", expect: "(A) 2020-03-01 Call @Mom about +Trip est:30 due:2020-03-10"},
+		{index: 0, text: "", expect: "2020-03-01 Call @Mom about +Trip est:30 due:2020-03-09"},
+		{index: 5, text: "", expect: "(A) 2020-03-01 Call @Mom about est:30 due:2020-03-09"},
+	} {
+		task, err := ParseTask(testEditSegmentTask)
+		require.NoError(t, err)
+
+		task.ID = 5
+
+		require.NoError(t, task.EditSegment(test.index, test.text), "index: %d, text: %q", test.index, test.text)
+		require.Equal(t, test.expect, task.Original)
+		require.Equal(t, 5, task.ID, "ID should be kept")
+
+		// Fields are consistent with the edited line
+		parsed, err := ParseTask(test.expect)
+		require.NoError(t, err)
+		require.Equal(t, parsed.String(), task.String())
+	}
+}
+
+func TestTask_EditSegment_header(t *testing.T) {
+	t.Parallel()
+
+	const line = "x 2020-03-03 (A) 2020-01-01 Call Mom due:2020-03-09"
+
+	for _, test := range []struct {
+		text   string
+		expect string
+		typ    TaskSegmentType
+		index  int
+	}{
+		// Removing the "x" also removes the completed date
+		{index: 0, typ: SegmentIsCompleted, text: "", expect: "(A) 2020-01-01 Call Mom due:2020-03-09"},
+		{index: 0, typ: SegmentIsCompleted, text: "x", expect: line},
+		{
+			index: 1, typ: SegmentCompletedDate, text: "2020-03-04",
+			expect: "x 2020-03-04 (A) 2020-01-01 Call Mom due:2020-03-09",
+		},
+		{index: 2, typ: SegmentPriority, text: "(B)", expect: "x 2020-03-03 (B) 2020-01-01 Call Mom due:2020-03-09"},
+		{index: 2, typ: SegmentPriority, text: "", expect: "x 2020-03-03 2020-01-01 Call Mom due:2020-03-09"},
+		{
+			index: 3, typ: SegmentCreatedDate, text: "2020-01-02",
+			expect: "x 2020-03-03 (A) 2020-01-02 Call Mom due:2020-03-09",
+		},
+		{index: 3, typ: SegmentCreatedDate, text: "", expect: "x 2020-03-03 (A) Call Mom due:2020-03-09"},
+	} {
+		task, err := ParseTask(line)
+		require.NoError(t, err)
+
+		task.ID = 5
+
+		require.Equal(t, test.typ, task.InlineSegments()[test.index].Type)
+		require.NoError(t, task.EditSegment(test.index, test.text), "index: %d, text: %q", test.index, test.text)
+		require.Equal(t, test.expect, task.String(), "index: %d, text: %q", test.index, test.text)
+		require.Equal(t, 5, task.ID, "ID should be kept")
+
+		parsed, err := ParseTask(test.expect)
+		require.NoError(t, err)
+		require.Equal(t, parsed.Completed, task.Completed, "index: %d, text: %q", test.index, test.text)
+		require.Equal(t, parsed.CompletedDate, task.CompletedDate, "index: %d, text: %q", test.index, test.text)
+		require.Equal(t, parsed.Priority, task.Priority, "index: %d, text: %q", test.index, test.text)
+		require.Equal(t, parsed.CreatedDate, task.CreatedDate, "index: %d, text: %q", test.index, test.text)
+		require.Equal(t, "Call Mom", task.Todo, "todo text should not be changed")
+	}
+
+	// Editing a completed task keeps its priority, even if the priority of the
+	// completed tasks is removed on writing
+	task, err := ParseTask("x (A) 2020-01-02 2020-01-01 foo")
+	require.NoError(t, err)
+	require.Equal(t, SegmentCreatedDate, task.InlineSegments(WithRemoveCompletedPriority(true))[2].Type)
+	require.NoError(t, task.EditSegment(2, "2020-01-03", WithRemoveCompletedPriority(true)))
+	require.Equal(t, "x (A) 2020-01-03 2020-01-01 foo", task.Original)
+	require.Equal(t, "A", task.Priority)
+	require.Equal(t, NewDate(2020, 1, 3), task.CreatedDate)
+	require.Equal(t, "2020-01-01 foo", task.Todo)
+
+	// Removing the completed date without a created date
+	task, err = ParseTask("x 2020-03-03 Call Mom")
+	require.NoError(t, err)
+	require.NoError(t, task.RemoveSegment(1))
+	require.Equal(t, "x Call Mom", task.String())
+	require.True(t, task.Completed)
+	require.True(t, task.CompletedDate.IsZero())
+}
+
+func TestTask_EditSegment_header_errors(t *testing.T) {
+	t.Parallel()
+
+	const line = "x 2020-03-03 2020-01-01 Call Mom"
+
+	task, err := ParseTask(line)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		text  string
+		typ   TaskSegmentType
+		index int
+	}{
+		{index: 0, typ: SegmentIsCompleted, text: "X"},
+		{index: 1, typ: SegmentCompletedDate, text: "2020-13-45"},
+		{index: 1, typ: SegmentCompletedDate, text: ""}, // the created date would be the completed date
+		{index: 2, typ: SegmentCreatedDate, text: "yesterday"},
+	} {
+		err := task.EditSegment(test.index, test.text)
+
+		var parseErr *ParseError
+
+		require.ErrorAs(t, err, &parseErr, "index: %d, text: %q", test.index, test.text)
+		require.Equal(t, test.typ, parseErr.Segment)
+		require.Equal(t, test.text, parseErr.Value)
+	}
+
+	require.Equal(t, line, task.String(), "failed edits should not change the task")
+	require.Equal(t, NewDate(2020, 1, 1), task.CreatedDate)
+}
+
+func TestTask_EditSegment_fields(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask(testEditSegmentTask)
+	require.NoError(t, err)
+
+	require.NoError(t, task.SetSegmentValue(3, "Dad"))
+	require.Equal(t, []string{"Dad"}, task.Contexts)
+	require.Equal(t, "Call @Dad about +Trip", task.Todo)
+
+	require.NoError(t, task.SetSegmentValue(6, "45"))
+	require.Equal(t, "45", task.AdditionalTags.Values("est")[0])
+
+	require.NoError(t, task.SetSegmentValue(7, "2020-04-01"))
+	require.Equal(t, NewDate(2020, 4, 1), task.DueDate)
+
+	require.NoError(t, task.SetSegmentValue(0, "C"))
+	require.Equal(t, "C", task.Priority)
+
+	require.NoError(t, task.RemoveSegment(5))
+	require.Empty(t, task.Projects)
+	require.Equal(t, "(C) 2020-03-01 Call @Dad about est:45 due:2020-04-01", task.String())
+
+	// Edits keep the order of the line
+	segs := task.InlineSegments()
+	require.Equal(t, SegmentTag, segs[5].Type)
+	require.Equal(t, []string{"est", "45"}, segs[5].Originals)
+}
+
+func TestTask_EditSegment_errors(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask(testEditSegmentTask)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		text  string
+		index int
+	}{
+		{index: 3, text: "Dad"},            // not a context
+		{index: 3, text: "@Mom @Dad"},      // more than one context
+		{index: 6, text: "due:2020-03-10"}, // not a tag
+		{index: 0, text: "(a)"},            // not a priority
+	} {
+		err := task.EditSegment(test.index, test.text)
+
+		var parseErr *ParseError
+
+		require.ErrorAs(t, err, &parseErr, "index: %d, text: %q", test.index, test.text)
+		require.Equal(t, test.text, parseErr.Value)
+	}
+
+	// Invalid dates
+	var parseErr *ParseError
+
+	require.ErrorAs(t, task.SetSegmentValue(7, "2020-13-45"), &parseErr)
+	require.Equal(t, SegmentDueDate, parseErr.Segment)
+
+	require.True(t, errors.Is(task.EditSegment(8, "@Dad"), ErrSegmentNotFound))
+	require.True(t, errors.Is(task.SetSegmentValue(-1, "Dad"), ErrSegmentNotFound))
+	require.True(t, errors.Is((&Task{NonTask: true}).RemoveSegment(0), ErrSegmentNotFound))
+
+	require.Equal(t, testEditSegmentTask, task.String(), "failed edits should not change the task")
+}
@@ -188,3 +188,72 @@ func TestTask_Segments(t *testing.T) {
 		require.Equal(t, expectSegments, actualSegments, "segments do not match for task: %s", test.text)
 	}
 }
+
+func TestTask_Segments_inline(t *testing.T) {
+	t.Parallel()
+
+	const line = "x 2020-03-09 (A) 2020-03-01 Call @Mom  about +Trip est:30 at noon due:2020-03-08"
+
+	task, err := ParseTask(line)
+	require.NoError(t, err)
+
+	segs := task.InlineSegments()
+
+	expect := []struct {
+		display   string
+		originals []string
+		typ       TaskSegmentType
+	}{
+		{typ: SegmentIsCompleted, display: "x", originals: []string{"x"}},
+		{typ: SegmentCompletedDate, display: "2020-03-09", originals: []string{"2020-03-09"}},
+		{typ: SegmentPriority, display: "(A)", originals: []string{"A"}},
+		{typ: SegmentCreatedDate, display: "2020-03-01", originals: []string{"2020-03-01"}},
+		{typ: SegmentTodoText, display: "Call", originals: []string{"Call"}},
+		{typ: SegmentContext, display: "@Mom", originals: []string{"Mom"}},
+		{typ: SegmentTodoText, display: "about", originals: []string{"about"}},
+		{typ: SegmentProject, display: "+Trip", originals: []string{"Trip"}},
+		{typ: SegmentTag, display: "est:30", originals: []string{"est", "30"}},
+		{typ: SegmentTodoText, display: "at noon", originals: []string{"at noon"}},
+		{typ: SegmentDueDate, display: "due:2020-03-08", originals: []string{"due:2020-03-08"}},
+	}
+
+	require.Len(t, segs, len(expect))
+
+	for i, seg := range segs {
+		require.Equal(t, expect[i].typ, seg.Type, "segment #%d", i)
+		require.Equal(t, expect[i].display, seg.Display, "segment #%d", i)
+		require.Equal(t, expect[i].originals, seg.Originals, "segment #%d", i)
+		require.Equal(t, seg.Display, line[seg.Start:seg.End], "offsets of segment #%d", i)
+	}
+
+	// Edited tasks are tokenized in the line written by PreserveOriginal
+	task.Projects = append(task.Projects, "Family")
+	task.DueDate = NewDate(2020, 3, 10)
+
+	edited := task.StringWith(WithPreserveOriginal(true))
+	require.Equal(t, "x 2020-03-09 (A) 2020-03-01 Call @Mom  about +Trip est:30 at noon due:2020-03-10 +Family", edited)
+
+	segs = task.InlineSegments()
+	last := segs[len(segs)-1]
+	require.Equal(t, SegmentProject, last.Type)
+	require.Equal(t, "+Family", edited[last.Start:last.End])
+
+	// Regular segments have no offsets
+	for _, seg := range task.Segments() {
+		require.Zero(t, seg.Start)
+		require.Zero(t, seg.End)
+	}
+}
+
+func TestTask_Segments_inline_without_tags(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("Meet Bob at 10:30 @Office", WithParseTags(false))
+	require.NoError(t, err)
+
+	segs := task.InlineSegments(WithParseTags(false))
+	require.Len(t, segs, 2)
+	require.Equal(t, "Meet Bob at 10:30", segs[0].Display)
+	require.Equal(t, SegmentContext, segs[1].Type)
+	require.Equal(t, 18, segs[1].Start)
+}
@@ -0,0 +1,299 @@
+package todo
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Typed accessors of additional tags
+// ----------------------------------------------------------------------------
+//  These methods get and set the values of Task.AdditionalTags as typed values.
+//  The getters return an error which wraps ErrTagNotFound if the task does not
+//  have the tag, or a *TagError if the value is invalid. For multi-valued tags,
+//  the typed getters use the first value.
+//
+//  The "due" and "t" tags are not in AdditionalTags but in Task.DueDate and
+//  Task.ThresholdDate. These methods get and set those fields for the keys,
+//  such as SetTagDate("due", date), so that the tags are never duplicated.
+//  Only a "t" tag which is not a date, such as "t:tbd", is in AdditionalTags.
+//
+//  The setters return a *TagError if the key or the value is empty or contains
+//  ":" or whitespaces, which would break the task line. So does a value which
+//  the TagCodec registered for the key fails to decode. See RegisterTagCodec.
+
+// AddTag adds a value to the additional tag with the given key. Unlike SetTag,
+// the existing values of the tag are kept. Such as "dep:3 dep:7". The "due" and
+// "t" tags have a single value, so it is the same as SetTag for them.
+func (task *Task) AddTag(key, value string) error {
+	if task.dateTagField(key) != nil {
+		return task.SetTag(key, value)
+	}
+
+	if err := checkTagText(key, value); err != nil {
+		return err
+	}
+
+	if err := validateTag(key, value); err != nil {
+		return err
+	}
+
+	task.AdditionalTags.Add(key, value)
+
+	return nil
+}
+
+// RemoveTag removes all the values of the additional tag with the given key
+// from the task.
+func (task *Task) RemoveTag(key string) {
+	if field := task.dateTagField(key); field != nil {
+		*field = Date{}
+	}
+
+	task.AdditionalTags.Remove(key)
+}
+
+// RemoveTagValue removes the given value of the additional tag with the given
+// key from the task. The other values of the tag are kept.
+func (task *Task) RemoveTagValue(key, value string) {
+	if field := task.dateTagField(key); field != nil && !field.IsZero() {
+		if field.formatLayout(DateLayout, true) == value {
+			*field = Date{}
+		}
+	}
+
+	task.AdditionalTags.RemoveValue(key, value)
+}
+
+// SetTag sets the string value of the additional tag with the given key. If the
+// tag has multiple values, they are replaced with the given value. The value of
+// the "due" and "t" tags must be a date in DateLayout, optionally with the time
+// of day. See DateTimeLayout.
+func (task *Task) SetTag(key, value string) error {
+	if field := task.dateTagField(key); field != nil {
+		date, err := parseDateTimeLayout(DateLayout, value)
+		if err != nil {
+			return &TagError{Err: err, Key: key, Value: value}
+		}
+
+		*field = date
+		task.AdditionalTags.Remove(key)
+
+		return nil
+	}
+
+	if err := checkTagText(key, value); err != nil {
+		return err
+	}
+
+	if err := validateTag(key, value); err != nil {
+		return err
+	}
+
+	task.AdditionalTags.Set(key, value)
+
+	return nil
+}
+
+// SetTagBool sets the value of the additional tag as a bool.
+func (task *Task) SetTagBool(key string, value bool) error {
+	return task.setTagTyped(key, TagCodecBool, value)
+}
+
+// SetTagDate sets the value of the additional tag as a date in DateLayout. For
+// the "due" and "t" keys, it sets Task.DueDate and Task.ThresholdDate as they
+// are, including the time of day.
+func (task *Task) SetTagDate(key string, value Date) error {
+	if field := task.dateTagField(key); field != nil {
+		*field = value
+		task.AdditionalTags.Remove(key)
+
+		return nil
+	}
+
+	return task.setTagTyped(key, TagCodecDate, value)
+}
+
+// SetTagDuration sets the value of the additional tag as a duration. Such as
+// "2w", "1d12h" or "90m".
+func (task *Task) SetTagDuration(key string, value time.Duration) error {
+	return task.setTagTyped(key, TagCodecDuration, value)
+}
+
+// SetTagInt sets the value of the additional tag as an int.
+func (task *Task) SetTagInt(key string, value int) error {
+	return task.setTagTyped(key, TagCodecInt, value)
+}
+
+// SetTagList sets the value of the additional tag as a comma separated list.
+func (task *Task) SetTagList(key string, value []string) error {
+	return task.setTagTyped(key, TagCodecList, value)
+}
+
+// SetTagValue sets the value of the additional tag with the TagCodec registered
+// for the key. See RegisterTagCodec. If no TagCodec is registered, the value
+// must be a string.
+func (task *Task) SetTagValue(key string, value any) error {
+	codec, ok := LookupTagCodec(key)
+	if !ok {
+		codec = TagCodecString
+	}
+
+	encoded, err := codec.Encode(value)
+	if err != nil {
+		return &TagError{Err: err, Key: key, Value: emptyStr}
+	}
+
+	return task.SetTag(key, encoded)
+}
+
+// Tag returns the string value of the additional tag with the given key. If the
+// tag has multiple values, the first one is returned. It returns false if the
+// task does not have the tag. The "due" and "t" tags are in DateLayout, with the
+// time of day if any.
+func (task *Task) Tag(key string) (string, bool) {
+	if field := task.dateTagField(key); field != nil && !field.IsZero() {
+		return field.formatLayout(DateLayout, true), true
+	}
+
+	return task.AdditionalTags.Get(key)
+}
+
+// TagBool returns the value of the additional tag as a bool. Such as "true",
+// "false", "yes" and "no".
+func (task *Task) TagBool(key string) (bool, error) {
+	return decodeTagTyped[bool](task, key, TagCodecBool)
+}
+
+// TagDate returns the value of the additional tag as a date in DateLayout. For
+// the "due" and "t" keys, it returns Task.DueDate and Task.ThresholdDate.
+func (task *Task) TagDate(key string) (Date, error) {
+	if field := task.dateTagField(key); field != nil && !field.IsZero() {
+		return *field, nil
+	}
+
+	return decodeTagTyped[Date](task, key, TagCodecDate)
+}
+
+// TagDuration returns the value of the additional tag as a duration. In
+// addition to the units of time.ParseDuration, "d" for days and "w" for weeks
+// are supported.
+func (task *Task) TagDuration(key string) (time.Duration, error) {
+	return decodeTagTyped[time.Duration](task, key, TagCodecDuration)
+}
+
+// TagInt returns the value of the additional tag as an int.
+func (task *Task) TagInt(key string) (int, error) {
+	return decodeTagTyped[int](task, key, TagCodecInt)
+}
+
+// TagList returns the value of the additional tag as a list of comma separated
+// strings.
+func (task *Task) TagList(key string) ([]string, error) {
+	return decodeTagTyped[[]string](task, key, TagCodecList)
+}
+
+// TagValues returns all the string values of the additional tag with the given
+// key in order of appearance.
+func (task *Task) TagValues(key string) []string {
+	if field := task.dateTagField(key); field != nil && !field.IsZero() {
+		return []string{field.formatLayout(DateLayout, true)}
+	}
+
+	return task.AdditionalTags.Values(key)
+}
+
+// TagValue returns the value of the additional tag decoded by the TagCodec
+// registered for the key. See RegisterTagCodec. If no TagCodec is registered,
+// the value is returned as a string.
+func (task *Task) TagValue(key string) (any, error) {
+	codec, ok := LookupTagCodec(key)
+	if !ok {
+		codec = TagCodecString
+	}
+
+	return task.decodeTag(key, codec)
+}
+
+// ValidateTags validates the values of all the additional tags with the
+// registered TagCodecs. It returns a *TagError of the first invalid tag.
+func (task *Task) ValidateTags() error {
+	for _, tag := range task.AdditionalTags {
+		if err := validateTag(tag.Key, tag.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dateTagField returns the field of Task.DueDate or Task.ThresholdDate if the
+// key is "due" or "t". Otherwise it returns nil.
+func (task *Task) dateTagField(key string) *Date {
+	switch key {
+	case "due":
+		return &task.DueDate
+	case "t":
+		return &task.ThresholdDate
+	}
+
+	return nil
+}
+
+// decodeTag returns the value of the additional tag decoded by the codec.
+func (task *Task) decodeTag(key string, codec TagCodec) (any, error) {
+	value, ok := task.Tag(key)
+	if !ok {
+		return nil, &TagError{Err: ErrTagNotFound, Key: key, Value: emptyStr}
+	}
+
+	decoded, err := codec.Decode(value)
+	if err != nil {
+		return nil, &TagError{Err: err, Key: key, Value: value}
+	}
+
+	return decoded, nil
+}
+
+// setTagTyped sets the value of the additional tag encoded by the codec. The
+// value must be of the type of the codec.
+func (task *Task) setTagTyped(key string, codec TagCodec, value any) error {
+	encoded, _ := codec.Encode(value) //nolint:errcheck // the type is always correct
+
+	return task.SetTag(key, encoded)
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// checkTagText returns a *TagError if the key or the value of a tag cannot be
+// written in the task line as is.
+func checkTagText(key, value string) error {
+	if isEmpty(key) || strings.ContainsAny(key, ":"+whitespaces) {
+		return &TagError{Err: errors.New("key must not be empty nor contain \":\" or whitespaces"), Key: key, Value: value}
+	}
+
+	if isEmpty(value) || strings.ContainsAny(value, ":"+whitespaces) {
+		return &TagError{Err: errors.New("value must not be empty nor contain \":\" or whitespaces"), Key: key, Value: value}
+	}
+
+	return nil
+}
+
+// decodeTagTyped returns the value of the additional tag decoded by the codec
+// as the type T.
+func decodeTagTyped[T any](task *Task, key string, codec TagCodec) (T, error) {
+	var zero T
+
+	decoded, err := task.decodeTag(key, codec)
+	if err != nil {
+		return zero, err
+	}
+
+	typed, _ := decoded.(T) //nolint:errcheck,forcetypeassert // the codec always returns T
+
+	return typed, nil
+}
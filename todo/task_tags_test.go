@@ -0,0 +1,147 @@
+package todo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTask_typed_tag_getters(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("Write report start:2020-05-01 pri:2 est:1d12h billable:yes who:bob,alice")
+	require.NoError(t, err)
+
+	date, err := task.TagDate("start")
+	require.NoError(t, err)
+	require.Equal(t, "2020-05-01", date.Format(DateLayout))
+
+	num, err := task.TagInt("pri")
+	require.NoError(t, err)
+	require.Equal(t, 2, num)
+
+	dur, err := task.TagDuration("est")
+	require.NoError(t, err)
+	require.Equal(t, 36*time.Hour, dur)
+
+	flag, err := task.TagBool("billable")
+	require.NoError(t, err)
+	require.True(t, flag)
+
+	list, err := task.TagList("who")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bob", "alice"}, list)
+
+	value, err := task.TagValue("who")
+	require.NoError(t, err)
+	require.Equal(t, "bob,alice", value, "tags without codec should be returned as string")
+}
+
+func TestTask_typed_tag_getters_error(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("Write report pri:high")
+	require.NoError(t, err)
+
+	_, err = task.TagInt("pri")
+	require.Error(t, err)
+
+	var tagErr *TagError
+
+	require.ErrorAs(t, err, &tagErr)
+	require.Equal(t, "pri", tagErr.Key)
+	require.Equal(t, "high", tagErr.Value)
+	require.Contains(t, err.Error(), `invalid tag "pri:high"`)
+
+	_, err = task.TagDate("t")
+	require.ErrorIs(t, err, ErrTagNotFound, "missing tag should wrap ErrTagNotFound")
+}
+
+func TestTask_typed_tag_setters(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("Write report")
+	require.NoError(t, err)
+
+	require.NoError(t, task.SetTagDate("start", NewDate(2020, 5, 1)))
+	require.NoError(t, task.SetTagInt("pri", 2))
+	require.NoError(t, task.SetTagDuration("est", 2*7*oneDay))
+	require.NoError(t, task.SetTagBool("billable", false))
+	require.NoError(t, task.SetTagList("who", []string{"bob", "alice"}))
+
+	require.Equal(t, "Write report billable:false est:14d pri:2 start:2020-05-01 who:bob,alice", task.String())
+
+	task.RemoveTag("who")
+
+	_, ok := task.Tag("who")
+	require.False(t, ok)
+
+	require.NoError(t, task.SetTagValue("note", "later"))
+	require.Error(t, task.SetTagValue("note", 10), "non-string value without codec should be an error")
+	require.NoError(t, task.ValidateTags())
+}
+
+func TestTask_tag_accessors_due_and_threshold(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("Write report t:2020-05-01 due:2020-05-10")
+	require.NoError(t, err)
+
+	date, err := task.TagDate("due")
+	require.NoError(t, err)
+	require.Equal(t, NewDate(2020, 5, 10), date)
+
+	value, ok := task.Tag("t")
+	require.True(t, ok)
+	require.Equal(t, "2020-05-01", value)
+	require.Equal(t, []string{"2020-05-10"}, task.TagValues("due"))
+
+	require.NoError(t, task.SetTagDate("due", NewDate(2020, 5, 20)))
+	require.NoError(t, task.SetTag("t", "2020-05-02"))
+	require.Equal(t, NewDate(2020, 5, 20), task.DueDate)
+	require.Equal(t, NewDate(2020, 5, 2), task.ThresholdDate)
+	require.Equal(t, "Write report t:2020-05-02 due:2020-05-20", task.String(), "tags should not be duplicated")
+	require.Empty(t, task.AdditionalTags)
+
+	require.Error(t, task.SetTag("due", "tomorrow"), "invalid date should be an error")
+	require.Equal(t, NewDate(2020, 5, 20), task.DueDate, "due date should be kept on error")
+
+	task.RemoveTagValue("t", "2020-05-01")
+	require.True(t, task.HasThresholdDate(), "other value should not be removed")
+
+	task.RemoveTag("t")
+	require.False(t, task.HasThresholdDate())
+
+	_, err = task.TagDate("t")
+	require.ErrorIs(t, err, ErrTagNotFound)
+}
+
+func TestTask_tag_setters_invalid(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("Write report")
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		key   string
+		value string
+	}{
+		{key: "", value: "1"},
+		{key: "see", value: "http://example.com"},
+		{key: "my key", value: "1"},
+		{key: "a:b", value: "1"},
+		{key: "note", value: "two words"},
+		{key: "note", value: ""},
+	} {
+		var tagErr *TagError
+
+		require.ErrorAs(t, task.SetTag(test.key, test.value), &tagErr, "key: %q, value: %q", test.key, test.value)
+		require.ErrorAs(t, task.AddTag(test.key, test.value), &tagErr, "key: %q, value: %q", test.key, test.value)
+	}
+
+	require.Error(t, task.SetTagInt("my key", 1))
+	require.Error(t, task.SetTagList("who", []string{"bob smith"}))
+	require.Empty(t, task.AdditionalTags, "invalid tags should not be set")
+	require.Equal(t, "Write report", task.String())
+}
@@ -244,7 +244,7 @@ func TestTask_CreatedDate(t *testing.T) {
 		taskID := test.taskID
 		task := testTasklist[taskID-1]
 
-		expectTime, err := parseTime(test.expect)
+		expectTime, err := ParseDate(test.expect)
 		require.NoError(t, err, "failed to parse time for testing")
 
 		actualTime := task.CreatedDate
@@ -351,7 +351,7 @@ func TestTask_DueDate(t *testing.T) {
 		taskID := 23
 		task := testTasklist[taskID-1]
 
-		expectTime, err := parseTime("2014-02-17")
+		expectTime, err := ParseDate("2014-02-17")
 		require.NoError(t, err, "failed to parse expected time for testing")
 
 		actualTime := task.DueDate
@@ -428,7 +428,7 @@ func TestTask_AddonTags(t *testing.T) {
 		taskID := 25
 		task := testTasklist[taskID-1]
 
-		expectTags := map[string]string{"Level": "5", "private": "false"}
+		expectTags := Tags{{Key: "private", Value: "false"}, {Key: "Level", Value: "5"}}
 		actualTags := task.AdditionalTags
 
 		require.Equal(t, expectTags, actualTags,
@@ -438,7 +438,7 @@ func TestTask_AddonTags(t *testing.T) {
 		taskID := 26
 		task := testTasklist[taskID-1]
 
-		expectTags := map[string]string{"Importance": "Very!"}
+		expectTags := Tags{{Key: "Importance", Value: "Very!"}}
 		actualTags := testTasklist[taskID-1].AdditionalTags
 
 		require.Equal(t, expectTags, actualTags,
@@ -540,7 +540,7 @@ func TestTask_CompletedDate(t *testing.T) {
 	{
 		taskID := 35
 
-		expectCompletedDate, err := parseTime("2014-01-03")
+		expectCompletedDate, err := ParseDate("2014-01-03")
 		require.NoError(t, err, "failed to parse time for task[%d]", taskID)
 
 		actualCompletedDate := testTasklist[taskID-1].CompletedDate
@@ -556,7 +556,7 @@ func TestTask_CompletedDate(t *testing.T) {
 	{
 		taskID := 37
 
-		expectCompletedDate, err := parseTime("2014-01-02")
+		expectCompletedDate, err := ParseDate("2014-01-02")
 		require.NoError(t, err, "failed to parse time for task[%d]", taskID)
 
 		actualCompletedDate := testTasklist[taskID-1].CompletedDate
@@ -566,7 +566,7 @@ func TestTask_CompletedDate(t *testing.T) {
 	{
 		taskID := 38
 
-		expectCompletedDate, err := parseTime("2014-01-03")
+		expectCompletedDate, err := ParseDate("2014-01-03")
 		require.NoError(t, err, "failed to parse time for task[%d]", taskID)
 
 		actualCompletedDate := testTasklist[taskID-1].CompletedDate
@@ -598,10 +598,11 @@ func TestTask_IsOverdue(t *testing.T) {
 		require.False(t, testTasklist[taskID-1].IsOverdue(),
 			"task[%d] should not be as overdue: %s", taskID, testTasklist[taskID-1].String())
 
-		// Update the due date to be now
-		testTasklist[taskID-1].DueDate = time.Now()
+		// Update the due date to be today, at the beginning of the day
+		now := time.Date(2020, 6, 10, 0, 0, 0, 0, time.Local)
+		testTasklist[taskID-1].DueDate = DateOf(now)
 
-		dueHours := testTasklist[taskID-1].Due().Hours()
+		dueHours := testTasklist[taskID-1].Due(WithClock(FixedClock(now))).Hours()
 		require.True(t, dueHours > 23.0 && dueHours < 25.0,
 			"task[%d] should be due in 24 hours: %s", taskID, testTasklist[taskID-1].String())
 	}
@@ -612,7 +613,7 @@ func TestTask_IsOverdue(t *testing.T) {
 			"task[%d] should be as overdue: %s", taskID, testTasklist[taskID-1].String())
 
 		// Update the due date to be 4 days ago
-		testTasklist[taskID-1].DueDate = time.Now().AddDate(0, 0, -4)
+		testTasklist[taskID-1].DueDate = Today().AddDays(-4)
 
 		dueHours := testTasklist[taskID-1].Due().Hours()
 
@@ -621,7 +622,7 @@ func TestTask_IsOverdue(t *testing.T) {
 			taskID, dueHours, testTasklist[taskID-1].String(),
 		)
 
-		testTasklist[taskID-1].DueDate = time.Now().AddDate(0, 0, 2)
+		testTasklist[taskID-1].DueDate = Today().AddDays(2)
 
 		dueHours = testTasklist[taskID-1].Due().Hours()
 		require.True(t, dueHours > 71 || dueHours < 73,
@@ -637,6 +638,59 @@ func TestTask_IsOverdue(t *testing.T) {
 	}
 }
 
+func TestTask_ThresholdDate(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("(A) Plan the trip t:2020-05-01 @Home due:2020-06-01 Level:5")
+	require.NoError(t, err)
+
+	require.True(t, task.HasThresholdDate())
+	require.Equal(t, "2020-05-01", task.ThresholdDate.Format(DateLayout))
+	require.False(t, task.AdditionalTags.Has("t"), "threshold date should not be in the additional tags")
+	require.Equal(t, "(A) Plan the trip @Home Level:5 t:2020-05-01 due:2020-06-01", task.String())
+	require.True(t, task.IsThresholdReached())
+	require.True(t, task.IsActive())
+
+	// Future threshold date
+	task.ThresholdDate = Today().AddDays(1)
+	require.False(t, task.IsThresholdReached())
+	require.False(t, task.IsActive(), "task with future threshold date should not be active")
+
+	// Threshold date of today
+	task.ThresholdDate = Today()
+	require.True(t, task.IsActive())
+
+	// Completed task
+	task.Complete()
+	require.False(t, task.IsActive(), "completed task should not be active")
+
+	// Task without threshold date
+	task, err = ParseTask("Plan the trip")
+	require.NoError(t, err)
+	require.False(t, task.HasThresholdDate())
+	require.True(t, task.IsActive())
+
+	// Values which are not dates are kept as additional tags
+	for _, text := range []string{"Plan the trip t:tbd @Home", "Plan the trip t:2020-13-45 @Home"} {
+		task, err = ParseTask(text)
+		require.NoError(t, err, "text: %s", text)
+		require.False(t, task.HasThresholdDate())
+		require.True(t, task.AdditionalTags.Has("t"))
+		require.True(t, task.IsActive())
+		require.Equal(t, text, task.StringWith(WithPreserveOriginal(true)))
+
+		segs := task.InlineSegments()
+		require.Equal(t, SegmentTag, segs[1].Type, "text: %s", text)
+	}
+
+	value, ok := task.Tag("t")
+	require.True(t, ok)
+	require.Equal(t, "2020-13-45", value)
+
+	require.NoError(t, task.SetTag("t", "2020-05-01"))
+	require.Equal(t, "Plan the trip @Home t:2020-05-01", task.String(), "additional t: tag should be replaced")
+}
+
 func TestTask_Complete(t *testing.T) {
 	t.Parallel()
 
@@ -693,6 +747,38 @@ func TestTask_Complete(t *testing.T) {
 	}
 }
 
+func TestTask_with_clock(t *testing.T) {
+	t.Parallel()
+
+	// 23:30 in UTC is already the next day in JST
+	now := time.Date(2020, 3, 8, 23, 30, 0, 0, time.UTC)
+	utc := []Option{WithClock(FixedClock(now)), WithLocation(time.UTC)}
+	jst := []Option{WithClock(FixedClock(now)), WithLocation(time.FixedZone("JST", 9*60*60))}
+
+	task, err := ParseTask("Write report t:2020-03-09 due:2020-03-08 rec:1d")
+	require.NoError(t, err)
+
+	require.True(t, task.IsDueToday(utc...))
+	require.False(t, task.IsOverdue(utc...))
+	require.False(t, task.IsThresholdReached(utc...))
+	require.False(t, task.IsActive(utc...))
+
+	require.False(t, task.IsDueToday(jst...))
+	require.True(t, task.IsOverdue(jst...))
+	require.True(t, task.IsThresholdReached(jst...))
+	require.True(t, task.IsActive(jst...))
+
+	require.Equal(t, "2020-03-08", NewTask(utc...).CreatedDate.Format(DateLayout))
+	require.Equal(t, "2020-03-09", NewTask(jst...).CreatedDate.Format(DateLayout))
+
+	next, err := task.NextInstance(jst...)
+	require.NoError(t, err)
+	require.Equal(t, "2020-03-10", next.DueDate.Format(DateLayout), "should be a day after today in JST")
+
+	task.Complete(jst...)
+	require.Equal(t, "2020-03-09", task.CompletedDate.Format(DateLayout))
+}
+
 //nolint:funlen // leave it as is since it's a test
 func TestTask_Reopen(t *testing.T) {
 	t.Parallel()
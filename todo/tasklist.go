@@ -16,6 +16,11 @@ import (
 // TaskList represents a list of todo.txt task entries.
 // It is usually loaded from a whole todo.txt file.
 //
+// If loaded with the WithKeepNonTaskLines(true) option, the list also holds the comment and
+// blank lines of the file as non-task entries (see Task.NonTask). Such entries
+// have no ID and are skipped by Count, Filter, GetTask and the removals, while
+// Sort keeps them at their positions.
+//
 //nolint:recvcheck // The method Filter uses non-pointer receiver so it is mixed
 type TaskList []Task
 
@@ -26,32 +31,65 @@ type TaskList []Task
 // LoadFromFile loads and returns a TaskList from io.Reader.
 //
 // This function aims to be used with os.File, os.Stdin or any other io.Reader.
-func LoadFromFile(file io.Reader) (TaskList, error) {
+// To process large files without loading the whole list, use Reader instead.
+// The options are used to change the default configuration. See Config.
+func LoadFromFile(file io.Reader, opts ...Option) (TaskList, error) {
 	var tasklist TaskList
 
-	if err := tasklist.LoadFromFile(file); err != nil {
+	if err := tasklist.LoadFromFile(file, opts...); err != nil {
 		return nil, errors.Wrap(err, "failed to load from file")
 	}
 
 	return tasklist, nil
 }
 
+// LoadFromFileLenient loads and returns a TaskList from io.Reader. Unlike
+// LoadFromFile, it does not stop at the first invalid line.
+//
+// The lines that failed to parse are skipped and reported as ParseErrors, along
+// with the TaskList of the tasks that were parsed successfully. Any other error,
+// such as a read error, returns a nil TaskList.
+func LoadFromFileLenient(file io.Reader, opts ...Option) (TaskList, error) {
+	var tasklist TaskList
+
+	err := tasklist.LoadFromFileLenient(file, opts...)
+	if err != nil && !isParseErrors(err) {
+		return nil, errors.Wrap(err, "failed to load from file")
+	}
+
+	return tasklist, err
+}
+
 // LoadFromPath loads and returns a TaskList from a file (most likely called "todo.txt").
-func LoadFromPath(filename string) (TaskList, error) {
+func LoadFromPath(filename string, opts ...Option) (TaskList, error) {
 	var tasklist TaskList
 
-	if err := tasklist.LoadFromPath(filename); err != nil {
+	if err := tasklist.LoadFromPath(filename, opts...); err != nil {
 		return nil, err
 	}
 
 	return tasklist, nil
 }
 
+// LoadFromPathLenient loads and returns a TaskList from a file. Like
+// LoadFromFileLenient, the lines that failed to parse are reported as
+// ParseErrors along with the TaskList of the tasks that were parsed.
+func LoadFromPathLenient(filename string, opts ...Option) (TaskList, error) {
+	var tasklist TaskList
+
+	err := tasklist.LoadFromPathLenient(filename, opts...)
+	if err != nil && !isParseErrors(err) {
+		return nil, err
+	}
+
+	return tasklist, err
+}
+
 // LoadFromString loads and returns a TaskList from a string.
-func LoadFromString(str string) (TaskList, error) {
+func LoadFromString(str string, opts ...Option) (TaskList, error) {
 	reader := strings.NewReader(str)
 
-	return LoadFromFile(reader)
+	return LoadFromFile(reader, opts...)
 }
 
 // NewTaskList creates a new empty TaskList.
@@ -79,9 +117,18 @@ func (tasklist *TaskList) AddTask(task *Task) {
 	*tasklist = append(*tasklist, *task)
 }
 
-// Count returns the number of tasks in the TaskList.
+// Count returns the number of tasks in the TaskList. Non-task entries are not
+// counted.
 func (tasklist *TaskList) Count() int {
-	return len(*tasklist)
+	count := 0
+
+	for _, task := range *tasklist {
+		if !task.NonTask {
+			count++
+		}
+	}
+
+	return count
 }
 
 /* TaskList.Filter() has been moved to tasklist_filter.go */
@@ -91,7 +138,7 @@ func (tasklist *TaskList) Count() int {
 // Returns an error if Task could not be found.
 func (tasklist *TaskList) GetTask(id int) (*Task, error) {
 	for i := range *tasklist {
-		if ([]Task(*tasklist))[i].ID == id {
+		if ([]Task(*tasklist))[i].ID == id && !([]Task(*tasklist))[i].NonTask {
 			return &([]Task(*tasklist))[i], nil
 		}
 	}
@@ -102,55 +149,52 @@ func (tasklist *TaskList) GetTask(id int) (*Task, error) {
 // LoadFromFile loads a TaskList from io.Reader.
 //
 // This function aims to be used with os.File, os.Stdin or any other io.Reader.
+// It stops at the first line that failed to parse and the returned error wraps
+// a *ParseError with the line number.
 //
 // Note: This will clear the current TaskList and overwrite it's contents with whatever is in *os.File.
-func (tasklist *TaskList) LoadFromFile(file io.Reader) error {
-	if file == nil {
-		return errors.New("nil io.Reader")
-	}
-
-	*tasklist = []Task{} // Empty task list
-
-	taskID := 1
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		text := strings.Trim(scanner.Text(), whitespaces) // Read line
-
-		// Ignore blank or comment lines
-		if isEmpty(text) || (IgnoreComments && strings.HasPrefix(text, "#")) {
-			continue
-		}
-
-		task, err := ParseTask(text)
-		if err != nil {
-			return err
-		}
+func (tasklist *TaskList) LoadFromFile(file io.Reader, opts ...Option) error {
+	return tasklist.loadFromFile(file, false, NewConfig(opts...))
+}
 
-		task.ID = taskID
-		*tasklist = append(*tasklist, *task)
+// LoadFromFileLenient loads a TaskList from io.Reader without stopping at the
+// first line that failed to parse. Such lines are skipped and returned as
+// ParseErrors once the whole file has been read.
+//
+// Note: This will clear the current TaskList and overwrite it's contents with whatever is in *os.File.
+func (tasklist *TaskList) LoadFromFileLenient(file io.Reader, opts ...Option) error {
+	return tasklist.loadFromFile(file, true, NewConfig(opts...))
+}
 
-		taskID++
+// LoadFromPath loads a TaskList from a file (most likely called "todo.txt").
+//
+// Note: This will clear the current TaskList and overwrite it's contents with whatever is in the file.
+func (tasklist *TaskList) LoadFromPath(filename string, opts ...Option) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return errors.Wrap(err, "failed to open file: "+filename)
 	}
+	defer file.Close()
 
-	return errors.Wrap(scanner.Err(), "failed to load from file")
+	return tasklist.LoadFromFile(file, opts...)
 }
 
-// LoadFromPath loads a TaskList from a file (most likely called "todo.txt").
+// LoadFromPathLenient loads a TaskList from a file without stopping at the first
+// line that failed to parse. See LoadFromFileLenient.
 //
 // Note: This will clear the current TaskList and overwrite it's contents with whatever is in the file.
-func (tasklist *TaskList) LoadFromPath(filename string) error {
+func (tasklist *TaskList) LoadFromPathLenient(filename string, opts ...Option) error {
 	file, err := os.Open(filename)
 	if err != nil {
 		return errors.Wrap(err, "failed to open file: "+filename)
 	}
 	defer file.Close()
 
-	return tasklist.LoadFromFile(file)
+	return tasklist.LoadFromFileLenient(file, opts...)
 }
 
 // RemoveTask removes any Task from the TaskList with the same String representation
-// as the given Task.
+// as the given Task. Non-task entries are never removed.
 // Returns an error if no Task was removed.
 func (tasklist *TaskList) RemoveTask(task Task) error {
 	var newList TaskList
@@ -158,7 +202,7 @@ func (tasklist *TaskList) RemoveTask(task Task) error {
 	found := false
 
 	for _, t := range *tasklist {
-		if t.String() != task.String() {
+		if t.NonTask || t.String() != task.String() {
 			newList = append(newList, t)
 		} else {
 			found = true
@@ -182,7 +226,7 @@ func (tasklist *TaskList) RemoveTaskByID(taskID int) error {
 	found := false
 
 	for _, t := range *tasklist {
-		if t.ID != taskID {
+		if t.NonTask || t.ID != taskID {
 			newList = append(newList, t)
 		} else {
 			found = true
@@ -200,13 +244,95 @@ func (tasklist *TaskList) RemoveTaskByID(taskID int) error {
 
 /* TaskList.Sort() has been moved to tasklist_sort.go */
 
+// splitNonTask returns a copy of the TaskList without the non-task entries,
+// along with the indexes of the tasks in the current TaskList.
+func (tasklist *TaskList) splitNonTask() (TaskList, []int) {
+	tasks := make(TaskList, 0, len(*tasklist))
+	indexes := make([]int, 0, len(*tasklist))
+
+	for i, task := range *tasklist {
+		if !task.NonTask {
+			tasks = append(tasks, task)
+			indexes = append(indexes, i)
+		}
+	}
+
+	return tasks, indexes
+}
+
+// mergeNonTask puts the given tasks back to the given indexes of the current
+// TaskList. It is the counterpart of splitNonTask, so the non-task entries stay
+// at their positions.
+func (tasklist *TaskList) mergeNonTask(tasks TaskList, indexes []int) {
+	for i, index := range indexes {
+		(*tasklist)[index] = tasks[i]
+	}
+}
+
+// loadFromFile loads a TaskList from io.Reader with the given configuration.
+// If lenient is true, the lines that failed to parse are collected as ParseErrors
+// instead of stopping there.
+func (tasklist *TaskList) loadFromFile(file io.Reader, lenient bool, cfg *Config) error {
+	if file == nil {
+		return errors.New("nil io.Reader")
+	}
+
+	*tasklist = []Task{} // Empty task list
+
+	var parseErrs ParseErrors
+
+	reader := newReader(file, cfg)
+
+	for {
+		task, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			parseErr, ok := asParseError(err)
+			if !ok {
+				return errors.Wrap(err, "failed to load from file")
+			}
+
+			if !lenient {
+				return err
+			}
+
+			parseErrs = append(parseErrs, parseErr)
+
+			continue
+		}
+
+		*tasklist = append(*tasklist, *task)
+	}
+
+	if len(parseErrs) > 0 {
+		return parseErrs
+	}
+
+	return nil
+}
+
 // String returns a complete list of tasks in todo.txt format.
 func (tasklist *TaskList) String() string {
+	return tasklist.stringWith(NewConfig())
+}
+
+// StringWith returns a complete list of tasks in todo.txt format with the given
+// options. The options are used to change the default configuration. See Config.
+func (tasklist *TaskList) StringWith(opts ...Option) string {
+	return tasklist.stringWith(NewConfig(opts...))
+}
+
+// stringWith returns a complete list of tasks in todo.txt format with the given
+// configuration.
+func (tasklist *TaskList) stringWith(cfg *Config) string {
 	var strBldr strings.Builder
 
 	for _, task := range *tasklist {
-		strBldr.WriteString(task.String())
-		strBldr.WriteString(NewLine)
+		strBldr.WriteString(task.stringWith(cfg))
+		strBldr.WriteString(cfg.NewLine)
 	}
 
 	return strBldr.String()
@@ -215,13 +341,15 @@ func (tasklist *TaskList) String() string {
 // WriteToFile writes a TaskList to *os.File.
 //
 // Using *os.File instead of a filename allows to also use os.Stdout.
+// The options are used to change the default configuration. See Config.
 //
 // Note: Comments from original file will be omitted and not written to target
-// *os.File, if IgnoreComments is set to 'true'.
-func (tasklist *TaskList) WriteToFile(file *os.File) error {
+// *os.File, if IgnoreComments is set to 'true' and they were not loaded with the
+// WithKeepNonTaskLines(true) option.
+func (tasklist *TaskList) WriteToFile(file *os.File, opts ...Option) error {
 	writer := bufio.NewWriter(file)
 
-	if _, err := writer.WriteString(tasklist.String()); err != nil {
+	if _, err := writer.WriteString(tasklist.StringWith(opts...)); err != nil {
 		return errors.Wrap(err, "failed to write string to buffer")
 	}
 
@@ -230,9 +358,10 @@ func (tasklist *TaskList) WriteToFile(file *os.File) error {
 }
 
 // WriteToPath writes a TaskList to the specified file (most likely called "todo.txt").
-func (tasklist *TaskList) WriteToPath(filename string) error {
+// The options are used to change the default configuration. See Config.
+func (tasklist *TaskList) WriteToPath(filename string, opts ...Option) error {
 	return errors.Wrap(
-		os.WriteFile(filename, []byte(tasklist.String()), PermReadWrite),
+		os.WriteFile(filename, []byte(tasklist.StringWith(opts...)), PermReadWrite),
 		"failed to save task list to the path: "+filename,
 	)
 }
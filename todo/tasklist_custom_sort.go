@@ -11,11 +11,45 @@ import (
 // CustomSort allows a TaskList to be sorted by a custom function.
 //
 // The providing function must return true if taskA is less than taskB.
+// Non-task entries, such as comment or blank lines, stay at their positions.
 func (tasklist *TaskList) CustomSort(isALessThanB func(taskA, taskB Task) bool) {
+	tasks, indexes := tasklist.splitNonTask()
+	defer tasklist.mergeNonTask(tasks, indexes)
+
 	sort.Slice(
-		*tasklist,
+		tasks,
 		func(i int, j int) bool {
-			return isALessThanB((*tasklist)[i], (*tasklist)[j])
+			return isALessThanB(tasks[i], tasks[j])
 		},
 	)
 }
+
+// ----------------------------------------------------------------------------
+//  Less functions for CustomSort()
+// ----------------------------------------------------------------------------
+
+// LessByTagAsc returns a function for CustomSort which sorts the tasks by the
+// values of the additional tag with the given key in ascending order.
+//
+// The values of multi-valued tags, such as "dep:3 dep:7", are compared in order
+// of appearance as the contexts and projects are. The tasks without the tag
+// come last.
+func LessByTagAsc(key string) func(taskA, taskB Task) bool {
+	return func(taskA, taskB Task) bool {
+		return lessStrings(taskA.AdditionalTags.Values(key), taskB.AdditionalTags.Values(key))
+	}
+}
+
+// LessByTagDesc is the same as LessByTagAsc but in descending order. The tasks
+// without the tag come last as well.
+func LessByTagDesc(key string) func(taskA, taskB Task) bool {
+	return func(taskA, taskB Task) bool {
+		valuesA, valuesB := taskA.AdditionalTags.Values(key), taskB.AdditionalTags.Values(key)
+
+		if len(valuesA) == 0 || len(valuesB) == 0 {
+			return len(valuesA) > len(valuesB)
+		}
+
+		return lessStrings(valuesB, valuesA)
+	}
+}
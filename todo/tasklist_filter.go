@@ -7,6 +7,13 @@ package todo
 // Filter filters the current TaskList for the given predicate, and returns a
 // new TaskList. The original TaskList is not modified.
 //
+// If multiple predicates are given, the tasks that match any of them are kept
+// (OR semantics). Use FilterAll for AND semantics, or combine the predicates
+// with FilterAnd, FilterOr and FilterNot, or an Expr, for more complex cases.
+//
+// Non-task entries, such as comment or blank lines, are not included in the
+// filtered TaskList.
+//
 //	For the Predicate type filters see the todo/filters.go file.
 func (tasklist TaskList) Filter(filter Predicate, filters ...Predicate) TaskList {
 	combined := []Predicate{filter}
@@ -15,6 +22,10 @@ func (tasklist TaskList) Filter(filter Predicate, filters ...Predicate) TaskList
 	newList := []Task{}
 
 	for _, task := range tasklist {
+		if task.NonTask {
+			continue
+		}
+
 		for _, filt := range combined {
 			// Append tasks to the new list if the filter returns true.
 			if filt(task) {
@@ -27,3 +38,16 @@ func (tasklist TaskList) Filter(filter Predicate, filters ...Predicate) TaskList
 
 	return TaskList(newList)
 }
+
+// FilterAll is the same as Filter but with AND semantics. The tasks that match
+// all the given predicates are kept. See FilterAll for the Predicate version.
+func (tasklist TaskList) FilterAll(filter Predicate, filters ...Predicate) TaskList {
+	return tasklist.Filter(FilterAll(append([]Predicate{filter}, filters...)...))
+}
+
+// FilterAny is the same as Filter, which has OR semantics. The tasks that match
+// any of the given predicates are kept. It is provided to make the semantics
+// explicit along with FilterAll.
+func (tasklist TaskList) FilterAny(filter Predicate, filters ...Predicate) TaskList {
+	return tasklist.Filter(filter, filters...)
+}
@@ -48,10 +48,10 @@ func TestTaskList_Filter_filter_by_multiple_predicates(t *testing.T) {
 	// Load test data
 	testTasklist := testLoadFromPath(t, testInputFilter)
 
-	now := time.Now()
-	testTasklist[0].DueDate = now.AddDate(0, 0, -2)
-	testTasklist[1].DueDate = now
-	testTasklist[2].DueDate = now.AddDate(0, 0, 1)
+	today := Today()
+	testTasklist[0].DueDate = today.AddDays(-2)
+	testTasklist[1].DueDate = today
+	testTasklist[2].DueDate = today.AddDays(1)
 
 	// and -- filters tasks with priority and is overdue and is completed
 	{
@@ -90,10 +90,10 @@ func TestTaskList_Filter_Helpers(t *testing.T) {
 	// Load test data
 	testTasklist := testLoadFromPath(t, testInputFilter)
 
-	now := time.Now()
-	testTasklist[0].DueDate = now.AddDate(0, 0, -2)
-	testTasklist[1].DueDate = now
-	testTasklist[2].DueDate = now.AddDate(0, 0, 1)
+	today := Today()
+	testTasklist[0].DueDate = today.AddDays(-2)
+	testTasklist[1].DueDate = today
+	testTasklist[2].DueDate = today.AddDays(1)
 
 	for testNum, test := range []struct {
 		predicate Predicate
@@ -126,3 +126,97 @@ func TestTaskList_Filter_Helpers(t *testing.T) {
 			"test case #%d failed. It did not filter as expected:\n%s", testNum+1, filteredList.String())
 	}
 }
+
+func TestTaskList_Filter_filter_by_tag(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString("Deploy dep:3 dep:7\nTest dep:3\nWrite docs\nReview dep:8")
+	require.NoError(t, err)
+
+	require.Len(t, tasklist.Filter(FilterByTag("dep")), 3, "tasks with the tag should match")
+	require.Len(t, tasklist.Filter(FilterByTag("dep", "7")), 1, "any of the values should match")
+	require.Len(t, tasklist.Filter(FilterByTag("dep", "3", "8")), 3)
+	require.Empty(t, tasklist.Filter(FilterByTag("dep", "1")))
+}
+
+func TestTaskList_Filter_filter_threshold_reached(t *testing.T) {
+	t.Parallel()
+
+	future := time.Now().AddDate(0, 0, 3).Format(DateLayout)
+
+	tasklist, err := LoadFromString("Task A t:2014-01-12\nTask B\nTask C t:" + future + "\nx Task D t:2014-01-05")
+	require.NoError(t, err)
+
+	require.Len(t, tasklist.Filter(FilterHasThresholdDate), 3)
+	require.Len(t, tasklist.Filter(FilterThresholdReached), 3, "future-dated tasks should be hidden")
+	require.Len(t, tasklist.Filter(FilterActive), 2, "completed tasks should not be active")
+}
+
+func TestTaskList_Filter_combinators(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString("(A) Write +X\nx (A) Ship +X\n(B) Plan +Y\nCall Mom +X")
+	require.NoError(t, err)
+
+	// AND semantics
+	require.Len(t, tasklist.FilterAll(FilterByProject("X"), FilterNotCompleted), 2)
+	require.Len(t, tasklist.Filter(FilterAnd(FilterByProject("X"), FilterNotCompleted, FilterHasPriority)), 1)
+
+	// OR semantics
+	require.Len(t, tasklist.FilterAny(FilterByProject("Y"), FilterCompleted), 2)
+	require.Len(t, tasklist.Filter(FilterOr(FilterByProject("Y"), FilterCompleted)), 2)
+
+	// Grouping: not completed and (priority A or project Y)
+	filter := FilterAnd(FilterNot(FilterCompleted), FilterOr(FilterByPriority("A"), FilterByProject("Y")))
+	require.Len(t, tasklist.Filter(filter), 2)
+
+	// Empty combinators
+	require.Len(t, tasklist.Filter(FilterAll()), 4, "FilterAll with no predicates should match any task")
+	require.Empty(t, tasklist.Filter(FilterAny()), "FilterAny with no predicates should match no task")
+}
+
+func TestTaskList_Filter_date_ranges(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString(
+		"2020-03-01 Write report due:2020-03-08\n" + // 1
+			"2020-03-07 Review report due:2020-03-09\n" + // 2
+			"x 2020-03-08 2020-02-20 Send report due:2020-03-12\n" + // 3
+			"x 2020-03-05 Call Mom\n" + // 4
+			"Plan trip\n", // 5
+	)
+	require.NoError(t, err)
+
+	date := func(day int) time.Time {
+		return time.Date(2020, 3, day, 0, 0, 0, 0, time.Local)
+	}
+
+	// Just after midnight in UTC, but still the previous day in New York
+	nyc := time.FixedZone("EST", -5*60*60)
+	clock := WithClock(FixedClock(time.Date(2020, 3, 8, 3, 0, 0, 0, time.UTC)))
+
+	for _, test := range []struct {
+		filter Predicate
+		name   string
+		expect []int
+	}{
+		{name: "due before", filter: FilterDueBefore(date(9)), expect: []int{1}},
+		{name: "due after", filter: FilterDueAfter(date(8)), expect: []int{2, 3}},
+		{name: "due between", filter: FilterDueBetween(date(8), date(9)), expect: []int{1, 2}},
+		{name: "due within", filter: FilterDueWithin(1, clock, WithLocation(time.UTC)), expect: []int{1, 2}},
+		{name: "due within in location", filter: FilterDueWithin(1, clock, WithLocation(nyc)), expect: []int{1}},
+		{name: "due within today", filter: FilterDueWithin(0, clock, WithLocation(time.UTC)), expect: []int{1}},
+		{name: "created within", filter: FilterCreatedWithin(7, clock, WithLocation(time.UTC)), expect: []int{1, 2}},
+		{name: "created within in location", filter: FilterCreatedWithin(0, clock, WithLocation(nyc)), expect: []int{2}},
+		{name: "completed on", filter: FilterCompletedOn(date(8)), expect: []int{3}},
+		{name: "completed between", filter: FilterCompletedBetween(date(1), date(31)), expect: []int{3, 4}},
+	} {
+		actual := []int{}
+
+		for _, task := range tasklist.Filter(test.filter) {
+			actual = append(actual, task.ID)
+		}
+
+		require.Equal(t, test.expect, actual, "filter: %s", test.name)
+	}
+}
@@ -13,8 +13,14 @@ import (
 // Sort allows a TaskList to be sorted by certain predefined fields. Multiple-key
 // sorting is supported. See constants Sort* for fields and sort order.
 //
-//nolint:cyclop // complexity is 12 but leave it as is
+// Non-task entries, such as comment or blank lines, stay at their positions and
+// only the tasks are sorted among themselves.
+//
+//nolint:cyclop // complexity is 13 but leave it as is
 func (tasklist *TaskList) Sort(flag TaskSortByType, flags ...TaskSortByType) error {
+	tasks, indexes := tasklist.splitNonTask()
+	defer tasklist.mergeNonTask(tasks, indexes)
+
 	lenFlags := len(flags)
 	combined := make([]TaskSortByType, lenFlags+1)
 	index := 0
@@ -29,21 +35,23 @@ func (tasklist *TaskList) Sort(flag TaskSortByType, flags ...TaskSortByType) err
 	for _, flag := range combined {
 		switch flag {
 		case SortTaskIDAsc, SortTaskIDDesc:
-			tasklist.sortByTaskID(flag)
+			tasks.sortByTaskID(flag)
 		case SortTodoTextAsc, SortTodoTextDesc:
-			tasklist.sortByTodoText(flag)
+			tasks.sortByTodoText(flag)
 		case SortPriorityAsc, SortPriorityDesc:
-			tasklist.sortByPriority(flag)
+			tasks.sortByPriority(flag)
 		case SortCreatedDateAsc, SortCreatedDateDesc:
-			tasklist.sortByCreatedDate(flag)
+			tasks.sortByCreatedDate(flag)
 		case SortCompletedDateAsc, SortCompletedDateDesc:
-			tasklist.sortByCompletedDate(flag)
+			tasks.sortByCompletedDate(flag)
 		case SortDueDateAsc, SortDueDateDesc:
-			tasklist.sortByDueDate(flag)
+			tasks.sortByDueDate(flag)
 		case SortContextAsc, SortContextDesc:
-			tasklist.sortByContext(flag)
+			tasks.sortByContext(flag)
 		case SortProjectAsc, SortProjectDesc:
-			tasklist.sortByProject(flag)
+			tasks.sortByProject(flag)
+		case SortThresholdDateAsc, SortThresholdDateDesc:
+			tasks.sortByThresholdDate(flag)
 		default:
 			return errors.New("unrecognized sort option")
 		}
@@ -167,6 +175,20 @@ func (tasklist *TaskList) sortByTaskID(order TaskSortByType) *TaskList {
 	return tasklist
 }
 
+func (tasklist *TaskList) sortByThresholdDate(order TaskSortByType) *TaskList {
+	tasklist.sortBy(func(task1, task2 *Task) bool {
+		return sortByDate(
+			order == SortThresholdDateAsc, // is asc
+			task1.HasThresholdDate(),      // hasDate1
+			task2.HasThresholdDate(),      // hasDate2
+			task1.ThresholdDate,           // date1
+			task2.ThresholdDate,           // date2
+		)
+	})
+
+	return tasklist
+}
+
 func (tasklist *TaskList) sortByTodoText(order TaskSortByType) *TaskList {
 	tasklist.sortBy(func(task1, task2 *Task) bool {
 		if task1.Todo < task2.Todo {
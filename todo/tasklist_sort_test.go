@@ -26,6 +26,8 @@ func TestTaskSortByType(t *testing.T) {
 		SortContextDesc:       "ContextDesc",
 		SortProjectAsc:        "ProjectAsc",
 		SortProjectDesc:       "ProjectDesc",
+		SortThresholdDateAsc:  "ThresholdDateAsc",
+		SortThresholdDateDesc: "ThresholdDateDesc",
 		0:                     "TaskSortByType(0)",
 	}
 
@@ -197,6 +199,39 @@ func TestTaskList_Sort_sort_by_due_date(t *testing.T) {
 	}
 }
 
+func TestTaskList_Sort_sort_by_threshold_date(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString("Task A t:2014-01-12\nTask B\nTask C t:2014-01-05\nTask D t:2014-02-17")
+	require.NoError(t, err)
+
+	// SortThresholdDateAsc
+	{
+		require.NoError(t, tasklist.Sort(SortThresholdDateAsc), "sorting by SortThresholdDateAsc failed")
+
+		expectTasklist := []string{
+			"Task B",
+			"Task C t:2014-01-05",
+			"Task A t:2014-01-12",
+			"Task D t:2014-02-17",
+		}
+		checkTaskListOrder(t, tasklist, expectTasklist)
+	}
+
+	// SortThresholdDateDesc
+	{
+		require.NoError(t, tasklist.Sort(SortThresholdDateDesc), "sorting by SortThresholdDateDesc failed")
+
+		expectTasklist := []string{
+			"Task D t:2014-02-17",
+			"Task A t:2014-01-12",
+			"Task C t:2014-01-05",
+			"Task B",
+		}
+		checkTaskListOrder(t, tasklist, expectTasklist)
+	}
+}
+
 func TestTaskList_Sort_sort_by_task_id(t *testing.T) {
 	t.Parallel()
 
@@ -517,3 +552,16 @@ func Test_lessStrings(t *testing.T) {
 		)
 	}
 }
+
+func TestTaskList_CustomSort_sort_by_tag(t *testing.T) {
+	t.Parallel()
+
+	tasklist, err := LoadFromString("Deploy dep:3 dep:7\nWrite docs\nTest dep:3\nReview dep:8")
+	require.NoError(t, err)
+
+	tasklist.CustomSort(LessByTagAsc("dep"))
+	require.Equal(t, "Test dep:3\nDeploy dep:3 dep:7\nReview dep:8\nWrite docs\n", tasklist.String())
+
+	tasklist.CustomSort(LessByTagDesc("dep"))
+	require.Equal(t, "Review dep:8\nDeploy dep:3 dep:7\nTest dep:3\nWrite docs\n", tasklist.String())
+}
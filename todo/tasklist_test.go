@@ -1,8 +1,10 @@
 package todo
 
 import (
+	"errors"
 	"os"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -190,7 +192,7 @@ func TestTaskList_AddTask(t *testing.T) {
 	taskID++
 
 	// add selfmade task
-	createdDate := time.Now()
+	createdDate := Today()
 
 	//nolint:exhaustruct // other fields are missing intentionally
 	testTasklist.AddTask(&Task{
@@ -277,7 +279,7 @@ func TestTaskList_update_task(t *testing.T) {
 	}
 	{
 		// Update/change task properties
-		date, err := parseTime("2011-11-11")
+		date, err := ParseDate("2011-11-11")
 		require.NoError(t, err, "failed to parse time during test")
 
 		task.DueDate = date    // 2014-02-17 -> 2011-11-11
@@ -422,9 +424,11 @@ func TestTaskList_read_errors(t *testing.T) {
 			"Expected LoadFromPath to fail because of invalid completed date, but got TaskList back: [%s]",
 		},
 		{
+			// Very long lines are read as a whole, so the invalid due date at the
+			// end of the 100 KiB line is found
 			testInputTasklistScannerError,
-			`bufio.Scanner: token too long`,
-			"Expected LoadFromPath to fail because of invalid file, but got TaskList back: [%s]",
+			`parsing time "2014-02-17x": extra text: "x"`,
+			"Expected LoadFromPath to fail because of invalid due date, but got TaskList back: [%s]",
 		},
 	} {
 		testTasklist, err := LoadFromPath(test.path)
@@ -433,4 +437,79 @@ func TestTaskList_read_errors(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), test.expectMsg)
 	}
+
+	// Read error
+	testTasklist, err := LoadFromFile(iotest.ErrReader(errors.New("forced error")))
+
+	require.Empty(t, testTasklist, "Expected LoadFromFile to fail because of read error")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to load from file")
+	require.Contains(t, err.Error(), "forced error")
+}
+
+//nolint:paralleltest,funlen // do not parallel to avoid race conditions
+func TestTaskList_keep_non_task_lines(t *testing.T) {
+	rawInput, err := os.ReadFile(testInputTask)
+	require.NoError(t, err, "failed to read test data")
+
+	testTasklist, err := LoadFromPath(testInputTask, WithKeepNonTaskLines(true))
+	require.NoError(t, err, "failed to load test data")
+
+	// Comments and blank lines are kept as non-task entries
+	{
+		require.True(t, testTasklist[0].IsNonTask(), "the first line is a comment")
+		require.Equal(t, "# String test cases", testTasklist[0].String())
+		require.Equal(t, 1, testTasklist[1].ID, "non-task entries should not be numbered")
+
+		task, err := testTasklist.GetTask(27)
+		require.NoError(t, err, "failed to get task")
+		require.Equal(t, 27, task.ID, "IDs should be the same as without non-task entries")
+
+		_, err = testTasklist.GetTask(0)
+		require.Error(t, err, "non-task entries should not be returned by GetTask")
+
+		expectLen := 78
+		actualLen := len(testTasklist)
+		require.Equal(t, expectLen, actualLen, "all the lines should be kept in the tasklist")
+
+		expectCount := 54
+		actualCount := testTasklist.Count()
+		require.Equal(t, expectCount, actualCount, "non-task entries should not be counted")
+	}
+
+	// Writing the tasklist back keeps the file as is
+	{
+		pathFileOutput := testGetPathFileTemp(t, testOutput)
+		require.NoError(t, testTasklist.WriteToPath(pathFileOutput, WithPreserveOriginal(true)),
+			"failed to write tasklist")
+
+		rawOutput, err := os.ReadFile(pathFileOutput)
+		require.NoError(t, err, "failed to read saved tasklist")
+
+		require.Equal(t, string(rawInput), string(rawOutput), "comments and blank lines should be kept")
+	}
+
+	// Filter skips non-task entries
+	{
+		filteredList := testTasklist.Filter(FilterNotCompleted)
+
+		for _, task := range filteredList {
+			require.False(t, task.IsNonTask(), "filtered list should not contain non-task entries")
+		}
+	}
+
+	// Sort keeps non-task entries at their positions
+	{
+		require.NoError(t, testTasklist.Sort(SortTaskIDDesc), "failed to sort tasklist")
+
+		require.True(t, testTasklist[0].IsNonTask(), "the first line should stay as a comment")
+		require.Equal(t, testTasklist.Count(), testTasklist[1].ID, "tasks should be sorted around non-task entries")
+
+		testTasklist.CustomSort(func(taskA, taskB Task) bool {
+			return taskA.ID < taskB.ID
+		})
+
+		require.True(t, testTasklist[0].IsNonTask(), "the first line should stay as a comment")
+		require.Equal(t, 1, testTasklist[1].ID, "tasks should be sorted around non-task entries")
+	}
 }
@@ -5,8 +5,14 @@ package todo
 // ----------------------------------------------------------------------------
 
 // TaskSegment represents a segment in task string.
+//
+// Start and End are only set by Task.InlineSegments. They are the byte offsets
+// of the segment in the task line, such that Display is line[Start:End]. Both
+// are 0 otherwise.
 type TaskSegment struct {
 	Display   string
 	Originals []string
+	Start     int
+	End       int
 	Type      TaskSegmentType
 }
@@ -26,4 +26,5 @@ const (
 	SegmentProject
 	SegmentTag
 	SegmentDueDate
+	SegmentThresholdDate
 )
@@ -17,11 +17,12 @@ func _() {
 	_ = x[SegmentProject-8]
 	_ = x[SegmentTag-9]
 	_ = x[SegmentDueDate-10]
+	_ = x[SegmentThresholdDate-11]
 }
 
-const _TaskSegmentType_name = "IsCompletedCompletedDatePriorityCreatedDateTodoTextContextProjectTagDueDate"
+const _TaskSegmentType_name = "IsCompletedCompletedDatePriorityCreatedDateTodoTextContextProjectTagDueDateThresholdDate"
 
-var _TaskSegmentType_index = [...]uint8{0, 11, 24, 32, 43, 51, 58, 65, 68, 75}
+var _TaskSegmentType_index = [...]uint8{0, 11, 24, 32, 43, 51, 58, 65, 68, 75, 88}
 
 func (i TaskSegmentType) String() string {
 	i -= 2
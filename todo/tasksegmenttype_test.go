@@ -18,6 +18,7 @@ func TestTaskSegmentType(t *testing.T) {
 		SegmentProject:       "Project",
 		SegmentTag:           "Tag",
 		SegmentDueDate:       "DueDate",
+		SegmentThresholdDate: "ThresholdDate",
 		0:                    "TaskSegmentType(0)",
 		100:                  "TaskSegmentType(100)",
 	}
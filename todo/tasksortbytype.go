@@ -28,4 +28,6 @@ const (
 	SortContextDesc
 	SortProjectAsc
 	SortProjectDesc
+	SortThresholdDateAsc
+	SortThresholdDateDesc
 )
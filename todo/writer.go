@@ -0,0 +1,63 @@
+package todo
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Writer
+// ----------------------------------------------------------------------------
+
+// Writer writes tasks one by one to an io.Writer in todo.txt format.
+//
+// It is the counterpart of Reader. The written data is buffered, so call Flush
+// once all the tasks are written.
+type Writer struct {
+	writer *bufio.Writer
+	cfg    *Config
+}
+
+// ----------------------------------------------------------------------------
+//  Constructor
+// ----------------------------------------------------------------------------
+
+// NewWriter returns a new Writer which writes tasks to the given io.Writer.
+// The options are used to change the default configuration. See Config.
+func NewWriter(writer io.Writer, opts ...Option) *Writer {
+	return &Writer{
+		writer: bufio.NewWriter(writer),
+		cfg:    NewConfig(opts...),
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *Writer) Flush() error {
+	return errors.Wrap(w.writer.Flush(), "failed write buffered data to the underlying io.Writer")
+}
+
+// Write writes a task as a line.
+func (w *Writer) Write(task *Task) error {
+	if _, err := w.writer.WriteString(task.stringWith(w.cfg) + w.cfg.NewLine); err != nil {
+		return errors.Wrap(err, "failed to write task")
+	}
+
+	return nil
+}
+
+// WriteAll writes all the tasks in the given TaskList and flushes the buffer.
+func (w *Writer) WriteAll(tasklist TaskList) error {
+	for i := range tasklist {
+		if err := w.Write(&tasklist[i]); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
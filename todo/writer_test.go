@@ -0,0 +1,84 @@
+package todo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_Write(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	writer := NewWriter(&buf, WithNewLine("\r\n"))
+
+	task, err := ParseTask("(A) Call Mom @Phone")
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Write(task))
+	require.Empty(t, buf.String(), "written data should be buffered")
+
+	require.NoError(t, writer.Flush())
+	require.Equal(t, "(A) Call Mom @Phone\r\n", buf.String())
+}
+
+func TestWriter_WriteAll(t *testing.T) {
+	t.Parallel()
+
+	tasklist := testLoadFromPath(t, testInputTasklist)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, NewWriter(&buf).WriteAll(tasklist))
+	require.Equal(t, tasklist.String(), buf.String(), "it should write the same as TaskList.String")
+}
+
+func TestWriter_stream(t *testing.T) {
+	t.Parallel()
+
+	rawInput, err := os.ReadFile(testInputTasklist)
+	require.NoError(t, err)
+
+	// Copy the completed tasks from the reader to the writer
+	var buf bytes.Buffer
+
+	reader := NewReader(bytes.NewReader(rawInput)).Filter(FilterCompleted)
+	writer := NewWriter(&buf)
+
+	for {
+		task, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+		require.NoError(t, writer.Write(task))
+	}
+
+	require.NoError(t, writer.Flush())
+
+	tasklist := testLoadFromPath(t, testInputTasklist)
+	expect := tasklist.Filter(FilterCompleted)
+
+	require.Equal(t, expect.String(), buf.String())
+	require.Equal(t, 33, strings.Count(buf.String(), NewLine))
+}
+
+func TestWriter_fail_to_write(t *testing.T) {
+	t.Parallel()
+
+	task, err := ParseTask("(A) Call Mom @Phone")
+	require.NoError(t, err)
+
+	writer := NewWriter(os.Stdin)
+
+	require.NoError(t, writer.Write(task), "written data should be buffered")
+	require.Error(t, writer.Flush(), "it should fail to write to stdin")
+	require.Error(t, writer.WriteAll(TaskList{*task}), "it should fail to write to stdin")
+}